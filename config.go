@@ -0,0 +1,37 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ngray1747/dvd-rental/internal/config"
+)
+
+const configUsage = `usage: dvd_rental config [flags]
+
+Prints the configuration the services would start with, secrets redacted, and the problems found in it.
+`
+
+// runConfig runs the config subcommand and returns its exit code
+func runConfig(args []string) int {
+	fs := flag.NewFlagSet("config", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, configUsage)
+		fs.PrintDefaults()
+	}
+	config.RegisterFlags(fs)
+	cfg, err := config.Parse(fs, args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("# env: %s\n%s", cfg.Env, cfg)
+	if err := cfg.Validate(); err != nil {
+		for _, e := range err.(config.Errors) {
+			fmt.Fprintln(os.Stderr, e)
+		}
+		return 1
+	}
+	return 0
+}
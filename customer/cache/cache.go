@@ -1,13 +1,12 @@
 package cache
 
 import (
-	"encoding/json"
 	"errors"
 
 	"github.com/go-redis/redis/v7"
-	"github.com/vmihailenco/msgpack"
 	"github.com/ngray1747/dvd-rental/customer"
 	"github.com/ngray1747/dvd-rental/customer/repository"
+	"github.com/vmihailenco/msgpack"
 )
 
 var errNilResult = errors.New("nil value")
@@ -16,7 +15,7 @@ type cacheClient struct {
 	client *redis.Client
 }
 
-//NewCacheClient init a new cache client
+// NewCacheClient init a new cache client
 func NewCacheClient(cli *redis.Client) repository.Cache {
 	return &cacheClient{client: cli}
 }
@@ -26,8 +25,8 @@ func (c *cacheClient) StoreToCache(key string, customer customer.Customer) error
 	if err != nil {
 		return err
 	}
-	
-	return  c.client.HSet(key, customer.ID, bytes).Err()
+
+	return c.client.HSet(key, customer.ID, bytes).Err()
 }
 
 func (c *cacheClient) GetFromCache(key, field string) (*customer.Customer, error) {
@@ -37,7 +36,7 @@ func (c *cacheClient) GetFromCache(key, field string) (*customer.Customer, error
 	}
 
 	var result = new(customer.Customer)
-	err = json.Unmarshal(val, result)
+	err = msgpack.Unmarshal(val, result)
 	if err != nil {
 		return nil, err
 	}
@@ -49,4 +48,4 @@ func (c *cacheClient) RemoveFromCache(key, field string) error {
 		return err
 	}
 	return nil
-}
\ No newline at end of file
+}
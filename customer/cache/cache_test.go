@@ -16,6 +16,7 @@ import (
 )
 
 var db *redis.Client
+
 func TestMain(m *testing.M) {
 
 	var err error
@@ -66,8 +67,8 @@ func TestStoreToCache(t *testing.T) {
 						ID:        "66d112da-07e3-41de-bce3-86fe2bd52b24",
 						CreatedAt: time.Now(),
 					},
-					Name:      "Duy Nguyen",
-					Address:   "1102 Truong Sa Street",
+					Name:    "Duy Nguyen",
+					Address: "1102 Truong Sa Street",
 				},
 			},
 			wantErr: false,
@@ -80,3 +81,24 @@ func TestStoreToCache(t *testing.T) {
 		})
 	}
 }
+
+func TestStoreRentalToCache(t *testing.T) {
+	client := cache.NewRentalCacheClient(db)
+	rental := customer.Rental{
+		Base: model.Base{
+			ID: "7b0c6d1e-5f0a-4a53-9d1f-0e7a2b9a6a11",
+		},
+		CustomerID: "66d112da-07e3-41de-bce3-86fe2bd52b24",
+		DVDID:      "5e8b83c9-36f3-4084-94b5-33153246d534",
+		RentedAt:   time.Now(),
+	}
+	assert.NoError(t, client.StoreToCache("customers:rentals", rental))
+
+	got, err := client.GetFromCache("customers:rentals", rental.DVDID)
+	assert.NoError(t, err)
+	assert.Equal(t, rental.CustomerID, got.CustomerID)
+
+	assert.NoError(t, client.RemoveFromCache("customers:rentals", rental.DVDID))
+	_, err = client.GetFromCache("customers:rentals", rental.DVDID)
+	assert.Equal(t, redis.Nil, err)
+}
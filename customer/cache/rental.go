@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"github.com/go-redis/redis/v7"
+	"github.com/ngray1747/dvd-rental/customer"
+	"github.com/ngray1747/dvd-rental/customer/repository"
+	"github.com/vmihailenco/msgpack"
+)
+
+type rentalCacheClient struct {
+	client *redis.Client
+}
+
+// NewRentalCacheClient init a new rental cache client
+func NewRentalCacheClient(cli *redis.Client) repository.RentalCache {
+	return &rentalCacheClient{client: cli}
+}
+
+func (c *rentalCacheClient) StoreToCache(key string, rental customer.Rental) error {
+	bytes, err := msgpack.Marshal(rental)
+	if err != nil {
+		return err
+	}
+
+	return c.client.HSet(key, rental.DVDID, bytes).Err()
+}
+
+func (c *rentalCacheClient) GetFromCache(key, field string) (*customer.Rental, error) {
+	val, err := c.client.HGet(key, field).Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	var result = new(customer.Rental)
+	if err := msgpack.Unmarshal(val, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *rentalCacheClient) RemoveFromCache(key, field string) error {
+	return c.client.HDel(key, field).Err()
+}
@@ -5,23 +5,27 @@ import (
 	"github.com/ngray1747/dvd-rental/internal/model"
 )
 
-
-//Customer represents the customer model
+// Customer represents the customer model
 type Customer struct {
 	model.Base
-	Name      string `pg:",notnull"`
-	Address   string `pg:",notnull"`
+	Name    string `pg:",notnull" json:"name"`
+	Address string `pg:",notnull" json:"address"`
+	// Balance is what the customer owes in late fees, in cents
+	Balance int64 `pg:",use_zero" json:"balance"`
 }
 
-//Repository represent database/cache business
+// Repository represent database/cache business
 type Repository interface {
 	Store(c *Customer) error
 	GetByID(q string) (*Customer, error)
 	Update(c *Customer) error
 	Delete(c *Customer) error
+	//Restore undeletes the deleted customer with the id of c, giving it the name and address of c.
+	//It returns a not found error when no deleted customer has that id.
+	Restore(c *Customer) error
 }
 
-//NewCustomer init a new customer with name and address.
+// NewCustomer init a new customer with name and address.
 func NewCustomer(name, address string) (*Customer, error) {
 	id, err := uuid.NewRandom()
 	if err != nil {
@@ -30,7 +34,7 @@ func NewCustomer(name, address string) (*Customer, error) {
 
 	return &Customer{
 		Base: model.Base{
-			ID:      id.String(),
+			ID: id.String(),
 		},
 		Name:    name,
 		Address: address,
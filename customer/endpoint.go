@@ -2,15 +2,13 @@ package customer
 
 import (
 	"context"
-	"time"
+	"net/http"
 
-	"github.com/go-kit/kit/circuitbreaker"
 	"github.com/go-kit/kit/endpoint"
-	"github.com/go-kit/kit/ratelimit"
 	"github.com/go-kit/kit/tracing/opentracing"
+	"github.com/ngray1747/dvd-rental/internal/idempotency"
+	"github.com/ngray1747/dvd-rental/internal/limits"
 	stdopentracing "github.com/opentracing/opentracing-go"
-	"github.com/sony/gobreaker"
-	"golang.org/x/time/rate"
 )
 
 type registerRequest struct {
@@ -19,16 +17,87 @@ type registerRequest struct {
 }
 
 type registerResponse struct {
-	Err error `json:"error,omitempty"`
+	Customer *Customer `json:"customer,omitempty"`
+	Err      error     `json:"error,omitempty"`
 }
 
-func (r registerResponse) error() error { return r.Err }
+func (r registerResponse) Failed() error { return r.Err }
+
+// StatusCode implements kithttp.StatusCoder, a registered customer is a created resource
+func (r registerResponse) StatusCode() int { return http.StatusCreated }
+
+// Headers implements kithttp.Headerer, pointing to the registered customer
+func (r registerResponse) Headers() http.Header {
+	h := http.Header{}
+	if r.Customer != nil {
+		h.Set("Location", "/customer/v1/"+r.Customer.ID)
+	}
+	return h
+}
 
 func makeRegisterEndpoint(s Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(registerRequest)
-		err := s.Register(ctx, req.Name, req.Address)
-		return registerResponse{Err: err}, nil
+		c, err := s.Register(ctx, req.Name, req.Address)
+		return registerResponse{Customer: c, Err: err}, nil
+	}
+}
+
+type getRequest struct {
+	ID string
+}
+
+type getResponse struct {
+	Customer *Customer `json:"customer,omitempty"`
+	Err      error     `json:"error,omitempty"`
+}
+
+func (r getResponse) Failed() error { return r.Err }
+
+func makeGetEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(getRequest)
+		c, err := s.Get(ctx, req.ID)
+		return getResponse{Customer: c, Err: err}, nil
+	}
+}
+
+type updateRequest struct {
+	ID      string
+	Name    string `json:"name"`
+	Address string `json:"address"`
+}
+
+type updateResponse struct {
+	Customer *Customer `json:"customer,omitempty"`
+	Err      error     `json:"error,omitempty"`
+}
+
+func (r updateResponse) Failed() error { return r.Err }
+
+func makeUpdateEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(updateRequest)
+		c, err := s.Update(ctx, req.ID, req.Name, req.Address)
+		return updateResponse{Customer: c, Err: err}, nil
+	}
+}
+
+type deleteRequest struct {
+	ID string
+}
+
+type deleteResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r deleteResponse) Failed() error { return r.Err }
+
+func makeDeleteEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(deleteRequest)
+		err := s.Delete(ctx, req.ID)
+		return deleteResponse{Err: err}, nil
 	}
 }
 
@@ -38,44 +107,299 @@ type rentRequest struct {
 }
 
 type rentResponse struct {
-	Err error `json:"error,omitempty"`
+	Rental *Rental `json:"rental,omitempty"`
+	Err    error   `json:"error,omitempty"`
 }
 
-func (r rentResponse) error() error { return r.Err }
+func (r rentResponse) Failed() error { return r.Err }
 
 func makeRentEndpoint(s Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(rentRequest)
-		err := s.Rent(ctx, req.CustomerID, req.DVDID)
-		return rentResponse{Err: err}, nil
+		r, err := s.Rent(ctx, req.CustomerID, req.DVDID)
+		return rentResponse{Rental: r, Err: err}, nil
+	}
+}
+
+type buyRequest struct {
+	CustomerID string `json:"customer_id"`
+	DVDID      string `json:"dvd_id"`
+}
+
+type buyResponse struct {
+	Purchase *Purchase `json:"purchase,omitempty"`
+	Err      error     `json:"error,omitempty"`
+}
+
+func (r buyResponse) Failed() error { return r.Err }
+
+func makeBuyEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(buyRequest)
+		p, err := s.Buy(ctx, req.CustomerID, req.DVDID)
+		return buyResponse{Purchase: p, Err: err}, nil
+	}
+}
+
+type returnRequest struct {
+	CustomerID string `json:"customer_id"`
+	DVDID      string `json:"dvd_id"`
+}
+
+type returnResponse struct {
+	Rental *Rental `json:"rental,omitempty"`
+	Err    error   `json:"error,omitempty"`
+}
+
+func (r returnResponse) Failed() error { return r.Err }
+
+func makeReturnEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(returnRequest)
+		r, err := s.Return(ctx, req.CustomerID, req.DVDID)
+		return returnResponse{Rental: r, Err: err}, nil
+	}
+}
+
+type balanceRequest struct {
+	ID string
+}
+
+type balanceResponse struct {
+	CustomerID string `json:"customer_id,omitempty"`
+	Balance    int64  `json:"balance"`
+	Err        error  `json:"error,omitempty"`
+}
+
+func (r balanceResponse) Failed() error { return r.Err }
+
+func makeBalanceEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(balanceRequest)
+		b, err := s.Balance(ctx, req.ID)
+		if err != nil {
+			return balanceResponse{Err: err}, nil
+		}
+		return balanceResponse{CustomerID: req.ID, Balance: b}, nil
+	}
+}
+
+type joinWaitlistRequest struct {
+	CustomerID string
+	TitleID    string `json:"title_id"`
+}
+
+type joinWaitlistResponse struct {
+	Reservation *Reservation `json:"reservation,omitempty"`
+	Err         error        `json:"error,omitempty"`
+}
+
+func (r joinWaitlistResponse) Failed() error { return r.Err }
+
+func makeJoinWaitlistEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(joinWaitlistRequest)
+		r, err := s.JoinWaitlist(ctx, req.CustomerID, req.TitleID)
+		return joinWaitlistResponse{Reservation: r, Err: err}, nil
+	}
+}
+
+type leaveWaitlistRequest struct {
+	CustomerID string
+	TitleID    string
+}
+
+type leaveWaitlistResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r leaveWaitlistResponse) Failed() error { return r.Err }
+
+func makeLeaveWaitlistEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(leaveWaitlistRequest)
+		err := s.LeaveWaitlist(ctx, req.CustomerID, req.TitleID)
+		return leaveWaitlistResponse{Err: err}, nil
+	}
+}
+
+type listWaitlistRequest struct {
+	TitleID string
+}
+
+type listWaitlistResponse struct {
+	Reservations []Reservation `json:"reservations"`
+	Err          error         `json:"error,omitempty"`
+}
+
+func (r listWaitlistResponse) Failed() error { return r.Err }
+
+func makeListWaitlistEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listWaitlistRequest)
+		r, err := s.ListWaitlist(ctx, req.TitleID)
+		return listWaitlistResponse{Reservations: r, Err: err}, nil
+	}
+}
+
+type listDVDsRequest struct {
+	Name   string
+	Status string
+	Cursor string
+	Limit  int
+}
+
+type listDVDsResponse struct {
+	*DVDPage
+	Err error `json:"error,omitempty"`
+}
+
+func (r listDVDsResponse) Failed() error { return r.Err }
+
+func makeListDVDsEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listDVDsRequest)
+		page, err := s.ListDVDs(ctx, req.Name, req.Status, req.Cursor, req.Limit)
+		return listDVDsResponse{DVDPage: page, Err: err}, nil
 	}
 }
 
 type CustomerEndpoints struct {
 	RegisterEndpoint endpoint.Endpoint
-	RentEndpoint endpoint.Endpoint
+	GetEndpoint      endpoint.Endpoint
+	UpdateEndpoint   endpoint.Endpoint
+	DeleteEndpoint   endpoint.Endpoint
+	RentEndpoint     endpoint.Endpoint
+	BuyEndpoint      endpoint.Endpoint
+	ReturnEndpoint   endpoint.Endpoint
+	BalanceEndpoint  endpoint.Endpoint
+	ListDVDsEndpoint endpoint.Endpoint
+
+	JoinWaitlistEndpoint  endpoint.Endpoint
+	LeaveWaitlistEndpoint endpoint.Endpoint
+	ListWaitlistEndpoint  endpoint.Endpoint
 }
 
-//NewCustomerEndpoint wraps all customer service with all middlewares
-func NewCustomerEndpoint(cs Service, ot stdopentracing.Tracer) CustomerEndpoints {
+// NewCustomerEndpoint wraps all customer service with all middlewares, calls changing state are made idempotent by keeper
+// and every call is rate limited and circuit broken by guard
+func NewCustomerEndpoint(cs Service, ot stdopentracing.Tracer, keeper *idempotency.Keeper, guard *limits.Guard) CustomerEndpoints {
 	var registerEndpoint endpoint.Endpoint
 	{
 		registerEndpoint = makeRegisterEndpoint(cs)
-		registerEndpoint = ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Every(time.Second), 1))(registerEndpoint)
-		registerEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{}))(registerEndpoint)
+		registerEndpoint = guard.Limit()(registerEndpoint)
+		registerEndpoint = guard.Break("Register")(registerEndpoint)
 		registerEndpoint = opentracing.TraceServer(ot, "Register")(registerEndpoint)
+		registerEndpoint = keeper.Middleware("register", registerResponse{})(registerEndpoint)
+	}
+
+	var getEndpoint endpoint.Endpoint
+	{
+		getEndpoint = makeGetEndpoint(cs)
+		getEndpoint = guard.Limit()(getEndpoint)
+		getEndpoint = guard.Break("Get")(getEndpoint)
+		getEndpoint = opentracing.TraceServer(ot, "Get")(getEndpoint)
+	}
+
+	var updateEndpoint endpoint.Endpoint
+	{
+		updateEndpoint = makeUpdateEndpoint(cs)
+		updateEndpoint = guard.Limit()(updateEndpoint)
+		updateEndpoint = guard.Break("Update")(updateEndpoint)
+		updateEndpoint = opentracing.TraceServer(ot, "Update")(updateEndpoint)
+		updateEndpoint = keeper.Middleware("update", updateResponse{})(updateEndpoint)
+	}
+
+	var deleteEndpoint endpoint.Endpoint
+	{
+		deleteEndpoint = makeDeleteEndpoint(cs)
+		deleteEndpoint = guard.Limit()(deleteEndpoint)
+		deleteEndpoint = guard.Break("Delete")(deleteEndpoint)
+		deleteEndpoint = opentracing.TraceServer(ot, "Delete")(deleteEndpoint)
+		deleteEndpoint = keeper.Middleware("delete", deleteResponse{})(deleteEndpoint)
 	}
 
 	var rentEndpoint endpoint.Endpoint
 	{
 		rentEndpoint = makeRentEndpoint(cs)
-		rentEndpoint = ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Every(time.Second), 1))(rentEndpoint)
-		rentEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{}))(rentEndpoint)
+		rentEndpoint = guard.Limit()(rentEndpoint)
+		rentEndpoint = guard.Break("Rent")(rentEndpoint)
 		rentEndpoint = opentracing.TraceServer(ot, "Rent")(rentEndpoint)
+		rentEndpoint = keeper.Middleware("rent", rentResponse{})(rentEndpoint)
+	}
+
+	var buyEndpoint endpoint.Endpoint
+	{
+		buyEndpoint = makeBuyEndpoint(cs)
+		buyEndpoint = guard.Limit()(buyEndpoint)
+		buyEndpoint = guard.Break("Buy")(buyEndpoint)
+		buyEndpoint = opentracing.TraceServer(ot, "Buy")(buyEndpoint)
+		buyEndpoint = keeper.Middleware("buy", buyResponse{})(buyEndpoint)
+	}
+
+	var returnEndpoint endpoint.Endpoint
+	{
+		returnEndpoint = makeReturnEndpoint(cs)
+		returnEndpoint = guard.Limit()(returnEndpoint)
+		returnEndpoint = guard.Break("Return")(returnEndpoint)
+		returnEndpoint = opentracing.TraceServer(ot, "Return")(returnEndpoint)
+		returnEndpoint = keeper.Middleware("return", returnResponse{})(returnEndpoint)
 	}
-	
+
+	var balanceEndpoint endpoint.Endpoint
+	{
+		balanceEndpoint = makeBalanceEndpoint(cs)
+		balanceEndpoint = guard.Limit()(balanceEndpoint)
+		balanceEndpoint = guard.Break("Balance")(balanceEndpoint)
+		balanceEndpoint = opentracing.TraceServer(ot, "Balance")(balanceEndpoint)
+	}
+
+	var listDVDsEndpoint endpoint.Endpoint
+	{
+		listDVDsEndpoint = makeListDVDsEndpoint(cs)
+		listDVDsEndpoint = guard.Limit()(listDVDsEndpoint)
+		listDVDsEndpoint = guard.Break("ListDVDs")(listDVDsEndpoint)
+		listDVDsEndpoint = opentracing.TraceServer(ot, "ListDVDs")(listDVDsEndpoint)
+	}
+
+	var joinWaitlistEndpoint endpoint.Endpoint
+	{
+		joinWaitlistEndpoint = makeJoinWaitlistEndpoint(cs)
+		joinWaitlistEndpoint = guard.Limit()(joinWaitlistEndpoint)
+		joinWaitlistEndpoint = guard.Break("JoinWaitlist")(joinWaitlistEndpoint)
+		joinWaitlistEndpoint = opentracing.TraceServer(ot, "JoinWaitlist")(joinWaitlistEndpoint)
+		joinWaitlistEndpoint = keeper.Middleware("joinWaitlist", joinWaitlistResponse{})(joinWaitlistEndpoint)
+	}
+
+	var leaveWaitlistEndpoint endpoint.Endpoint
+	{
+		leaveWaitlistEndpoint = makeLeaveWaitlistEndpoint(cs)
+		leaveWaitlistEndpoint = guard.Limit()(leaveWaitlistEndpoint)
+		leaveWaitlistEndpoint = guard.Break("LeaveWaitlist")(leaveWaitlistEndpoint)
+		leaveWaitlistEndpoint = opentracing.TraceServer(ot, "LeaveWaitlist")(leaveWaitlistEndpoint)
+		leaveWaitlistEndpoint = keeper.Middleware("leaveWaitlist", leaveWaitlistResponse{})(leaveWaitlistEndpoint)
+	}
+
+	var listWaitlistEndpoint endpoint.Endpoint
+	{
+		listWaitlistEndpoint = makeListWaitlistEndpoint(cs)
+		listWaitlistEndpoint = guard.Limit()(listWaitlistEndpoint)
+		listWaitlistEndpoint = guard.Break("ListWaitlist")(listWaitlistEndpoint)
+		listWaitlistEndpoint = opentracing.TraceServer(ot, "ListWaitlist")(listWaitlistEndpoint)
+	}
+
 	return CustomerEndpoints{
 		RegisterEndpoint: registerEndpoint,
-		RentEndpoint: rentEndpoint,
+		GetEndpoint:      getEndpoint,
+		UpdateEndpoint:   updateEndpoint,
+		DeleteEndpoint:   deleteEndpoint,
+		RentEndpoint:     rentEndpoint,
+		BuyEndpoint:      buyEndpoint,
+		ReturnEndpoint:   returnEndpoint,
+		BalanceEndpoint:  balanceEndpoint,
+		ListDVDsEndpoint: listDVDsEndpoint,
+
+		JoinWaitlistEndpoint:  joinWaitlistEndpoint,
+		LeaveWaitlistEndpoint: leaveWaitlistEndpoint,
+		ListWaitlistEndpoint:  listWaitlistEndpoint,
 	}
 }
@@ -0,0 +1,60 @@
+package customer
+
+import (
+	"time"
+
+	"github.com/ngray1747/dvd-rental/internal/config"
+)
+
+const (
+	day = 24 * time.Hour
+
+	defaultRentalPeriod  = 7 * day
+	defaultLateFeePerDay = 100
+)
+
+// FeePolicy decides how long a dvd may be rented and what returning it late costs
+type FeePolicy struct {
+	Period      time.Duration
+	GracePeriod time.Duration
+	// DailyRate is charged for every started day late, in cents
+	DailyRate int64
+}
+
+// NewFeePolicy builds a policy from the service's rental config, falling back to defaults for unset values
+func NewFeePolicy(cfg *config.Rental) FeePolicy {
+	p := FeePolicy{
+		Period:    defaultRentalPeriod,
+		DailyRate: defaultLateFeePerDay,
+	}
+	if cfg == nil {
+		return p
+	}
+	if cfg.Period > 0 {
+		p.Period = time.Duration(cfg.Period) * day
+	}
+	if cfg.GracePeriod > 0 {
+		p.GracePeriod = time.Duration(cfg.GracePeriod) * time.Hour
+	}
+	if cfg.LateFeePerDay > 0 {
+		p.DailyRate = cfg.LateFeePerDay
+	}
+	return p
+}
+
+// LateFee returns what is owed for a rental returned at returnedAt.
+// Nothing is charged within the grace period, past it every started day since
+// the due date is charged, never more than the replacement cost of the dvd when known.
+func (p FeePolicy) LateFee(r *Rental, returnedAt time.Time, replacementCost int64) int64 {
+	late := returnedAt.Sub(r.DueAt)
+	if late <= p.GracePeriod {
+		return 0
+	}
+
+	days := int64((late + day - 1) / day)
+	fee := days * p.DailyRate
+	if replacementCost > 0 && fee > replacementCost {
+		fee = replacementCost
+	}
+	return fee
+}
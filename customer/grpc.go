@@ -0,0 +1,173 @@
+package customer
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/tracing/opentracing"
+	"github.com/go-kit/kit/transport"
+	grpctransport "github.com/go-kit/kit/transport/grpc"
+	"github.com/ngray1747/dvd-rental/customer/pb"
+	"github.com/ngray1747/dvd-rental/internal/apperr"
+	"github.com/ngray1747/dvd-rental/internal/idempotency"
+	stdopentracing "github.com/opentracing/opentracing-go"
+)
+
+type grpcServer struct {
+	register grpctransport.Handler
+	rent     grpctransport.Handler
+	ret      grpctransport.Handler
+	get      grpctransport.Handler
+}
+
+func decodeGRPCRegisterRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(*pb.RegisterRequest)
+	return registerRequest{Name: req.Name, Address: req.Address}, nil
+}
+
+func encodeGRPCRegisterResponse(_ context.Context, response interface{}) (interface{}, error) {
+	res := response.(registerResponse)
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	return &pb.RegisterResponse{Customer: toPBCustomer(res.Customer)}, nil
+}
+
+func (g *grpcServer) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.RegisterResponse, error) {
+	_, res, err := g.register.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, apperr.ToGRPC(err)
+	}
+	return res.(*pb.RegisterResponse), nil
+}
+
+func decodeGRPCRentRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(*pb.RentRequest)
+	return rentRequest{CustomerID: req.CustomerId, DVDID: req.DvdId}, nil
+}
+
+func encodeGRPCRentResponse(_ context.Context, response interface{}) (interface{}, error) {
+	res := response.(rentResponse)
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	return &pb.RentResponse{Rental: toPBRental(res.Rental)}, nil
+}
+
+func (g *grpcServer) Rent(ctx context.Context, req *pb.RentRequest) (*pb.RentResponse, error) {
+	_, res, err := g.rent.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, apperr.ToGRPC(err)
+	}
+	return res.(*pb.RentResponse), nil
+}
+
+func decodeGRPCReturnRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(*pb.ReturnRequest)
+	return returnRequest{CustomerID: req.CustomerId, DVDID: req.DvdId}, nil
+}
+
+func encodeGRPCReturnResponse(_ context.Context, response interface{}) (interface{}, error) {
+	res := response.(returnResponse)
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	return &pb.ReturnResponse{Rental: toPBRental(res.Rental)}, nil
+}
+
+func (g *grpcServer) Return(ctx context.Context, req *pb.ReturnRequest) (*pb.ReturnResponse, error) {
+	_, res, err := g.ret.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, apperr.ToGRPC(err)
+	}
+	return res.(*pb.ReturnResponse), nil
+}
+
+func decodeGRPCGetRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(*pb.GetRequest)
+	return getRequest{ID: req.Id}, nil
+}
+
+func encodeGRPCGetResponse(_ context.Context, response interface{}) (interface{}, error) {
+	res := response.(getResponse)
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	return &pb.GetResponse{Customer: toPBCustomer(res.Customer)}, nil
+}
+
+func (g *grpcServer) Get(ctx context.Context, req *pb.GetRequest) (*pb.GetResponse, error) {
+	_, res, err := g.get.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, apperr.ToGRPC(err)
+	}
+	return res.(*pb.GetResponse), nil
+}
+
+func toPBCustomer(c *Customer) *pb.Customer {
+	return &pb.Customer{
+		Id:        c.ID,
+		Name:      c.Name,
+		Address:   c.Address,
+		Balance:   c.Balance,
+		CreatedAt: c.CreatedAt.Unix(),
+	}
+}
+
+func toPBRental(r *Rental) *pb.Rental {
+	p := &pb.Rental{
+		Id:         r.ID,
+		CustomerId: r.CustomerID,
+		DvdId:      r.DVDID,
+		RentedAt:   r.RentedAt.Unix(),
+		DueAt:      r.DueAt.Unix(),
+		LateFee:    r.LateFee,
+	}
+	if !r.ReturnedAt.IsZero() {
+		p.ReturnedAt = r.ReturnedAt.Unix()
+	}
+	return p
+}
+
+// NewGRPCServer serves the customer endpoints over gRPC, for internal callers, next to the JSON API of MakeHandler
+func NewGRPCServer(endpoints CustomerEndpoints, ot stdopentracing.Tracer, logger log.Logger) pb.CustomerServiceServer {
+	opts := []grpctransport.ServerOption{
+		grpctransport.ServerErrorHandler(transport.NewLogErrorHandler(logger)),
+		grpctransport.ServerBefore(idempotency.GRPCToContext),
+	}
+
+	registerHandler := grpctransport.NewServer(
+		endpoints.RegisterEndpoint,
+		decodeGRPCRegisterRequest,
+		encodeGRPCRegisterResponse,
+		append(opts, grpctransport.ServerBefore(opentracing.GRPCToContext(ot, "register", logger)))...,
+	)
+
+	rentHandler := grpctransport.NewServer(
+		endpoints.RentEndpoint,
+		decodeGRPCRentRequest,
+		encodeGRPCRentResponse,
+		append(opts, grpctransport.ServerBefore(opentracing.GRPCToContext(ot, "rent", logger)))...,
+	)
+
+	returnHandler := grpctransport.NewServer(
+		endpoints.ReturnEndpoint,
+		decodeGRPCReturnRequest,
+		encodeGRPCReturnResponse,
+		append(opts, grpctransport.ServerBefore(opentracing.GRPCToContext(ot, "return", logger)))...,
+	)
+
+	getHandler := grpctransport.NewServer(
+		endpoints.GetEndpoint,
+		decodeGRPCGetRequest,
+		encodeGRPCGetResponse,
+		append(opts, grpctransport.ServerBefore(opentracing.GRPCToContext(ot, "get", logger)))...,
+	)
+
+	return &grpcServer{
+		registerHandler,
+		rentHandler,
+		returnHandler,
+		getHandler,
+	}
+}
@@ -4,22 +4,28 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"strconv"
 
+	"github.com/go-kit/kit/endpoint"
 	kitlog "github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/tracing/opentracing"
 	"github.com/go-kit/kit/transport"
 	kithttp "github.com/go-kit/kit/transport/http"
 	"github.com/gorilla/mux"
+	"github.com/ngray1747/dvd-rental/internal/apperr"
+	"github.com/ngray1747/dvd-rental/internal/idempotency"
 	stdopentracing "github.com/opentracing/opentracing-go"
 )
 
+var errBadRoute = apperr.New(apperr.InvalidArgument, "bad route")
+
 func decodeRegisterRequest(_ context.Context, r *http.Request) (interface{}, error) {
 	var body struct {
 		Name    string `json:"name"`
 		Address string `json:"address"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		return nil, err
+		return nil, apperr.Errorf(apperr.InvalidArgument, "invalid request body: %v", err)
 	}
 	return registerRequest{
 		Name:    body.Name,
@@ -27,41 +33,173 @@ func decodeRegisterRequest(_ context.Context, r *http.Request) (interface{}, err
 	}, nil
 }
 
+func decodeGetRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	id, ok := mux.Vars(r)["id"]
+	if !ok {
+		return nil, errBadRoute
+	}
+	return getRequest{ID: id}, nil
+}
+
+func decodeUpdateRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	id, ok := mux.Vars(r)["id"]
+	if !ok {
+		return nil, errBadRoute
+	}
+	var body struct {
+		Name    string `json:"name"`
+		Address string `json:"address"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return nil, apperr.Errorf(apperr.InvalidArgument, "invalid request body: %v", err)
+	}
+	return updateRequest{
+		ID:      id,
+		Name:    body.Name,
+		Address: body.Address,
+	}, nil
+}
+
+func decodeDeleteRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	id, ok := mux.Vars(r)["id"]
+	if !ok {
+		return nil, errBadRoute
+	}
+	return deleteRequest{ID: id}, nil
+}
+
+func decodeBalanceRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	id, ok := mux.Vars(r)["id"]
+	if !ok {
+		return nil, errBadRoute
+	}
+	return balanceRequest{ID: id}, nil
+}
+
 func decodeRentRequest(_ context.Context, r *http.Request) (interface{}, error) {
 	var body struct {
 		CustomerID string `json:"customer_id"`
 		DVDID      string `json:"dvd_id"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		return nil, err
+		return nil, apperr.Errorf(apperr.InvalidArgument, "invalid request body: %v", err)
 	}
 	return rentRequest{
-		CustomerID:    body.CustomerID,
-		DVDID: body.DVDID,
+		CustomerID: body.CustomerID,
+		DVDID:      body.DVDID,
+	}, nil
+}
+
+func decodeBuyRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var body struct {
+		CustomerID string `json:"customer_id"`
+		DVDID      string `json:"dvd_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return nil, apperr.Errorf(apperr.InvalidArgument, "invalid request body: %v", err)
+	}
+	return buyRequest{
+		CustomerID: body.CustomerID,
+		DVDID:      body.DVDID,
 	}, nil
 }
 
-type errorer interface {
-	error() error
+func decodeReturnRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var body struct {
+		CustomerID string `json:"customer_id"`
+		DVDID      string `json:"dvd_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return nil, apperr.Errorf(apperr.InvalidArgument, "invalid request body: %v", err)
+	}
+	return returnRequest{
+		CustomerID: body.CustomerID,
+		DVDID:      body.DVDID,
+	}, nil
+}
+
+func decodeJoinWaitlistRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	id, ok := mux.Vars(r)["id"]
+	if !ok {
+		return nil, errBadRoute
+	}
+	var body struct {
+		TitleID string `json:"title_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return nil, apperr.Errorf(apperr.InvalidArgument, "invalid request body: %v", err)
+	}
+	return joinWaitlistRequest{
+		CustomerID: id,
+		TitleID:    body.TitleID,
+	}, nil
+}
+
+func decodeLeaveWaitlistRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	vars := mux.Vars(r)
+	id, ok := vars["id"]
+	if !ok {
+		return nil, errBadRoute
+	}
+	titleID, ok := vars["title_id"]
+	if !ok {
+		return nil, errBadRoute
+	}
+	return leaveWaitlistRequest{
+		CustomerID: id,
+		TitleID:    titleID,
+	}, nil
+}
+
+func decodeListWaitlistRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	titleID, ok := mux.Vars(r)["title_id"]
+	if !ok {
+		return nil, errBadRoute
+	}
+	return listWaitlistRequest{TitleID: titleID}, nil
+}
+
+func decodeListDVDsRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	q := r.URL.Query()
+	var limit int
+	if l := q.Get("limit"); l != "" {
+		var err error
+		if limit, err = strconv.Atoi(l); err != nil {
+			return nil, errInvalidArgument
+		}
+	}
+	return listDVDsRequest{
+		Name:   q.Get("name"),
+		Status: q.Get("status"),
+		Cursor: q.Get("cursor"),
+		Limit:  limit,
+	}, nil
 }
 
 func encodeError(ctx context.Context, err error, w http.ResponseWriter) {
 	w.Header().Set("Content-type", "application/json; charset=utf-8")
-	switch err {
-	case errInvalidArgument:
-		w.WriteHeader(http.StatusBadRequest)
-	}
+	w.WriteHeader(apperr.HTTPStatus(err))
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"error": err.Error(),
 	})
 }
 
 func encodeResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
-	if e, ok := response.(errorer); ok && e.error() != nil {
-		encodeError(ctx, e.error(), w)
+	if f, ok := response.(endpoint.Failer); ok && f.Failed() != nil {
+		encodeError(ctx, f.Failed(), w)
 		return nil
 	}
 	w.Header().Set("Content-type", "application/json; charset=utf-8")
+	if h, ok := response.(kithttp.Headerer); ok {
+		for k, values := range h.Headers() {
+			for _, v := range values {
+				w.Header().Add(k, v)
+			}
+		}
+	}
+	if sc, ok := response.(kithttp.StatusCoder); ok {
+		w.WriteHeader(sc.StatusCode())
+	}
 	return json.NewEncoder(w).Encode(response)
 }
 
@@ -69,6 +207,7 @@ func MakeHandler(endpoints CustomerEndpoints, logger kitlog.Logger, ot stdopentr
 	opts := []kithttp.ServerOption{
 		kithttp.ServerErrorHandler(transport.NewLogErrorHandler(logger)),
 		kithttp.ServerErrorEncoder(encodeError),
+		kithttp.ServerBefore(idempotency.HTTPToContext),
 	}
 
 	registerHandler := kithttp.NewServer(
@@ -78,6 +217,27 @@ func MakeHandler(endpoints CustomerEndpoints, logger kitlog.Logger, ot stdopentr
 		append(opts, kithttp.ServerBefore(opentracing.HTTPToContext(ot, "register", logger)))...,
 	)
 
+	getHandler := kithttp.NewServer(
+		endpoints.GetEndpoint,
+		decodeGetRequest,
+		encodeResponse,
+		append(opts, kithttp.ServerBefore(opentracing.HTTPToContext(ot, "get", logger)))...,
+	)
+
+	updateHandler := kithttp.NewServer(
+		endpoints.UpdateEndpoint,
+		decodeUpdateRequest,
+		encodeResponse,
+		append(opts, kithttp.ServerBefore(opentracing.HTTPToContext(ot, "update", logger)))...,
+	)
+
+	deleteHandler := kithttp.NewServer(
+		endpoints.DeleteEndpoint,
+		decodeDeleteRequest,
+		encodeResponse,
+		append(opts, kithttp.ServerBefore(opentracing.HTTPToContext(ot, "delete", logger)))...,
+	)
+
 	rentHandler := kithttp.NewServer(
 		endpoints.RentEndpoint,
 		decodeRentRequest,
@@ -85,9 +245,68 @@ func MakeHandler(endpoints CustomerEndpoints, logger kitlog.Logger, ot stdopentr
 		append(opts, kithttp.ServerBefore(opentracing.HTTPToContext(ot, "rent", logger)))...,
 	)
 
+	buyHandler := kithttp.NewServer(
+		endpoints.BuyEndpoint,
+		decodeBuyRequest,
+		encodeResponse,
+		append(opts, kithttp.ServerBefore(opentracing.HTTPToContext(ot, "buy", logger)))...,
+	)
+
+	returnHandler := kithttp.NewServer(
+		endpoints.ReturnEndpoint,
+		decodeReturnRequest,
+		encodeResponse,
+		append(opts, kithttp.ServerBefore(opentracing.HTTPToContext(ot, "return", logger)))...,
+	)
+
+	balanceHandler := kithttp.NewServer(
+		endpoints.BalanceEndpoint,
+		decodeBalanceRequest,
+		encodeResponse,
+		append(opts, kithttp.ServerBefore(opentracing.HTTPToContext(ot, "balance", logger)))...,
+	)
+
+	listDVDsHandler := kithttp.NewServer(
+		endpoints.ListDVDsEndpoint,
+		decodeListDVDsRequest,
+		encodeResponse,
+		append(opts, kithttp.ServerBefore(opentracing.HTTPToContext(ot, "list_dvds", logger)))...,
+	)
+
+	joinWaitlistHandler := kithttp.NewServer(
+		endpoints.JoinWaitlistEndpoint,
+		decodeJoinWaitlistRequest,
+		encodeResponse,
+		append(opts, kithttp.ServerBefore(opentracing.HTTPToContext(ot, "join_waitlist", logger)))...,
+	)
+
+	leaveWaitlistHandler := kithttp.NewServer(
+		endpoints.LeaveWaitlistEndpoint,
+		decodeLeaveWaitlistRequest,
+		encodeResponse,
+		append(opts, kithttp.ServerBefore(opentracing.HTTPToContext(ot, "leave_waitlist", logger)))...,
+	)
+
+	listWaitlistHandler := kithttp.NewServer(
+		endpoints.ListWaitlistEndpoint,
+		decodeListWaitlistRequest,
+		encodeResponse,
+		append(opts, kithttp.ServerBefore(opentracing.HTTPToContext(ot, "list_waitlist", logger)))...,
+	)
+
 	r := mux.NewRouter()
 
 	r.Handle("/customer/v1/register", registerHandler)
 	r.Handle("/customer/v1/rent", rentHandler)
+	r.Handle("/customer/v1/buy", buyHandler).Methods("POST")
+	r.Handle("/customer/v1/return", returnHandler)
+	r.Handle("/customer/v1/dvds", listDVDsHandler).Methods("GET")
+	r.Handle("/customer/v1/waitlist/{title_id}", listWaitlistHandler).Methods("GET")
+	r.Handle("/customer/v1/{id}/balance", balanceHandler).Methods("GET")
+	r.Handle("/customer/v1/{id}/waitlist", joinWaitlistHandler).Methods("POST")
+	r.Handle("/customer/v1/{id}/waitlist/{title_id}", leaveWaitlistHandler).Methods("DELETE")
+	r.Handle("/customer/v1/{id}", getHandler).Methods("GET")
+	r.Handle("/customer/v1/{id}", updateHandler).Methods("PUT")
+	r.Handle("/customer/v1/{id}", deleteHandler).Methods("DELETE")
 	return r
 }
@@ -0,0 +1,127 @@
+package customer
+
+import (
+	"context"
+
+	"github.com/ngray1747/dvd-rental/dvd"
+)
+
+// localProxy calls a dvd service running in the same process instead of going through gRPC
+type localProxy struct {
+	svc dvd.Service
+}
+
+// NewLocalProxy returns a ProxyService calling svc directly, for when both services run in one process
+func NewLocalProxy(svc dvd.Service) ProxyService {
+	return &localProxy{svc: svc}
+}
+
+func (p *localProxy) UpdateDVDStatus(ctx context.Context, customerID, DVDID string) error {
+	_, err := p.svc.RentDVD(ctx, DVDID, "", customerID)
+	return err
+}
+
+func (p *localProxy) ReturnDVD(ctx context.Context, customerID, DVDID string) (*DVD, error) {
+	c, err := p.svc.ReturnDVD(ctx, DVDID, customerID)
+	if err != nil {
+		return nil, err
+	}
+	d := fromCopy(c)
+	return &d, nil
+}
+
+func (p *localProxy) ReleaseDVD(ctx context.Context, customerID, DVDID string) error {
+	_, err := p.svc.ReleaseDVD(ctx, DVDID, customerID)
+	return err
+}
+
+func (p *localProxy) SellDVD(ctx context.Context, DVDID string) (*DVD, error) {
+	c, err := p.svc.SellDVD(ctx, DVDID)
+	if err != nil {
+		return nil, err
+	}
+	d := fromCopy(c)
+	return &d, nil
+}
+
+func (p *localProxy) JoinWaitlist(ctx context.Context, customerID, titleID string) (*Reservation, error) {
+	r, err := p.svc.JoinWaitlist(ctx, titleID, customerID)
+	if err != nil {
+		return nil, err
+	}
+	reservation := fromReservation(r)
+	return &reservation, nil
+}
+
+func (p *localProxy) LeaveWaitlist(ctx context.Context, customerID, titleID string) error {
+	return p.svc.LeaveWaitlist(ctx, titleID, customerID)
+}
+
+func (p *localProxy) ListWaitlist(ctx context.Context, titleID string) ([]Reservation, error) {
+	rs, err := p.svc.ListWaitlist(ctx, titleID)
+	if err != nil {
+		return nil, err
+	}
+	reservations := make([]Reservation, 0, len(rs))
+	for _, r := range rs {
+		reservations = append(reservations, fromReservation(r))
+	}
+	return reservations, nil
+}
+
+func (p *localProxy) ListDVDs(ctx context.Context, name, status, cursor string, limit int) (*DVDPage, error) {
+	s, err := dvd.ParseStatus(status)
+	if err != nil {
+		return nil, err
+	}
+	f := dvd.Filter{Name: name, Status: s}
+	var page *dvd.Page
+	if f.IsZero() {
+		page, err = p.svc.ListDVDs(ctx, cursor, limit)
+	} else {
+		page, err = p.svc.SearchDVDs(ctx, f, cursor, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	dvds := &DVDPage{
+		DVDs:       make([]DVD, 0, len(page.Copies)),
+		NextCursor: page.NextCursor,
+	}
+	for _, c := range page.Copies {
+		dvds.DVDs = append(dvds.DVDs, fromCopy(c))
+	}
+	return dvds, nil
+}
+
+func fromCopy(c *dvd.Copy) DVD {
+	d := DVD{
+		ID:        c.ID,
+		TitleID:   c.TitleID,
+		Barcode:   c.Barcode,
+		Condition: string(c.Condition),
+		Status:    c.Status.ToString(),
+		CreatedAt: c.CreatedAt,
+
+		ReservedFor: c.ReservedFor,
+	}
+	if !c.HeldUntil.IsZero() {
+		heldUntil := c.HeldUntil
+		d.HeldUntil = &heldUntil
+	}
+	if c.Title != nil {
+		d.Name = c.Title.Name
+		d.ReplacementCost = c.Title.ReplacementCost
+		d.Price = c.Price()
+	}
+	return d
+}
+
+func fromReservation(r *dvd.Reservation) Reservation {
+	return Reservation{
+		ID:         r.ID,
+		TitleID:    r.TitleID,
+		CustomerID: r.CustomerID,
+		CreatedAt:  r.CreatedAt,
+	}
+}
@@ -16,27 +16,97 @@ type loggingService struct {
 	Service
 }
 
-//NewLoggingService init a logging service
+// NewLoggingService init a logging service
 func NewLoggingService(logger log.Logger) Middleware {
 	return func(svc Service) Service {
 		return &loggingService{logger, svc}
 	}
 }
 
-func (l *loggingService) Register(ctx context.Context, name, address string) (err error) {
+func (l *loggingService) Register(ctx context.Context, name, address string) (c *Customer, err error) {
 	defer func(begin time.Time) {
 		l.logger.Log("method", "register", "name", name, "address", address, "error", err, "time", time.Since(begin))
 	}(time.Now())
 	return l.Service.Register(ctx, name, address)
 }
 
-func (l *loggingService) Rent(ctx context.Context, customerID, dvdID string) (err error) {
+func (l *loggingService) Get(ctx context.Context, id string) (c *Customer, err error) {
+	defer func(begin time.Time) {
+		l.logger.Log("method", "get", "customerID", id, "error", err, "time", time.Since(begin))
+	}(time.Now())
+	return l.Service.Get(ctx, id)
+}
+
+func (l *loggingService) Update(ctx context.Context, id, name, address string) (c *Customer, err error) {
+	defer func(begin time.Time) {
+		l.logger.Log("method", "update", "customerID", id, "name", name, "address", address, "error", err, "time", time.Since(begin))
+	}(time.Now())
+	return l.Service.Update(ctx, id, name, address)
+}
+
+func (l *loggingService) Delete(ctx context.Context, id string) (err error) {
+	defer func(begin time.Time) {
+		l.logger.Log("method", "delete", "customerID", id, "error", err, "time", time.Since(begin))
+	}(time.Now())
+	return l.Service.Delete(ctx, id)
+}
+
+func (l *loggingService) Rent(ctx context.Context, customerID, dvdID string) (r *Rental, err error) {
 	defer func(begin time.Time) {
 		l.logger.Log("method", "rentDVD", "customerID", customerID, "dvdID", dvdID, "error", err, "time", time.Since(begin))
 	}(time.Now())
 	return l.Service.Rent(ctx, customerID, dvdID)
 }
 
+func (l *loggingService) Buy(ctx context.Context, customerID, dvdID string) (p *Purchase, err error) {
+	defer func(begin time.Time) {
+		l.logger.Log("method", "buyDVD", "customerID", customerID, "dvdID", dvdID, "error", err, "time", time.Since(begin))
+	}(time.Now())
+	return l.Service.Buy(ctx, customerID, dvdID)
+}
+
+func (l *loggingService) Return(ctx context.Context, customerID, dvdID string) (r *Rental, err error) {
+	defer func(begin time.Time) {
+		l.logger.Log("method", "returnDVD", "customerID", customerID, "dvdID", dvdID, "error", err, "time", time.Since(begin))
+	}(time.Now())
+	return l.Service.Return(ctx, customerID, dvdID)
+}
+
+func (l *loggingService) Balance(ctx context.Context, id string) (balance int64, err error) {
+	defer func(begin time.Time) {
+		l.logger.Log("method", "balance", "customerID", id, "error", err, "time", time.Since(begin))
+	}(time.Now())
+	return l.Service.Balance(ctx, id)
+}
+
+func (l *loggingService) JoinWaitlist(ctx context.Context, customerID, titleID string) (r *Reservation, err error) {
+	defer func(begin time.Time) {
+		l.logger.Log("method", "joinWaitlist", "customerID", customerID, "titleID", titleID, "error", err, "time", time.Since(begin))
+	}(time.Now())
+	return l.Service.JoinWaitlist(ctx, customerID, titleID)
+}
+
+func (l *loggingService) LeaveWaitlist(ctx context.Context, customerID, titleID string) (err error) {
+	defer func(begin time.Time) {
+		l.logger.Log("method", "leaveWaitlist", "customerID", customerID, "titleID", titleID, "error", err, "time", time.Since(begin))
+	}(time.Now())
+	return l.Service.LeaveWaitlist(ctx, customerID, titleID)
+}
+
+func (l *loggingService) ListWaitlist(ctx context.Context, titleID string) (r []Reservation, err error) {
+	defer func(begin time.Time) {
+		l.logger.Log("method", "listWaitlist", "titleID", titleID, "error", err, "time", time.Since(begin))
+	}(time.Now())
+	return l.Service.ListWaitlist(ctx, titleID)
+}
+
+func (l *loggingService) ListDVDs(ctx context.Context, name, status, cursor string, limit int) (p *DVDPage, err error) {
+	defer func(begin time.Time) {
+		l.logger.Log("method", "listDVDs", "name", name, "status", status, "cursor", cursor, "limit", limit, "error", err, "time", time.Since(begin))
+	}(time.Now())
+	return l.Service.ListDVDs(ctx, name, status, cursor, limit)
+}
+
 type instrumentService struct {
 	counter   metrics.Counter
 	histogram metrics.Histogram
@@ -49,20 +119,110 @@ func NewInstrumentService(counter metrics.Counter, histogram metrics.Histogram)
 	}
 }
 
-func (is *instrumentService) Register(ctx context.Context, name, address string) error {
-	err := is.Service.Register(ctx, name, address)
+func (is *instrumentService) Register(ctx context.Context, name, address string) (*Customer, error) {
+	c, err := is.Service.Register(ctx, name, address)
 	defer func(begin time.Time) {
 		is.counter.With("method", "register").Add(1)
 		is.histogram.With("method", "register", "success", fmt.Sprint(err == nil)).Observe(time.Since(begin).Seconds())
 	}(time.Now())
+	return c, err
+}
+
+func (is *instrumentService) Get(ctx context.Context, id string) (*Customer, error) {
+	c, err := is.Service.Get(ctx, id)
+	defer func(begin time.Time) {
+		is.counter.With("method", "get").Add(1)
+		is.histogram.With("method", "get", "success", fmt.Sprint(err == nil)).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	return c, err
+}
+
+func (is *instrumentService) Update(ctx context.Context, id, name, address string) (*Customer, error) {
+	c, err := is.Service.Update(ctx, id, name, address)
+	defer func(begin time.Time) {
+		is.counter.With("method", "update").Add(1)
+		is.histogram.With("method", "update", "success", fmt.Sprint(err == nil)).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	return c, err
+}
+
+func (is *instrumentService) Delete(ctx context.Context, id string) error {
+	err := is.Service.Delete(ctx, id)
+	defer func(begin time.Time) {
+		is.counter.With("method", "delete").Add(1)
+		is.histogram.With("method", "delete", "success", fmt.Sprint(err == nil)).Observe(time.Since(begin).Seconds())
+	}(time.Now())
 	return err
 }
 
-func (is *instrumentService) Rent(ctx context.Context, customerID, dvdID string) error {
-	err := is.Service.Rent(ctx, customerID, dvdID)
+func (is *instrumentService) Rent(ctx context.Context, customerID, dvdID string) (*Rental, error) {
+	r, err := is.Service.Rent(ctx, customerID, dvdID)
 	defer func(begin time.Time) {
 		is.counter.With("method", "rentDVD").Add(1)
 		is.histogram.With("method", "rentDVD", "success", fmt.Sprint(err == nil)).Observe(time.Since(begin).Seconds())
 	}(time.Now())
+	return r, err
+}
+
+func (is *instrumentService) Buy(ctx context.Context, customerID, dvdID string) (*Purchase, error) {
+	p, err := is.Service.Buy(ctx, customerID, dvdID)
+	defer func(begin time.Time) {
+		is.counter.With("method", "buyDVD").Add(1)
+		is.histogram.With("method", "buyDVD", "success", fmt.Sprint(err == nil)).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	return p, err
+}
+
+func (is *instrumentService) Return(ctx context.Context, customerID, dvdID string) (*Rental, error) {
+	r, err := is.Service.Return(ctx, customerID, dvdID)
+	defer func(begin time.Time) {
+		is.counter.With("method", "returnDVD").Add(1)
+		is.histogram.With("method", "returnDVD", "success", fmt.Sprint(err == nil)).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	return r, err
+}
+
+func (is *instrumentService) Balance(ctx context.Context, id string) (int64, error) {
+	b, err := is.Service.Balance(ctx, id)
+	defer func(begin time.Time) {
+		is.counter.With("method", "balance").Add(1)
+		is.histogram.With("method", "balance", "success", fmt.Sprint(err == nil)).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	return b, err
+}
+
+func (is *instrumentService) JoinWaitlist(ctx context.Context, customerID, titleID string) (*Reservation, error) {
+	r, err := is.Service.JoinWaitlist(ctx, customerID, titleID)
+	defer func(begin time.Time) {
+		is.counter.With("method", "joinWaitlist").Add(1)
+		is.histogram.With("method", "joinWaitlist", "success", fmt.Sprint(err == nil)).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	return r, err
+}
+
+func (is *instrumentService) LeaveWaitlist(ctx context.Context, customerID, titleID string) error {
+	err := is.Service.LeaveWaitlist(ctx, customerID, titleID)
+	defer func(begin time.Time) {
+		is.counter.With("method", "leaveWaitlist").Add(1)
+		is.histogram.With("method", "leaveWaitlist", "success", fmt.Sprint(err == nil)).Observe(time.Since(begin).Seconds())
+	}(time.Now())
 	return err
-}
\ No newline at end of file
+}
+
+func (is *instrumentService) ListWaitlist(ctx context.Context, titleID string) ([]Reservation, error) {
+	r, err := is.Service.ListWaitlist(ctx, titleID)
+	defer func(begin time.Time) {
+		is.counter.With("method", "listWaitlist").Add(1)
+		is.histogram.With("method", "listWaitlist", "success", fmt.Sprint(err == nil)).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	return r, err
+}
+
+func (is *instrumentService) ListDVDs(ctx context.Context, name, status, cursor string, limit int) (*DVDPage, error) {
+	p, err := is.Service.ListDVDs(ctx, name, status, cursor, limit)
+	defer func(begin time.Time) {
+		is.counter.With("method", "listDVDs").Add(1)
+		is.histogram.With("method", "listDVDs", "success", fmt.Sprint(err == nil)).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	return p, err
+}
@@ -49,6 +49,20 @@ func (_m *Repository) GetByID(q string) (*customer.Customer, error) {
 	return r0, r1
 }
 
+// Restore provides a mock function with given fields: c
+func (_m *Repository) Restore(c *customer.Customer) error {
+	ret := _m.Called(c)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*customer.Customer) error); ok {
+		r0 = rf(c)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Store provides a mock function with given fields: c
 func (_m *Repository) Store(c *customer.Customer) error {
 	ret := _m.Called(c)
@@ -0,0 +1,757 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: customer.proto
+
+package pb
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type Customer struct {
+	Id      string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name    string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Address string `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
+	// balance the customer owes in late fees, in cents
+	Balance              int64    `protobuf:"varint,4,opt,name=balance,proto3" json:"balance,omitempty"`
+	CreatedAt            int64    `protobuf:"varint,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Customer) Reset()         { *m = Customer{} }
+func (m *Customer) String() string { return proto.CompactTextString(m) }
+func (*Customer) ProtoMessage()    {}
+func (*Customer) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9efa92dae3d6ec46, []int{0}
+}
+
+func (m *Customer) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Customer.Unmarshal(m, b)
+}
+func (m *Customer) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Customer.Marshal(b, m, deterministic)
+}
+func (m *Customer) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Customer.Merge(m, src)
+}
+func (m *Customer) XXX_Size() int {
+	return xxx_messageInfo_Customer.Size(m)
+}
+func (m *Customer) XXX_DiscardUnknown() {
+	xxx_messageInfo_Customer.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Customer proto.InternalMessageInfo
+
+func (m *Customer) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Customer) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Customer) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *Customer) GetBalance() int64 {
+	if m != nil {
+		return m.Balance
+	}
+	return 0
+}
+
+func (m *Customer) GetCreatedAt() int64 {
+	if m != nil {
+		return m.CreatedAt
+	}
+	return 0
+}
+
+// Rental of a dvd by a customer, returned_at is 0 until the dvd is returned
+type Rental struct {
+	Id         string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CustomerId string `protobuf:"bytes,2,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	DvdId      string `protobuf:"bytes,3,opt,name=dvd_id,json=dvdId,proto3" json:"dvd_id,omitempty"`
+	RentedAt   int64  `protobuf:"varint,4,opt,name=rented_at,json=rentedAt,proto3" json:"rented_at,omitempty"`
+	DueAt      int64  `protobuf:"varint,5,opt,name=due_at,json=dueAt,proto3" json:"due_at,omitempty"`
+	ReturnedAt int64  `protobuf:"varint,6,opt,name=returned_at,json=returnedAt,proto3" json:"returned_at,omitempty"`
+	// late_fee charged on return, in cents
+	LateFee              int64    `protobuf:"varint,7,opt,name=late_fee,json=lateFee,proto3" json:"late_fee,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Rental) Reset()         { *m = Rental{} }
+func (m *Rental) String() string { return proto.CompactTextString(m) }
+func (*Rental) ProtoMessage()    {}
+func (*Rental) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9efa92dae3d6ec46, []int{1}
+}
+
+func (m *Rental) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Rental.Unmarshal(m, b)
+}
+func (m *Rental) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Rental.Marshal(b, m, deterministic)
+}
+func (m *Rental) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Rental.Merge(m, src)
+}
+func (m *Rental) XXX_Size() int {
+	return xxx_messageInfo_Rental.Size(m)
+}
+func (m *Rental) XXX_DiscardUnknown() {
+	xxx_messageInfo_Rental.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Rental proto.InternalMessageInfo
+
+func (m *Rental) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Rental) GetCustomerId() string {
+	if m != nil {
+		return m.CustomerId
+	}
+	return ""
+}
+
+func (m *Rental) GetDvdId() string {
+	if m != nil {
+		return m.DvdId
+	}
+	return ""
+}
+
+func (m *Rental) GetRentedAt() int64 {
+	if m != nil {
+		return m.RentedAt
+	}
+	return 0
+}
+
+func (m *Rental) GetDueAt() int64 {
+	if m != nil {
+		return m.DueAt
+	}
+	return 0
+}
+
+func (m *Rental) GetReturnedAt() int64 {
+	if m != nil {
+		return m.ReturnedAt
+	}
+	return 0
+}
+
+func (m *Rental) GetLateFee() int64 {
+	if m != nil {
+		return m.LateFee
+	}
+	return 0
+}
+
+type RegisterRequest struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Address              string   `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RegisterRequest) Reset()         { *m = RegisterRequest{} }
+func (m *RegisterRequest) String() string { return proto.CompactTextString(m) }
+func (*RegisterRequest) ProtoMessage()    {}
+func (*RegisterRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9efa92dae3d6ec46, []int{2}
+}
+
+func (m *RegisterRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RegisterRequest.Unmarshal(m, b)
+}
+func (m *RegisterRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RegisterRequest.Marshal(b, m, deterministic)
+}
+func (m *RegisterRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RegisterRequest.Merge(m, src)
+}
+func (m *RegisterRequest) XXX_Size() int {
+	return xxx_messageInfo_RegisterRequest.Size(m)
+}
+func (m *RegisterRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RegisterRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RegisterRequest proto.InternalMessageInfo
+
+func (m *RegisterRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *RegisterRequest) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+type RegisterResponse struct {
+	Customer             *Customer `protobuf:"bytes,1,opt,name=customer,proto3" json:"customer,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *RegisterResponse) Reset()         { *m = RegisterResponse{} }
+func (m *RegisterResponse) String() string { return proto.CompactTextString(m) }
+func (*RegisterResponse) ProtoMessage()    {}
+func (*RegisterResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9efa92dae3d6ec46, []int{3}
+}
+
+func (m *RegisterResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RegisterResponse.Unmarshal(m, b)
+}
+func (m *RegisterResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RegisterResponse.Marshal(b, m, deterministic)
+}
+func (m *RegisterResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RegisterResponse.Merge(m, src)
+}
+func (m *RegisterResponse) XXX_Size() int {
+	return xxx_messageInfo_RegisterResponse.Size(m)
+}
+func (m *RegisterResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RegisterResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RegisterResponse proto.InternalMessageInfo
+
+func (m *RegisterResponse) GetCustomer() *Customer {
+	if m != nil {
+		return m.Customer
+	}
+	return nil
+}
+
+type RentRequest struct {
+	CustomerId           string   `protobuf:"bytes,1,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	DvdId                string   `protobuf:"bytes,2,opt,name=dvd_id,json=dvdId,proto3" json:"dvd_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RentRequest) Reset()         { *m = RentRequest{} }
+func (m *RentRequest) String() string { return proto.CompactTextString(m) }
+func (*RentRequest) ProtoMessage()    {}
+func (*RentRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9efa92dae3d6ec46, []int{4}
+}
+
+func (m *RentRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RentRequest.Unmarshal(m, b)
+}
+func (m *RentRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RentRequest.Marshal(b, m, deterministic)
+}
+func (m *RentRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RentRequest.Merge(m, src)
+}
+func (m *RentRequest) XXX_Size() int {
+	return xxx_messageInfo_RentRequest.Size(m)
+}
+func (m *RentRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RentRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RentRequest proto.InternalMessageInfo
+
+func (m *RentRequest) GetCustomerId() string {
+	if m != nil {
+		return m.CustomerId
+	}
+	return ""
+}
+
+func (m *RentRequest) GetDvdId() string {
+	if m != nil {
+		return m.DvdId
+	}
+	return ""
+}
+
+type RentResponse struct {
+	Rental               *Rental  `protobuf:"bytes,1,opt,name=rental,proto3" json:"rental,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RentResponse) Reset()         { *m = RentResponse{} }
+func (m *RentResponse) String() string { return proto.CompactTextString(m) }
+func (*RentResponse) ProtoMessage()    {}
+func (*RentResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9efa92dae3d6ec46, []int{5}
+}
+
+func (m *RentResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RentResponse.Unmarshal(m, b)
+}
+func (m *RentResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RentResponse.Marshal(b, m, deterministic)
+}
+func (m *RentResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RentResponse.Merge(m, src)
+}
+func (m *RentResponse) XXX_Size() int {
+	return xxx_messageInfo_RentResponse.Size(m)
+}
+func (m *RentResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RentResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RentResponse proto.InternalMessageInfo
+
+func (m *RentResponse) GetRental() *Rental {
+	if m != nil {
+		return m.Rental
+	}
+	return nil
+}
+
+type ReturnRequest struct {
+	CustomerId           string   `protobuf:"bytes,1,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	DvdId                string   `protobuf:"bytes,2,opt,name=dvd_id,json=dvdId,proto3" json:"dvd_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReturnRequest) Reset()         { *m = ReturnRequest{} }
+func (m *ReturnRequest) String() string { return proto.CompactTextString(m) }
+func (*ReturnRequest) ProtoMessage()    {}
+func (*ReturnRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9efa92dae3d6ec46, []int{6}
+}
+
+func (m *ReturnRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReturnRequest.Unmarshal(m, b)
+}
+func (m *ReturnRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReturnRequest.Marshal(b, m, deterministic)
+}
+func (m *ReturnRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReturnRequest.Merge(m, src)
+}
+func (m *ReturnRequest) XXX_Size() int {
+	return xxx_messageInfo_ReturnRequest.Size(m)
+}
+func (m *ReturnRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReturnRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReturnRequest proto.InternalMessageInfo
+
+func (m *ReturnRequest) GetCustomerId() string {
+	if m != nil {
+		return m.CustomerId
+	}
+	return ""
+}
+
+func (m *ReturnRequest) GetDvdId() string {
+	if m != nil {
+		return m.DvdId
+	}
+	return ""
+}
+
+type ReturnResponse struct {
+	Rental               *Rental  `protobuf:"bytes,1,opt,name=rental,proto3" json:"rental,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReturnResponse) Reset()         { *m = ReturnResponse{} }
+func (m *ReturnResponse) String() string { return proto.CompactTextString(m) }
+func (*ReturnResponse) ProtoMessage()    {}
+func (*ReturnResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9efa92dae3d6ec46, []int{7}
+}
+
+func (m *ReturnResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReturnResponse.Unmarshal(m, b)
+}
+func (m *ReturnResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReturnResponse.Marshal(b, m, deterministic)
+}
+func (m *ReturnResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReturnResponse.Merge(m, src)
+}
+func (m *ReturnResponse) XXX_Size() int {
+	return xxx_messageInfo_ReturnResponse.Size(m)
+}
+func (m *ReturnResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReturnResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReturnResponse proto.InternalMessageInfo
+
+func (m *ReturnResponse) GetRental() *Rental {
+	if m != nil {
+		return m.Rental
+	}
+	return nil
+}
+
+type GetRequest struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetRequest) Reset()         { *m = GetRequest{} }
+func (m *GetRequest) String() string { return proto.CompactTextString(m) }
+func (*GetRequest) ProtoMessage()    {}
+func (*GetRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9efa92dae3d6ec46, []int{8}
+}
+
+func (m *GetRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetRequest.Unmarshal(m, b)
+}
+func (m *GetRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetRequest.Marshal(b, m, deterministic)
+}
+func (m *GetRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetRequest.Merge(m, src)
+}
+func (m *GetRequest) XXX_Size() int {
+	return xxx_messageInfo_GetRequest.Size(m)
+}
+func (m *GetRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetRequest proto.InternalMessageInfo
+
+func (m *GetRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type GetResponse struct {
+	Customer             *Customer `protobuf:"bytes,1,opt,name=customer,proto3" json:"customer,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *GetResponse) Reset()         { *m = GetResponse{} }
+func (m *GetResponse) String() string { return proto.CompactTextString(m) }
+func (*GetResponse) ProtoMessage()    {}
+func (*GetResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9efa92dae3d6ec46, []int{9}
+}
+
+func (m *GetResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetResponse.Unmarshal(m, b)
+}
+func (m *GetResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetResponse.Marshal(b, m, deterministic)
+}
+func (m *GetResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetResponse.Merge(m, src)
+}
+func (m *GetResponse) XXX_Size() int {
+	return xxx_messageInfo_GetResponse.Size(m)
+}
+func (m *GetResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetResponse proto.InternalMessageInfo
+
+func (m *GetResponse) GetCustomer() *Customer {
+	if m != nil {
+		return m.Customer
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*Customer)(nil), "pb.Customer")
+	proto.RegisterType((*Rental)(nil), "pb.Rental")
+	proto.RegisterType((*RegisterRequest)(nil), "pb.RegisterRequest")
+	proto.RegisterType((*RegisterResponse)(nil), "pb.RegisterResponse")
+	proto.RegisterType((*RentRequest)(nil), "pb.RentRequest")
+	proto.RegisterType((*RentResponse)(nil), "pb.RentResponse")
+	proto.RegisterType((*ReturnRequest)(nil), "pb.ReturnRequest")
+	proto.RegisterType((*ReturnResponse)(nil), "pb.ReturnResponse")
+	proto.RegisterType((*GetRequest)(nil), "pb.GetRequest")
+	proto.RegisterType((*GetResponse)(nil), "pb.GetResponse")
+}
+
+func init() { proto.RegisterFile("customer.proto", fileDescriptor_9efa92dae3d6ec46) }
+
+var fileDescriptor_9efa92dae3d6ec46 = []byte{
+	// 438 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x53, 0x4d, 0x8f, 0x12, 0x41,
+	0x10, 0x4d, 0x0f, 0x30, 0x3b, 0x14, 0x2b, 0xac, 0xa5, 0x26, 0x23, 0x6a, 0xdc, 0xf4, 0xc1, 0xe0,
+	0x05, 0x13, 0xd4, 0x78, 0x31, 0x31, 0xc4, 0x28, 0xd9, 0x6b, 0xfb, 0x03, 0x48, 0x43, 0x97, 0x66,
+	0x12, 0x76, 0x18, 0x7b, 0x7a, 0xb8, 0xfb, 0xcf, 0xfc, 0x01, 0xfe, 0x28, 0xd3, 0x5f, 0x33, 0xb0,
+	0x1a, 0x13, 0xe2, 0x8d, 0x7a, 0xaf, 0xab, 0xde, 0xab, 0x57, 0x0c, 0x8c, 0xb7, 0x4d, 0x6d, 0xf6,
+	0xb7, 0xa4, 0xe7, 0x95, 0xde, 0x9b, 0x3d, 0x26, 0xd5, 0x86, 0xff, 0x60, 0x90, 0x7d, 0x0c, 0x30,
+	0x8e, 0x21, 0x29, 0x54, 0xce, 0xae, 0xd9, 0x6c, 0x28, 0x92, 0x42, 0x21, 0x42, 0xbf, 0x94, 0xb7,
+	0x94, 0x27, 0x0e, 0x71, 0xbf, 0x31, 0x87, 0x0b, 0xa9, 0x94, 0xa6, 0xba, 0xce, 0x7b, 0x0e, 0x8e,
+	0xa5, 0x65, 0x36, 0x72, 0x27, 0xcb, 0x2d, 0xe5, 0xfd, 0x6b, 0x36, 0xeb, 0x89, 0x58, 0xe2, 0x33,
+	0x80, 0xad, 0x26, 0x69, 0x48, 0xad, 0xa5, 0xc9, 0x07, 0x8e, 0x1c, 0x06, 0x64, 0x69, 0xf8, 0x4f,
+	0x06, 0xa9, 0xa0, 0xd2, 0xc8, 0xdd, 0x1f, 0x0e, 0x9e, 0xc3, 0x28, 0x9a, 0x5e, 0x17, 0x2a, 0x18,
+	0x81, 0x08, 0xdd, 0x28, 0x7c, 0x04, 0xa9, 0x3a, 0x28, 0xcb, 0x79, 0x37, 0x03, 0x75, 0x50, 0x37,
+	0x0a, 0x9f, 0xc0, 0x50, 0x53, 0x19, 0x04, 0xbd, 0x9b, 0xcc, 0x03, 0x4b, 0xe3, 0x7a, 0x1a, 0xea,
+	0xac, 0x0c, 0x54, 0x43, 0x4b, 0x63, 0xb5, 0x34, 0x99, 0x46, 0x97, 0xbe, 0x2b, 0x75, 0x1c, 0x44,
+	0x68, 0x69, 0xf0, 0x31, 0x64, 0x3b, 0x69, 0x68, 0xfd, 0x95, 0x28, 0xbf, 0xf0, 0x1b, 0xda, 0xfa,
+	0x33, 0x11, 0xff, 0x00, 0x13, 0x41, 0xdf, 0x8a, 0xda, 0x90, 0x16, 0xf4, 0xbd, 0xa1, 0xda, 0xb4,
+	0xe1, 0xb1, 0xbf, 0x87, 0x97, 0x9c, 0x84, 0xc7, 0xdf, 0xc3, 0x55, 0x37, 0xa0, 0xae, 0xf6, 0x65,
+	0x4d, 0x38, 0x83, 0x2c, 0x6e, 0xea, 0xa6, 0x8c, 0x16, 0x97, 0xf3, 0x6a, 0x33, 0x8f, 0xe7, 0x12,
+	0x2d, 0xcb, 0x3f, 0xc1, 0xc8, 0x06, 0x18, 0xa5, 0xef, 0xa4, 0xc6, 0xfe, 0x91, 0x5a, 0x72, 0x94,
+	0x1a, 0x5f, 0xc0, 0xa5, 0x1f, 0x13, 0x0c, 0x70, 0x48, 0xb5, 0xbb, 0x4b, 0x90, 0x07, 0x2b, 0xef,
+	0x2f, 0x25, 0x02, 0xc3, 0x57, 0x70, 0x4f, 0xb8, 0x88, 0xfe, 0x57, 0xfc, 0x0d, 0x8c, 0xe3, 0xa0,
+	0x33, 0xe4, 0x9f, 0x02, 0xac, 0xa8, 0x5d, 0xfc, 0xce, 0xdf, 0x87, 0xbf, 0x83, 0x91, 0x63, 0xcf,
+	0x0d, 0x74, 0xf1, 0x8b, 0xc1, 0x24, 0xc2, 0x5f, 0x48, 0x1f, 0x8a, 0x2d, 0xe1, 0x5b, 0xc8, 0xe2,
+	0x89, 0xf0, 0x81, 0xb7, 0x72, 0x72, 0xf1, 0xe9, 0xc3, 0x53, 0x30, 0x88, 0xbe, 0x84, 0xbe, 0xf5,
+	0x8c, 0x93, 0xe8, 0x3e, 0x3e, 0xbf, 0xea, 0x80, 0xf0, 0xf4, 0x15, 0xa4, 0x3e, 0x02, 0xbc, 0xef,
+	0xb9, 0xa3, 0x5c, 0xa7, 0x78, 0x0c, 0x85, 0x86, 0x17, 0xd0, 0x5b, 0x91, 0xc1, 0xb1, 0xa5, 0xba,
+	0x18, 0xa6, 0x93, 0xb6, 0xf6, 0xef, 0x36, 0xa9, 0xfb, 0xe0, 0x5f, 0xff, 0x1e, 0x00, 0x13, 0x38,
+	0x45, 0x89, 0x02, 0x04, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// CustomerServiceClient is the client API for CustomerService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type CustomerServiceClient interface {
+	Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*RegisterResponse, error)
+	Rent(ctx context.Context, in *RentRequest, opts ...grpc.CallOption) (*RentResponse, error)
+	Return(ctx context.Context, in *ReturnRequest, opts ...grpc.CallOption) (*ReturnResponse, error)
+	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error)
+}
+
+type customerServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewCustomerServiceClient(cc *grpc.ClientConn) CustomerServiceClient {
+	return &customerServiceClient{cc}
+}
+
+func (c *customerServiceClient) Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*RegisterResponse, error) {
+	out := new(RegisterResponse)
+	err := c.cc.Invoke(ctx, "/pb.CustomerService/Register", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customerServiceClient) Rent(ctx context.Context, in *RentRequest, opts ...grpc.CallOption) (*RentResponse, error) {
+	out := new(RentResponse)
+	err := c.cc.Invoke(ctx, "/pb.CustomerService/Rent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customerServiceClient) Return(ctx context.Context, in *ReturnRequest, opts ...grpc.CallOption) (*ReturnResponse, error) {
+	out := new(ReturnResponse)
+	err := c.cc.Invoke(ctx, "/pb.CustomerService/Return", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customerServiceClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error) {
+	out := new(GetResponse)
+	err := c.cc.Invoke(ctx, "/pb.CustomerService/Get", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CustomerServiceServer is the server API for CustomerService service.
+type CustomerServiceServer interface {
+	Register(context.Context, *RegisterRequest) (*RegisterResponse, error)
+	Rent(context.Context, *RentRequest) (*RentResponse, error)
+	Return(context.Context, *ReturnRequest) (*ReturnResponse, error)
+	Get(context.Context, *GetRequest) (*GetResponse, error)
+}
+
+// UnimplementedCustomerServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedCustomerServiceServer struct {
+}
+
+func (*UnimplementedCustomerServiceServer) Register(ctx context.Context, req *RegisterRequest) (*RegisterResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Register not implemented")
+}
+func (*UnimplementedCustomerServiceServer) Rent(ctx context.Context, req *RentRequest) (*RentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Rent not implemented")
+}
+func (*UnimplementedCustomerServiceServer) Return(ctx context.Context, req *ReturnRequest) (*ReturnResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Return not implemented")
+}
+func (*UnimplementedCustomerServiceServer) Get(ctx context.Context, req *GetRequest) (*GetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+
+func RegisterCustomerServiceServer(s *grpc.Server, srv CustomerServiceServer) {
+	s.RegisterService(&_CustomerService_serviceDesc, srv)
+}
+
+func _CustomerService_Register_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomerServiceServer).Register(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.CustomerService/Register",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomerServiceServer).Register(ctx, req.(*RegisterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomerService_Rent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomerServiceServer).Rent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.CustomerService/Rent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomerServiceServer).Rent(ctx, req.(*RentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomerService_Return_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReturnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomerServiceServer).Return(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.CustomerService/Return",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomerServiceServer).Return(ctx, req.(*ReturnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomerService_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomerServiceServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.CustomerService/Get",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomerServiceServer).Get(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _CustomerService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.CustomerService",
+	HandlerType: (*CustomerServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Register",
+			Handler:    _CustomerService_Register_Handler,
+		},
+		{
+			MethodName: "Rent",
+			Handler:    _CustomerService_Rent_Handler,
+		},
+		{
+			MethodName: "Return",
+			Handler:    _CustomerService_Return_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _CustomerService_Get_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "customer.proto",
+}
@@ -0,0 +1,3 @@
+#!/bin/bash
+
+protoc customer.proto --go_out=plugins=grpc:.
\ No newline at end of file
@@ -2,34 +2,80 @@ package customer
 
 import (
 	"context"
-	"errors"
 	"time"
 
-	"github.com/go-kit/kit/circuitbreaker"
 	"github.com/go-kit/kit/endpoint"
 	"github.com/go-kit/kit/log"
-	"github.com/go-kit/kit/ratelimit"
 	"github.com/go-kit/kit/tracing/opentracing"
 	grpctransport "github.com/go-kit/kit/transport/grpc"
 	"github.com/ngray1747/dvd-rental/dvd/pb"
+	"github.com/ngray1747/dvd-rental/internal/apperr"
+	"github.com/ngray1747/dvd-rental/internal/limits"
 	stdopentracing "github.com/opentracing/opentracing-go"
-	"github.com/sony/gobreaker"
-	"golang.org/x/time/rate"
 	"google.golang.org/grpc"
 )
+
 type ProxyMiddleware func(ProxyService) ProxyService
 type ProxyService interface {
-	UpdateDVDStatus(ctx context.Context, DVDID string) error
+	UpdateDVDStatus(ctx context.Context, customerID, DVDID string) error
+	// ReturnDVD returns the dvd rented by customerID, a dvd rented by another customer is left alone
+	ReturnDVD(ctx context.Context, customerID, DVDID string) (*DVD, error)
+	// ReleaseDVD undoes UpdateDVDStatus, it is a no-op once the dvd is no longer rented by the customer
+	ReleaseDVD(ctx context.Context, customerID, DVDID string) error
+	SellDVD(ctx context.Context, DVDID string) (*DVD, error)
+	JoinWaitlist(ctx context.Context, customerID, titleID string) (*Reservation, error)
+	LeaveWaitlist(ctx context.Context, customerID, titleID string) error
+	ListWaitlist(ctx context.Context, titleID string) ([]Reservation, error)
+	ListDVDs(ctx context.Context, name, status, cursor string, limit int) (*DVDPage, error)
+}
+
+// DVD is the dvd service's view of a dvd
+type DVD struct {
+	ID        string    `json:"id"`
+	TitleID   string    `json:"title_id"`
+	Name      string    `json:"name"`
+	Barcode   string    `json:"barcode"`
+	Condition string    `json:"condition"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	// ReplacementCost and Price of the dvd in cents, zero when unknown
+	ReplacementCost int64 `json:"replacement_cost,omitempty"`
+	Price           int64 `json:"price,omitempty"`
+	// ReservedFor is the customer a Reserved dvd is held for until HeldUntil
+	ReservedFor string     `json:"reserved_for,omitempty"`
+	HeldUntil   *time.Time `json:"held_until,omitempty"`
+}
+
+// Reservation is the place of a customer in the waitlist of a title
+type Reservation struct {
+	ID         string    `json:"id"`
+	TitleID    string    `json:"title_id"`
+	CustomerID string    `json:"customer_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// DVDPage is a chunk of the dvd catalog, NextCursor is empty on the last page
+type DVDPage struct {
+	DVDs       []DVD  `json:"dvds"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 type proxymw struct {
 	context.Context
 	ProxyService
 	UpdateDVDStatusEndpoint endpoint.Endpoint
+	ReturnDVDEndpoint       endpoint.Endpoint
+	ReleaseDVDEndpoint      endpoint.Endpoint
+	SellDVDEndpoint         endpoint.Endpoint
+	JoinWaitlistEndpoint    endpoint.Endpoint
+	LeaveWaitlistEndpoint   endpoint.Endpoint
+	ListWaitlistEndpoint    endpoint.Endpoint
+	ListDVDsEndpoint        endpoint.Endpoint
 }
 
 type updateDVDStatusRequest struct {
-	ID string
+	CustomerID string
+	ID         string
 }
 
 type updateDVDStatusResponse struct {
@@ -38,25 +84,210 @@ type updateDVDStatusResponse struct {
 
 func encodeRentDVDRequest(_ context.Context, request interface{}) (interface{}, error) {
 	req := request.(updateDVDStatusRequest)
-	return &pb.RentDVDRequest{Id: req.ID}, nil
+	return &pb.RentDVDRequest{Id: req.ID, CustomerId: req.CustomerID}, nil
 }
 
 func decodeRentDVDResponse(_ context.Context, response interface{}) (interface{}, error) {
-	resp := response.(*pb.RentDVDResponse)
-	return updateDVDStatusResponse{Err: strToError(resp.Err)}, nil
+	_ = response.(*pb.RentDVDResponse)
+	return updateDVDStatusResponse{}, nil
+}
+
+type returnDVDRequest struct {
+	CustomerID string
+	ID         string
+}
+
+type returnDVDResponse struct {
+	DVD *DVD
+	Err error
+}
+
+func encodeReturnDVDRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(returnDVDRequest)
+	return &pb.ReturnDVDRequest{Id: req.ID, CustomerId: req.CustomerID}, nil
+}
+
+func decodeReturnDVDResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(*pb.ReturnDVDResponse)
+	d := toDVD(resp.Dvd)
+	return returnDVDResponse{DVD: &d}, nil
+}
+
+type releaseDVDResponse struct {
+	Err error
+}
+
+func encodeReleaseDVDRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(updateDVDStatusRequest)
+	return &pb.ReleaseDVDRequest{Id: req.ID, CustomerId: req.CustomerID}, nil
+}
+
+func decodeReleaseDVDResponse(_ context.Context, response interface{}) (interface{}, error) {
+	_ = response.(*pb.ReleaseDVDResponse)
+	return releaseDVDResponse{}, nil
 }
 
-func strToError(err string) error {
-	if err == "" {
-		return nil
+type sellDVDRequest struct {
+	ID string
+}
+
+type sellDVDResponse struct {
+	DVD *DVD
+	Err error
+}
+
+func encodeSellDVDRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(sellDVDRequest)
+	return &pb.SellDVDRequest{Id: req.ID}, nil
+}
+
+func decodeSellDVDResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(*pb.SellDVDResponse)
+	d := toDVD(resp.Dvd)
+	return sellDVDResponse{DVD: &d}, nil
+}
+
+type waitlistRequest struct {
+	CustomerID string
+	TitleID    string
+}
+
+type reserveResponse struct {
+	Reservation *Reservation
+	Err         error
+}
+
+func encodeJoinWaitlistRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(waitlistRequest)
+	return &pb.JoinWaitlistRequest{TitleId: req.TitleID, CustomerId: req.CustomerID}, nil
+}
+
+func decodeJoinWaitlistResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(*pb.JoinWaitlistResponse)
+	r := toReservation(resp.Reservation)
+	return reserveResponse{Reservation: &r}, nil
+}
+
+type cancelReservationResponse struct {
+	Err error
+}
+
+func encodeLeaveWaitlistRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(waitlistRequest)
+	return &pb.LeaveWaitlistRequest{TitleId: req.TitleID, CustomerId: req.CustomerID}, nil
+}
+
+func decodeLeaveWaitlistResponse(_ context.Context, response interface{}) (interface{}, error) {
+	_ = response.(*pb.LeaveWaitlistResponse)
+	return cancelReservationResponse{}, nil
+}
+
+type fetchWaitlistResponse struct {
+	Reservations []Reservation
+	Err          error
+}
+
+func encodeListWaitlistRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(waitlistRequest)
+	return &pb.ListWaitlistRequest{TitleId: req.TitleID}, nil
+}
+
+func decodeListWaitlistResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(*pb.ListWaitlistResponse)
+	reservations := make([]Reservation, 0, len(resp.Reservations))
+	for _, r := range resp.Reservations {
+		reservations = append(reservations, toReservation(r))
 	}
+	return fetchWaitlistResponse{Reservations: reservations}, nil
+}
+
+func toReservation(r *pb.Reservation) Reservation {
+	return Reservation{
+		ID:         r.Id,
+		TitleID:    r.TitleId,
+		CustomerID: r.CustomerId,
+		CreatedAt:  time.Unix(r.CreatedAt, 0),
+	}
+}
 
-	return errors.New(err)
+type fetchDVDsRequest struct {
+	Name   string
+	Status string
+	Cursor string
+	Limit  int
 }
 
-func (pm proxymw) UpdateDVDStatus(ctx context.Context, DVDID string) error {
+type fetchDVDsResponse struct {
+	Page *DVDPage
+	Err  error
+}
+
+func encodeListDVDsRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(fetchDVDsRequest)
+	return &pb.ListDVDsRequest{
+		Name:   req.Name,
+		Status: req.Status,
+		Cursor: req.Cursor,
+		Limit:  int32(req.Limit),
+	}, nil
+}
+
+func decodeListDVDsResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(*pb.ListDVDsResponse)
+	page := &DVDPage{
+		DVDs:       make([]DVD, 0, len(resp.Dvds)),
+		NextCursor: resp.NextCursor,
+	}
+	for _, d := range resp.Dvds {
+		page.DVDs = append(page.DVDs, toDVD(d))
+	}
+	return fetchDVDsResponse{Page: page}, nil
+}
+
+func toDVD(d *pb.DVD) DVD {
+	dvd := DVD{
+		ID:        d.Id,
+		TitleID:   d.TitleId,
+		Name:      d.Name,
+		Barcode:   d.Barcode,
+		Condition: d.Condition,
+		Status:    d.Status,
+		CreatedAt: time.Unix(d.CreatedAt, 0),
+
+		ReplacementCost: d.ReplacementCost,
+		Price:           d.Price,
+		ReservedFor:     d.ReservedFor,
+	}
+	if d.HeldUntil != 0 {
+		heldUntil := time.Unix(d.HeldUntil, 0)
+		dvd.HeldUntil = &heldUntil
+	}
+	return dvd
+}
+
+// domainErrors turns the domain errors a call to the dvd service fails with into a response built by failed,
+// so only failures of the dvd service itself count towards tripping the circuit breaker.
+// Every error is converted back from its gRPC status so callers can inspect its code.
+func domainErrors(failed func(error) interface{}) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			response, err := next(ctx, request)
+			if err == nil {
+				return response, nil
+			}
+			err = apperr.FromGRPC(err)
+			if apperr.IsDomain(err) {
+				return failed(err), nil
+			}
+			return nil, err
+		}
+	}
+}
+
+func (pm proxymw) UpdateDVDStatus(ctx context.Context, customerID, DVDID string) error {
 	response, err := pm.UpdateDVDStatusEndpoint(ctx, updateDVDStatusRequest{
-		ID: DVDID,
+		CustomerID: customerID,
+		ID:         DVDID,
 	})
 	if err != nil {
 		return err
@@ -65,9 +296,94 @@ func (pm proxymw) UpdateDVDStatus(ctx context.Context, DVDID string) error {
 	return resp.Err
 }
 
-func NewProxyMiddleware(conn *grpc.ClientConn, ctx context.Context, ot stdopentracing.Tracer, logger log.Logger) ProxyMiddleware {
+func (pm proxymw) ReturnDVD(ctx context.Context, customerID, DVDID string) (*DVD, error) {
+	response, err := pm.ReturnDVDEndpoint(ctx, returnDVDRequest{
+		CustomerID: customerID,
+		ID:         DVDID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := response.(returnDVDResponse)
+	return resp.DVD, resp.Err
+}
+
+func (pm proxymw) ReleaseDVD(ctx context.Context, customerID, DVDID string) error {
+	response, err := pm.ReleaseDVDEndpoint(ctx, updateDVDStatusRequest{
+		CustomerID: customerID,
+		ID:         DVDID,
+	})
+	if err != nil {
+		return err
+	}
+	resp := response.(releaseDVDResponse)
+	return resp.Err
+}
+
+func (pm proxymw) SellDVD(ctx context.Context, DVDID string) (*DVD, error) {
+	response, err := pm.SellDVDEndpoint(ctx, sellDVDRequest{
+		ID: DVDID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := response.(sellDVDResponse)
+	return resp.DVD, resp.Err
+}
+
+func (pm proxymw) JoinWaitlist(ctx context.Context, customerID, titleID string) (*Reservation, error) {
+	response, err := pm.JoinWaitlistEndpoint(ctx, waitlistRequest{
+		CustomerID: customerID,
+		TitleID:    titleID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := response.(reserveResponse)
+	return resp.Reservation, resp.Err
+}
+
+func (pm proxymw) LeaveWaitlist(ctx context.Context, customerID, titleID string) error {
+	response, err := pm.LeaveWaitlistEndpoint(ctx, waitlistRequest{
+		CustomerID: customerID,
+		TitleID:    titleID,
+	})
+	if err != nil {
+		return err
+	}
+	resp := response.(cancelReservationResponse)
+	return resp.Err
+}
+
+func (pm proxymw) ListWaitlist(ctx context.Context, titleID string) ([]Reservation, error) {
+	response, err := pm.ListWaitlistEndpoint(ctx, waitlistRequest{
+		TitleID: titleID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := response.(fetchWaitlistResponse)
+	return resp.Reservations, resp.Err
+}
+
+func (pm proxymw) ListDVDs(ctx context.Context, name, status, cursor string, limit int) (*DVDPage, error) {
+	response, err := pm.ListDVDsEndpoint(ctx, fetchDVDsRequest{
+		Name:   name,
+		Status: status,
+		Cursor: cursor,
+		Limit:  limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := response.(fetchDVDsResponse)
+	return resp.Page, resp.Err
+}
+
+// NewProxyMiddleware calls the dvd service over conn, the calls share a rate limit and are circuit broken by guard
+func NewProxyMiddleware(conn *grpc.ClientConn, ctx context.Context, ot stdopentracing.Tracer, logger log.Logger, guard *limits.Guard) ProxyMiddleware {
 	return func(svc ProxyService) ProxyService {
-		limiter := ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Every(time.Second), 10))
+		limiter := guard.Limit()
 
 		var opts []grpctransport.ClientOption
 		var rentDVDEndpoint endpoint.Endpoint
@@ -81,13 +397,141 @@ func NewProxyMiddleware(conn *grpc.ClientConn, ctx context.Context, ot stdopentr
 				pb.RentDVDResponse{},
 				append(opts, grpctransport.ClientBefore(opentracing.ContextToGRPC(ot, logger)))...,
 			).Endpoint()
+			rentDVDEndpoint = domainErrors(func(err error) interface{} { return updateDVDStatusResponse{Err: err} })(rentDVDEndpoint)
 			rentDVDEndpoint = opentracing.TraceClient(ot, "RentDVD")(rentDVDEndpoint)
 			rentDVDEndpoint = limiter(rentDVDEndpoint)
-			rentDVDEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
-				Name:    "RentDVD",
-				Timeout: 10 * time.Second,
-			}))(rentDVDEndpoint)
+			rentDVDEndpoint = guard.Break("RentDVD")(rentDVDEndpoint)
+		}
+
+		var returnDVDEndpoint endpoint.Endpoint
+		{
+			returnDVDEndpoint = grpctransport.NewClient(
+				conn,
+				"pb.DVDRental",
+				"ReturnDVD",
+				encodeReturnDVDRequest,
+				decodeReturnDVDResponse,
+				pb.ReturnDVDResponse{},
+				append(opts, grpctransport.ClientBefore(opentracing.ContextToGRPC(ot, logger)))...,
+			).Endpoint()
+			returnDVDEndpoint = domainErrors(func(err error) interface{} { return returnDVDResponse{Err: err} })(returnDVDEndpoint)
+			returnDVDEndpoint = opentracing.TraceClient(ot, "ReturnDVD")(returnDVDEndpoint)
+			returnDVDEndpoint = limiter(returnDVDEndpoint)
+			returnDVDEndpoint = guard.Break("ReturnDVD")(returnDVDEndpoint)
+		}
+
+		var releaseDVDEndpoint endpoint.Endpoint
+		{
+			releaseDVDEndpoint = grpctransport.NewClient(
+				conn,
+				"pb.DVDRental",
+				"ReleaseDVD",
+				encodeReleaseDVDRequest,
+				decodeReleaseDVDResponse,
+				pb.ReleaseDVDResponse{},
+				append(opts, grpctransport.ClientBefore(opentracing.ContextToGRPC(ot, logger)))...,
+			).Endpoint()
+			releaseDVDEndpoint = domainErrors(func(err error) interface{} { return releaseDVDResponse{Err: err} })(releaseDVDEndpoint)
+			releaseDVDEndpoint = opentracing.TraceClient(ot, "ReleaseDVD")(releaseDVDEndpoint)
+			releaseDVDEndpoint = limiter(releaseDVDEndpoint)
+			releaseDVDEndpoint = guard.Break("ReleaseDVD")(releaseDVDEndpoint)
+		}
+
+		var sellDVDEndpoint endpoint.Endpoint
+		{
+			sellDVDEndpoint = grpctransport.NewClient(
+				conn,
+				"pb.DVDRental",
+				"SellDVD",
+				encodeSellDVDRequest,
+				decodeSellDVDResponse,
+				pb.SellDVDResponse{},
+				append(opts, grpctransport.ClientBefore(opentracing.ContextToGRPC(ot, logger)))...,
+			).Endpoint()
+			sellDVDEndpoint = domainErrors(func(err error) interface{} { return sellDVDResponse{Err: err} })(sellDVDEndpoint)
+			sellDVDEndpoint = opentracing.TraceClient(ot, "SellDVD")(sellDVDEndpoint)
+			sellDVDEndpoint = limiter(sellDVDEndpoint)
+			sellDVDEndpoint = guard.Break("SellDVD")(sellDVDEndpoint)
+		}
+
+		var joinWaitlistEndpoint endpoint.Endpoint
+		{
+			joinWaitlistEndpoint = grpctransport.NewClient(
+				conn,
+				"pb.DVDRental",
+				"JoinWaitlist",
+				encodeJoinWaitlistRequest,
+				decodeJoinWaitlistResponse,
+				pb.JoinWaitlistResponse{},
+				append(opts, grpctransport.ClientBefore(opentracing.ContextToGRPC(ot, logger)))...,
+			).Endpoint()
+			joinWaitlistEndpoint = domainErrors(func(err error) interface{} { return reserveResponse{Err: err} })(joinWaitlistEndpoint)
+			joinWaitlistEndpoint = opentracing.TraceClient(ot, "JoinWaitlist")(joinWaitlistEndpoint)
+			joinWaitlistEndpoint = limiter(joinWaitlistEndpoint)
+			joinWaitlistEndpoint = guard.Break("JoinWaitlist")(joinWaitlistEndpoint)
+		}
+
+		var leaveWaitlistEndpoint endpoint.Endpoint
+		{
+			leaveWaitlistEndpoint = grpctransport.NewClient(
+				conn,
+				"pb.DVDRental",
+				"LeaveWaitlist",
+				encodeLeaveWaitlistRequest,
+				decodeLeaveWaitlistResponse,
+				pb.LeaveWaitlistResponse{},
+				append(opts, grpctransport.ClientBefore(opentracing.ContextToGRPC(ot, logger)))...,
+			).Endpoint()
+			leaveWaitlistEndpoint = domainErrors(func(err error) interface{} { return cancelReservationResponse{Err: err} })(leaveWaitlistEndpoint)
+			leaveWaitlistEndpoint = opentracing.TraceClient(ot, "LeaveWaitlist")(leaveWaitlistEndpoint)
+			leaveWaitlistEndpoint = limiter(leaveWaitlistEndpoint)
+			leaveWaitlistEndpoint = guard.Break("LeaveWaitlist")(leaveWaitlistEndpoint)
+		}
+
+		var listWaitlistEndpoint endpoint.Endpoint
+		{
+			listWaitlistEndpoint = grpctransport.NewClient(
+				conn,
+				"pb.DVDRental",
+				"ListWaitlist",
+				encodeListWaitlistRequest,
+				decodeListWaitlistResponse,
+				pb.ListWaitlistResponse{},
+				append(opts, grpctransport.ClientBefore(opentracing.ContextToGRPC(ot, logger)))...,
+			).Endpoint()
+			listWaitlistEndpoint = domainErrors(func(err error) interface{} { return fetchWaitlistResponse{Err: err} })(listWaitlistEndpoint)
+			listWaitlistEndpoint = opentracing.TraceClient(ot, "ListWaitlist")(listWaitlistEndpoint)
+			listWaitlistEndpoint = limiter(listWaitlistEndpoint)
+			listWaitlistEndpoint = guard.Break("ListWaitlist")(listWaitlistEndpoint)
+		}
+
+		var listDVDsEndpoint endpoint.Endpoint
+		{
+			listDVDsEndpoint = grpctransport.NewClient(
+				conn,
+				"pb.DVDRental",
+				"ListDVDs",
+				encodeListDVDsRequest,
+				decodeListDVDsResponse,
+				pb.ListDVDsResponse{},
+				append(opts, grpctransport.ClientBefore(opentracing.ContextToGRPC(ot, logger)))...,
+			).Endpoint()
+			listDVDsEndpoint = domainErrors(func(err error) interface{} { return fetchDVDsResponse{Err: err} })(listDVDsEndpoint)
+			listDVDsEndpoint = opentracing.TraceClient(ot, "ListDVDs")(listDVDsEndpoint)
+			listDVDsEndpoint = limiter(listDVDsEndpoint)
+			listDVDsEndpoint = guard.Break("ListDVDs")(listDVDsEndpoint)
+		}
+		return proxymw{
+			ctx,
+			svc,
+			rentDVDEndpoint,
+			returnDVDEndpoint,
+			releaseDVDEndpoint,
+			sellDVDEndpoint,
+			joinWaitlistEndpoint,
+			leaveWaitlistEndpoint,
+			listWaitlistEndpoint,
+			listDVDsEndpoint,
 		}
-		return proxymw{ctx, svc, rentDVDEndpoint}
 	}
 }
@@ -0,0 +1,56 @@
+package customer
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ngray1747/dvd-rental/internal/model"
+)
+
+// PurchaseState is how far a purchase went
+type PurchaseState string
+
+const (
+	// PurchasePending is recorded before the dvd service is asked to sell the dvd, so a sale is never left unrecorded.
+	// A purchase whose outcome could not be recorded stays pending, and is logged.
+	PurchasePending PurchaseState = "pending"
+	// PurchaseCompleted is a purchase whose dvd was sold at Price
+	PurchaseCompleted PurchaseState = "completed"
+	// PurchaseFailed is a purchase the dvd service turned down
+	PurchaseFailed PurchaseState = "failed"
+)
+
+// Purchase represents a dvd bought by a customer
+type Purchase struct {
+	model.Base
+	CustomerID string `pg:",notnull" json:"customer_id"`
+	DVDID      string `pg:",notnull" json:"dvd_id"`
+	// Price paid, in cents
+	Price       int64         `pg:",use_zero" json:"price"`
+	PurchasedAt time.Time     `pg:",notnull" json:"purchased_at"`
+	State       PurchaseState `pg:",notnull" json:"state"`
+}
+
+// PurchaseRepository represent purchase database business
+type PurchaseRepository interface {
+	Store(p *Purchase) error
+	Update(p *Purchase) error
+}
+
+// NewPurchase init a pending purchase of a dvd by a customer, its price is known once the dvd is sold.
+func NewPurchase(customerID, dvdID string) (*Purchase, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Purchase{
+		Base: model.Base{
+			ID: id.String(),
+		},
+		CustomerID:  customerID,
+		DVDID:       dvdID,
+		PurchasedAt: time.Now(),
+		State:       PurchasePending,
+	}, nil
+}
@@ -0,0 +1,55 @@
+package customer
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ngray1747/dvd-rental/internal/model"
+)
+
+// Rental represents a dvd rented by a customer
+type Rental struct {
+	model.Base
+	CustomerID string    `pg:",notnull" json:"customer_id"`
+	DVDID      string    `pg:",notnull" json:"dvd_id"`
+	RentedAt   time.Time `pg:",notnull" json:"rented_at"`
+	DueAt      time.Time `pg:",notnull" json:"due_at"`
+	ReturnedAt time.Time `json:"returned_at"`
+	// LateFee charged on return, in cents
+	LateFee int64 `pg:",use_zero" json:"late_fee"`
+}
+
+// RentalRepository represent rental database/cache business
+type RentalRepository interface {
+	Store(r *Rental) error
+	//Close records the return of r and charges its late fee to the customer's balance, both or neither
+	Close(r *Rental) error
+	//GetActiveByDVD returns the rental of a dvd which has not been returned yet
+	GetActiveByDVD(dvdID string) (*Rental, error)
+	//ListActiveByCustomer returns all rentals a customer has not returned yet
+	ListActiveByCustomer(customerID string) ([]*Rental, error)
+}
+
+// NewRental init a new rental of a dvd for a customer, due after period.
+func NewRental(customerID, dvdID string, period time.Duration) (*Rental, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &Rental{
+		Base: model.Base{
+			ID: id.String(),
+		},
+		CustomerID: customerID,
+		DVDID:      dvdID,
+		RentedAt:   now,
+		DueAt:      now.Add(period),
+	}, nil
+}
+
+// IsReturned reports whether the rented dvd has been returned
+func (r *Rental) IsReturned() bool {
+	return !r.ReturnedAt.IsZero()
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"github.com/ngray1747/dvd-rental/internal/migrate"
+	"github.com/ngray1747/dvd-rental/internal/outbox"
+)
+
+// Migrations builds the customer database schema. Tables are created only if missing so databases
+// created before migrations existed are adopted as they are.
+var Migrations = []migrate.Migration{
+	{
+		Version: 1,
+		Name:    "create_customers",
+		Up: `CREATE TABLE IF NOT EXISTS customers (
+			id uuid NOT NULL,
+			name varchar(55) NULL,
+			address varchar(255) NULL,
+			created_at timestamptz NULL,
+			updated_at timestamptz NULL,
+			deleted_at timestamptz NULL,
+			CONSTRAINT customers_pkey PRIMARY KEY (id)
+		)`,
+		Down: `DROP TABLE customers`,
+	},
+	{
+		Version: 2,
+		Name:    "create_rentals",
+		Up: `CREATE TABLE IF NOT EXISTS rentals (
+			id uuid NOT NULL,
+			customer_id uuid NOT NULL,
+			dvd_id uuid NOT NULL,
+			rented_at timestamptz NOT NULL,
+			returned_at timestamptz NULL,
+			created_at timestamptz NULL,
+			updated_at timestamptz NULL,
+			deleted_at timestamptz NULL,
+			CONSTRAINT rentals_pkey PRIMARY KEY (id)
+		)`,
+		Down: `DROP TABLE rentals`,
+	},
+	{
+		Version: 3,
+		Name:    "add_late_fees",
+		//* Rentals made before due dates existed are due after the default period
+		Up: `ALTER TABLE customers ADD COLUMN IF NOT EXISTS balance int8 NOT NULL DEFAULT 0;
+			ALTER TABLE rentals ADD COLUMN IF NOT EXISTS due_at timestamptz NULL;
+			ALTER TABLE rentals ADD COLUMN IF NOT EXISTS late_fee int8 NOT NULL DEFAULT 0;
+			UPDATE rentals SET due_at = rented_at + interval '7 days' WHERE due_at IS NULL;
+			ALTER TABLE rentals ALTER COLUMN due_at SET NOT NULL`,
+		Down: `ALTER TABLE rentals DROP COLUMN late_fee;
+			ALTER TABLE rentals DROP COLUMN due_at;
+			ALTER TABLE customers DROP COLUMN balance`,
+	},
+	{
+		Version: 4,
+		Name:    "create_purchases",
+		Up: `CREATE TABLE IF NOT EXISTS purchases (
+			id uuid NOT NULL,
+			customer_id uuid NOT NULL,
+			dvd_id uuid NOT NULL,
+			price int8 NOT NULL DEFAULT 0,
+			purchased_at timestamptz NOT NULL,
+			created_at timestamptz NULL,
+			updated_at timestamptz NULL,
+			deleted_at timestamptz NULL,
+			CONSTRAINT purchases_pkey PRIMARY KEY (id)
+		)`,
+		Down: `DROP TABLE purchases`,
+	},
+	{
+		Version: 5,
+		Name:    "create_rent_sagas",
+		Up: `CREATE TABLE IF NOT EXISTS rent_sagas (
+			id uuid NOT NULL,
+			rental_id uuid NOT NULL,
+			customer_id uuid NOT NULL,
+			dvd_id uuid NOT NULL,
+			rented_at timestamptz NOT NULL,
+			due_at timestamptz NOT NULL,
+			state varchar(20) NOT NULL,
+			error text NULL,
+			created_at timestamptz NULL,
+			updated_at timestamptz NULL,
+			deleted_at timestamptz NULL,
+			CONSTRAINT rent_sagas_pkey PRIMARY KEY (id)
+		);
+		CREATE INDEX IF NOT EXISTS rent_sagas_state_idx ON rent_sagas (state, updated_at)`,
+		Down: `DROP TABLE rent_sagas`,
+	},
+	outbox.Migration(6),
+	{
+		Version: 7,
+		Name:    "add_versions",
+		Up: `ALTER TABLE customers ADD COLUMN IF NOT EXISTS version int4 NOT NULL DEFAULT 1;
+			ALTER TABLE rentals ADD COLUMN IF NOT EXISTS version int4 NOT NULL DEFAULT 1;
+			ALTER TABLE purchases ADD COLUMN IF NOT EXISTS version int4 NOT NULL DEFAULT 1;
+			ALTER TABLE rent_sagas ADD COLUMN IF NOT EXISTS version int4 NOT NULL DEFAULT 1`,
+		Down: `ALTER TABLE rent_sagas DROP COLUMN version;
+			ALTER TABLE purchases DROP COLUMN version;
+			ALTER TABLE rentals DROP COLUMN version;
+			ALTER TABLE customers DROP COLUMN version`,
+	},
+	{
+		Version: 8,
+		Name:    "create_return_sagas",
+		Up: `CREATE TABLE IF NOT EXISTS return_sagas (
+			id uuid NOT NULL,
+			rental_id uuid NOT NULL,
+			customer_id uuid NOT NULL,
+			dvd_id uuid NOT NULL,
+			returned_at timestamptz NULL,
+			late_fee int8 NOT NULL DEFAULT 0,
+			state varchar(20) NOT NULL,
+			error text NULL,
+			created_at timestamptz NULL,
+			updated_at timestamptz NULL,
+			deleted_at timestamptz NULL,
+			version int4 NOT NULL DEFAULT 1,
+			CONSTRAINT return_sagas_pkey PRIMARY KEY (id)
+		);
+		CREATE INDEX IF NOT EXISTS return_sagas_state_idx ON return_sagas (state, updated_at)`,
+		Down: `DROP TABLE return_sagas`,
+	},
+	{
+		Version: 9,
+		Name:    "add_purchase_states",
+		//* Purchases made before states existed were stored once their dvd was sold
+		Up:   `ALTER TABLE purchases ADD COLUMN IF NOT EXISTS state varchar(20) NOT NULL DEFAULT 'completed'`,
+		Down: `ALTER TABLE purchases DROP COLUMN state`,
+	},
+	{
+		Version: 10,
+		Name:    "add_active_rental_index",
+		//* A dvd is rented to one customer at a time, see activeRentalIndex
+		Up:   `CREATE UNIQUE INDEX IF NOT EXISTS rentals_active_dvd_idx ON rentals (dvd_id) WHERE returned_at IS NULL`,
+		Down: `DROP INDEX rentals_active_dvd_idx`,
+	},
+}
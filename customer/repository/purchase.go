@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"github.com/go-pg/pg/v9"
+	"github.com/ngray1747/dvd-rental/customer"
+	"github.com/ngray1747/dvd-rental/internal/model"
+)
+
+type purchaseRepository struct {
+	db *pg.DB
+}
+
+// NewPurchaseRepository create a new purchase repository, purchases are never read back hot so they are not cached.
+func NewPurchaseRepository(db *pg.DB) customer.PurchaseRepository {
+	return &purchaseRepository{db: db}
+}
+
+func (pr *purchaseRepository) Store(p *customer.Purchase) error {
+	return pr.db.Insert(p)
+}
+
+func (pr *purchaseRepository) Update(p *customer.Purchase) error {
+	return model.Update(pr.db, p)
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"github.com/go-pg/pg/v9"
+	"github.com/go-redis/redis/v7"
+	"github.com/ngray1747/dvd-rental/customer"
+	"github.com/ngray1747/dvd-rental/internal/apperr"
+	"github.com/ngray1747/dvd-rental/internal/config"
+	"github.com/ngray1747/dvd-rental/internal/model"
+)
+
+const (
+	// rentalCacheKeySuffix is appended to the service cache key to hold active rentals
+	rentalCacheKeySuffix = ":rentals"
+	// activeRentalIndex keeps a dvd from having two rentals not returned yet
+	activeRentalIndex = "rentals_active_dvd_idx"
+)
+
+var errDVDRented = apperr.New(apperr.Conflict, "dvd already has an active rental")
+
+// RentalCache provides access to active rentals cache, keyed by dvd id
+type RentalCache interface {
+	StoreToCache(key string, value customer.Rental) error
+	GetFromCache(key, field string) (*customer.Rental, error)
+	RemoveFromCache(key, field string) error
+}
+
+type rentalRepository struct {
+	key         string
+	customerKey string
+	db          *pg.DB
+	cache       RentalCache
+	customers   Cache
+}
+
+// NewRentalRepository create a new rental repository, customers is the customer cache, whose balance Close changes.
+func NewRentalRepository(cfg *config.Cache, db *pg.DB, cache RentalCache, customers Cache) customer.RentalRepository {
+	return &rentalRepository{key: cfg.CacheKey + rentalCacheKeySuffix, customerKey: cfg.CacheKey, db: db, cache: cache, customers: customers}
+}
+
+func (rr *rentalRepository) Store(r *customer.Rental) error {
+	tx, err := rr.db.Begin()
+	if err != nil {
+		return err
+	}
+	// Rollback tx on error.
+	defer tx.Close()
+	if err := tx.Insert(r); err != nil {
+		if model.IsUniqueViolation(err, activeRentalIndex) {
+			return errDVDRented
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	//* Cached once committed, a rolled back rental must not be served from cache
+	return rr.cache.StoreToCache(rr.key, *r)
+}
+
+func (rr *rentalRepository) Close(r *customer.Rental) error {
+	tx, err := rr.db.Begin()
+	if err != nil {
+		return err
+	}
+	// Rollback tx on error.
+	defer tx.Close()
+	if err := model.Update(tx, r); err != nil {
+		return err
+	}
+	if r.LateFee > 0 {
+		_, err := tx.Model((*customer.Customer)(nil)).
+			Set("balance = balance + ?", r.LateFee).
+			Set("updated_at = now()").
+			Set("version = version + 1").
+			Where("id = ?", r.CustomerID).
+			Update()
+		if err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if r.LateFee > 0 {
+		//* The cached customer is read again with its new balance
+		if err := rr.customers.RemoveFromCache(rr.customerKey, r.CustomerID); err != nil {
+			return err
+		}
+	}
+	//* Only active rentals are kept in cache
+	return rr.cache.RemoveFromCache(rr.key, r.DVDID)
+}
+
+func (rr *rentalRepository) GetActiveByDVD(dvdID string) (*customer.Rental, error) {
+	//* Get data from cache first
+	r, err := rr.cache.GetFromCache(rr.key, dvdID)
+	if err != nil && err != redis.Nil {
+		return nil, err
+	} else if err == redis.Nil {
+		r = new(customer.Rental)
+		// Get from database
+		if err := rr.db.Model(r).
+			Where("dvd_id = ?", dvdID).
+			Where("returned_at IS NULL").
+			Select(); err != nil {
+			return nil, err
+		}
+		// Set back to cache
+		if err = rr.cache.StoreToCache(rr.key, *r); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func (rr *rentalRepository) ListActiveByCustomer(customerID string) ([]*customer.Rental, error) {
+	var rentals []*customer.Rental
+	if err := rr.db.Model(&rentals).
+		Where("customer_id = ?", customerID).
+		Where("returned_at IS NULL").
+		Order("rented_at ASC").
+		Select(); err != nil {
+		return nil, err
+	}
+	return rentals, nil
+}
@@ -6,9 +6,10 @@ import (
 	"github.com/ngray1747/dvd-rental/customer"
 	"github.com/ngray1747/dvd-rental/internal/config"
 	"github.com/ngray1747/dvd-rental/internal/model"
+	"github.com/ngray1747/dvd-rental/internal/outbox"
 )
 
-//Cache provides access to customer cache
+// Cache provides access to customer cache
 type Cache interface {
 	StoreToCache(key string, value customer.Customer) error
 	GetFromCache(key, field string) (*customer.Customer, error)
@@ -20,11 +21,20 @@ type customerRepository struct {
 	cache Cache
 }
 
-//NewCustomerRepository create a new customer repository.
+// NewCustomerRepository create a new customer repository.
 func NewCustomerRepository(cfg *config.Cache, db *pg.DB, cache Cache) customer.Repository {
 	return &customerRepository{cfg: cfg, db: db, cache: cache}
 }
 
+// commit commits tx then caches c, so the cache never holds a change that was rolled back.
+// A failed cache write is reported even though the change is committed.
+func (cr *customerRepository) commit(tx *pg.Tx, c *customer.Customer) error {
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return cr.cache.StoreToCache(cr.cfg.CacheKey, *c)
+}
+
 func (cr *customerRepository) Store(c *customer.Customer) error {
 	tx, err := cr.db.Begin()
 	if err != nil {
@@ -35,11 +45,11 @@ func (cr *customerRepository) Store(c *customer.Customer) error {
 	if err := tx.Insert(c); err != nil {
 		return err
 	}
-
-	if err := cr.cache.StoreToCache(cr.cfg.CacheKey, *c); err != nil {
+	if err := outbox.Add(tx, outbox.CustomerRegistered, c.ID, c); err != nil {
 		return err
 	}
-	return tx.Commit()
+
+	return cr.commit(tx, c)
 }
 
 func (cr *customerRepository) GetByID(id string) (*customer.Customer, error) {
@@ -73,15 +83,11 @@ func (cr *customerRepository) Update(c *customer.Customer) error {
 	// Rollback tx on error.
 	defer tx.Close()
 
-	if err := tx.Update(c); err != nil {
+	if err := model.Update(tx, c); err != nil {
 		return err
 	}
 
-	if err := cr.cache.StoreToCache(cr.cfg.CacheKey, *c); err != nil {
-		return err
-	}
-
-	return nil
+	return cr.commit(tx, c)
 }
 
 func (cr *customerRepository) Delete(c *customer.Customer) error {
@@ -96,9 +102,36 @@ func (cr *customerRepository) Delete(c *customer.Customer) error {
 		return err
 	}
 
-	if err := cr.cache.RemoveFromCache(cr.cfg.CacheKey, c.ID); err != nil {
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return cr.cache.RemoveFromCache(cr.cfg.CacheKey, c.ID)
+}
+
+func (cr *customerRepository) Restore(c *customer.Customer) error {
+	tx, err := cr.db.Begin()
+	if err != nil {
 		return err
 	}
+	// Rollback tx on error.
+	defer tx.Close()
+
+	res, err := tx.Model(c).
+		Set("name = ?name").
+		Set("address = ?address").
+		Set("deleted_at = NULL").
+		Set("updated_at = now()").
+		Set("version = version + 1").
+		WherePK().
+		Deleted().
+		Returning("*").
+		Update()
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return pg.ErrNoRows
+	}
 
-	return nil
+	return cr.commit(tx, c)
 }
@@ -6,13 +6,16 @@ import (
 	"log"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/go-pg/pg/v9"
 	"github.com/go-redis/redis/v7"
 	"github.com/ngray1747/dvd-rental/customer"
 	"github.com/ngray1747/dvd-rental/customer/cache"
 	"github.com/ngray1747/dvd-rental/customer/repository"
+	"github.com/ngray1747/dvd-rental/internal/apperr"
 	"github.com/ngray1747/dvd-rental/internal/config"
+	"github.com/ngray1747/dvd-rental/internal/migrate"
 	"github.com/ngray1747/dvd-rental/internal/model"
 	"github.com/ory/dockertest"
 	"github.com/stretchr/testify/assert"
@@ -41,19 +44,14 @@ func TestMain(m *testing.M) {
 			panic(err)
 		}
 		db = pg.Connect(pgConnectionString)
-		_, err = db.Exec(`CREATE TABLE public.customers (
-			id uuid NOT NULL,
-			name varchar(55) NULL,
-			address varchar(255) NULL,
-			created_at timestamptz NULL,
-			updated_at timestamptz NULL,
-			deleted_at timestamptz NULL,
-			CONSTRAINT customers_pkey PRIMARY KEY (id)
-		)`)
+		_, err = db.Exec("SELECT 1")
 		return err
 	}); err != nil {
 		log.Fatalf("Could not connect to docker: %s", err)
 	}
+	if err := migrateDB(db); err != nil {
+		log.Fatalf("Could not migrate database: %s", err)
+	}
 
 	cacheResource, err := pool.Run("bitnami/redis", "latest", []string{"ALLOW_EMPTY_PASSWORD=yes"})
 	if err != nil {
@@ -85,6 +83,24 @@ func TestMain(m *testing.M) {
 	os.Exit(code)
 }
 
+// migrateDB applies the migrations, rolls them all back and applies them again so both ways are exercised
+func migrateDB(db *pg.DB) error {
+	migrator, err := migrate.New(db, repository.Migrations)
+	if err != nil {
+		return err
+	}
+	if _, err := migrator.Up(); err != nil {
+		return err
+	}
+	for range repository.Migrations {
+		if _, err := migrator.Down(); err != nil {
+			return err
+		}
+	}
+	_, err = migrator.Up()
+	return err
+}
+
 func TestStore(t *testing.T) {
 	cacheCli := cache.NewCacheClient(cacheClient)
 	repo := repository.NewCustomerRepository(cacheConfig, db, cacheCli)
@@ -101,7 +117,7 @@ func TestStore(t *testing.T) {
 			args: args{
 				customer: &customer.Customer{
 					Base: model.Base{
-						ID:      "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+						ID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
 					},
 					Name:    "Duy Nguyen",
 					Address: "1102 Truong Sa Street",
@@ -117,3 +133,123 @@ func TestStore(t *testing.T) {
 		})
 	}
 }
+
+func TestRestore(t *testing.T) {
+	repo := repository.NewCustomerRepository(cacheConfig, db, cache.NewCacheClient(cacheClient))
+	c, err := customer.NewCustomer("Gone", "1 Deleted Street")
+	assert.NoError(t, err)
+	assert.Equal(t, pg.ErrNoRows, repo.Restore(c), "only a deleted customer is restored")
+	assert.NoError(t, repo.Store(c))
+	assert.NoError(t, repo.Delete(c))
+	_, err = repo.GetByID(c.ID)
+	assert.Equal(t, pg.ErrNoRows, err)
+
+	c.Address = "2 Restored Street"
+	assert.NoError(t, repo.Restore(c))
+	got, err := repo.GetByID(c.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "2 Restored Street", got.Address)
+}
+
+func TestRentalStoreAndReturn(t *testing.T) {
+	customers := repository.NewCustomerRepository(cacheConfig, db, cache.NewCacheClient(cacheClient))
+	repo := repository.NewRentalRepository(cacheConfig, db, cache.NewRentalCacheClient(cacheClient), cache.NewCacheClient(cacheClient))
+	c, err := customer.NewCustomer("Late Returner", "1 Due Date Street")
+	assert.NoError(t, err)
+	assert.NoError(t, customers.Store(c))
+	rental := &customer.Rental{
+		Base: model.Base{
+			ID: "7b0c6d1e-5f0a-4a53-9d1f-0e7a2b9a6a11",
+		},
+		CustomerID: c.ID,
+		DVDID:      "5e8b83c9-36f3-4084-94b5-33153246d534",
+		RentedAt:   time.Now(),
+		DueAt:      time.Now().Add(24 * time.Hour),
+	}
+	assert.NoError(t, repo.Store(rental))
+	again := &customer.Rental{
+		Base:       model.Base{ID: "0d3c5a8e-2b7f-4e61-a9c4-6f1e8b2d7c35"},
+		CustomerID: c.ID,
+		DVDID:      rental.DVDID,
+		RentedAt:   time.Now(),
+		DueAt:      time.Now().Add(24 * time.Hour),
+	}
+	err = repo.Store(again)
+	assert.Equal(t, apperr.Conflict, apperr.CodeOf(err), "a dvd has one active rental at a time")
+
+	active, err := repo.GetActiveByDVD(rental.DVDID)
+	assert.NoError(t, err)
+	assert.Equal(t, rental.CustomerID, active.CustomerID)
+
+	out, err := repo.ListActiveByCustomer(rental.CustomerID)
+	assert.NoError(t, err)
+	assert.Len(t, out, 1)
+
+	//* Cache the customer so the charge is seen to invalidate it
+	_, err = customers.GetByID(c.ID)
+	assert.NoError(t, err)
+	rental.ReturnedAt = time.Now()
+	rental.LateFee = 300
+	assert.NoError(t, repo.Close(rental))
+
+	_, err = repo.GetActiveByDVD(rental.DVDID)
+	assert.Equal(t, pg.ErrNoRows, err)
+	assert.NoError(t, repo.Store(again), "a returned dvd can be rented again")
+	charged, err := customers.GetByID(c.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(300), charged.Balance)
+}
+
+func TestPurchaseStore(t *testing.T) {
+	repo := repository.NewPurchaseRepository(db)
+	p, err := customer.NewPurchase("18eb0b6e-8757-4dfb-b062-1c7944e2b8f7", "5e8b83c9-36f3-4084-94b5-33153246d534")
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Store(p))
+	assert.Error(t, repo.Store(p), "a purchase is recorded once")
+
+	p.Price = 1500
+	p.State = customer.PurchaseCompleted
+	assert.NoError(t, repo.Update(p))
+}
+
+func TestSagaListPending(t *testing.T) {
+	repo := repository.NewSagaRepository(db)
+	rental, err := customer.NewRental("18eb0b6e-8757-4dfb-b062-1c7944e2b8f7", "9a4d2f61-0c3b-4f7e-8e5a-2b1c6d7e8f90", 24*time.Hour)
+	assert.NoError(t, err)
+	pending, err := customer.NewRentSaga(rental)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Store(pending))
+	done, err := customer.NewRentSaga(rental)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Store(done))
+	done.State = customer.SagaCompleted
+	assert.NoError(t, repo.Update(done))
+
+	out, err := repo.ListPending(time.Now())
+	assert.NoError(t, err)
+	assert.Len(t, out, 1)
+	assert.Equal(t, pending.ID, out[0].ID)
+
+	out, err = repo.ListPending(time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Empty(t, out)
+}
+
+func TestReturnSagaListPending(t *testing.T) {
+	repo := repository.NewReturnSagaRepository(db)
+	rental, err := customer.NewRental("18eb0b6e-8757-4dfb-b062-1c7944e2b8f7", "9a4d2f61-0c3b-4f7e-8e5a-2b1c6d7e8f90", 24*time.Hour)
+	assert.NoError(t, err)
+	pending, err := customer.NewReturnSaga(rental)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Store(pending))
+	done, err := customer.NewReturnSaga(rental)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Store(done))
+	done.State = customer.SagaCompleted
+	assert.NoError(t, repo.Update(done))
+
+	out, err := repo.ListPending(time.Now())
+	assert.NoError(t, err)
+	assert.Len(t, out, 1)
+	assert.Equal(t, pending.ID, out[0].ID)
+}
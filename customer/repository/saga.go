@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/ngray1747/dvd-rental/customer"
+	"github.com/ngray1747/dvd-rental/internal/model"
+)
+
+type sagaRepository struct {
+	db *pg.DB
+}
+
+// NewSagaRepository create a new rent saga repository, sagas must survive a crash so they are never cached.
+func NewSagaRepository(db *pg.DB) customer.SagaRepository {
+	return &sagaRepository{db: db}
+}
+
+func (sr *sagaRepository) Store(s *customer.RentSaga) error {
+	return sr.db.Insert(s)
+}
+
+func (sr *sagaRepository) Update(s *customer.RentSaga) error {
+	return model.Update(sr.db, s)
+}
+
+func (sr *sagaRepository) ListPending(before time.Time) ([]*customer.RentSaga, error) {
+	var sagas []*customer.RentSaga
+	if err := sr.db.Model(&sagas).
+		WhereIn("state IN (?)", []customer.SagaState{customer.SagaStarted, customer.SagaDVDRented, customer.SagaCompensating}).
+		Where("updated_at < ?", before).
+		Order("created_at ASC").
+		Select(); err != nil {
+		return nil, err
+	}
+	return sagas, nil
+}
+
+type returnSagaRepository struct {
+	db *pg.DB
+}
+
+// NewReturnSagaRepository create a new return saga repository, never cached for the same reason as rent sagas.
+func NewReturnSagaRepository(db *pg.DB) customer.ReturnSagaRepository {
+	return &returnSagaRepository{db: db}
+}
+
+func (sr *returnSagaRepository) Store(s *customer.ReturnSaga) error {
+	return sr.db.Insert(s)
+}
+
+func (sr *returnSagaRepository) Update(s *customer.ReturnSaga) error {
+	return model.Update(sr.db, s)
+}
+
+func (sr *returnSagaRepository) ListPending(before time.Time) ([]*customer.ReturnSaga, error) {
+	var sagas []*customer.ReturnSaga
+	if err := sr.db.Model(&sagas).
+		WhereIn("state IN (?)", []customer.SagaState{customer.SagaStarted, customer.SagaDVDReturned}).
+		Where("updated_at < ?", before).
+		Order("created_at ASC").
+		Select(); err != nil {
+		return nil, err
+	}
+	return sagas, nil
+}
@@ -0,0 +1,392 @@
+package customer
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/google/uuid"
+	"github.com/ngray1747/dvd-rental/internal/apperr"
+	"github.com/ngray1747/dvd-rental/internal/config"
+	"github.com/ngray1747/dvd-rental/internal/model"
+)
+
+const defaultSagaRecoveryInterval = time.Minute
+
+// SagaState is the last step a rent saga went through
+type SagaState string
+
+const (
+	// SagaStarted is recorded before the dvd service is asked to rent the dvd
+	SagaStarted SagaState = "started"
+	// SagaDVDRented is recorded once the dvd service rented the dvd to the customer
+	SagaDVDRented SagaState = "dvd_rented"
+	// SagaCompleted ends a saga whose rental is stored
+	SagaCompleted SagaState = "completed"
+	// SagaCompensating is recorded when a rent failed after the dvd may have been rented, until it is released
+	SagaCompensating SagaState = "compensating"
+	// SagaCompensated ends a saga whose dvd was never rented or has been released
+	SagaCompensated SagaState = "compensated"
+	// SagaDVDReturned is recorded once the dvd service took a returned dvd back, until its rental is closed
+	SagaDVDReturned SagaState = "dvd_returned"
+)
+
+// RentSaga records the steps of a rent, which spans the dvd and the customer services,
+// so a rent interrupted halfway is undone instead of leaving the dvd rented to nobody
+type RentSaga struct {
+	model.Base
+	// RentalID, CustomerID, DVDID, RentedAt and DueAt describe the rental being stored
+	RentalID   string    `pg:",notnull" json:"rental_id"`
+	CustomerID string    `pg:",notnull" json:"customer_id"`
+	DVDID      string    `pg:",notnull" json:"dvd_id"`
+	RentedAt   time.Time `pg:",notnull" json:"rented_at"`
+	DueAt      time.Time `pg:",notnull" json:"due_at"`
+	State      SagaState `pg:",notnull" json:"state"`
+	// Error is why the saga was compensated
+	Error string `json:"error,omitempty"`
+}
+
+// SagaRepository represent rent saga database business
+type SagaRepository interface {
+	Store(s *RentSaga) error
+	Update(s *RentSaga) error
+	//ListPending returns the sagas neither completed nor compensated which were last updated before before
+	ListPending(before time.Time) ([]*RentSaga, error)
+}
+
+// ReturnSaga records the steps of a return, so a rental whose dvd is back on the shelf is closed
+// and its late fee charged even when the return is interrupted once the dvd service took the dvd back
+type ReturnSaga struct {
+	model.Base
+	RentalID   string `pg:",notnull" json:"rental_id"`
+	CustomerID string `pg:",notnull" json:"customer_id"`
+	DVDID      string `pg:",notnull" json:"dvd_id"`
+	// ReturnedAt and LateFee are known once the dvd service took the dvd back
+	ReturnedAt time.Time `json:"returned_at"`
+	LateFee    int64     `pg:",use_zero" json:"late_fee"`
+	State      SagaState `pg:",notnull" json:"state"`
+	// Error is why the saga was compensated
+	Error string `json:"error,omitempty"`
+}
+
+// ReturnSagaRepository represent return saga database business
+type ReturnSagaRepository interface {
+	Store(s *ReturnSaga) error
+	Update(s *ReturnSaga) error
+	//ListPending returns the sagas neither completed nor compensated which were last updated before before
+	ListPending(before time.Time) ([]*ReturnSaga, error)
+}
+
+// NewRentSaga init the saga storing rental r
+func NewRentSaga(r *Rental) (*RentSaga, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+
+	return &RentSaga{
+		Base: model.Base{
+			ID: id.String(),
+		},
+		RentalID:   r.ID,
+		CustomerID: r.CustomerID,
+		DVDID:      r.DVDID,
+		RentedAt:   r.RentedAt,
+		DueAt:      r.DueAt,
+		State:      SagaStarted,
+	}, nil
+}
+
+// Rental returns the rental the saga stores
+func (s *RentSaga) Rental() *Rental {
+	return &Rental{
+		Base: model.Base{
+			ID: s.RentalID,
+		},
+		CustomerID: s.CustomerID,
+		DVDID:      s.DVDID,
+		RentedAt:   s.RentedAt,
+		DueAt:      s.DueAt,
+	}
+}
+
+// NewReturnSaga init the saga returning the dvd of rental r
+func NewReturnSaga(r *Rental) (*ReturnSaga, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReturnSaga{
+		Base: model.Base{
+			ID: id.String(),
+		},
+		RentalID:   r.ID,
+		CustomerID: r.CustomerID,
+		DVDID:      r.DVDID,
+		State:      SagaStarted,
+	}, nil
+}
+
+// SagaCoordinator runs rent and return sagas and recovers the ones a crash or a failed compensation left pending
+type SagaCoordinator struct {
+	sagas   SagaRepository
+	returns ReturnSagaRepository
+	rentals RentalRepository
+	dvdSvc  ProxyService
+	fees    FeePolicy
+	logger  log.Logger
+	// RecoveryInterval is how often pending sagas are looked for, and how long a saga is left alone before it counts as pending
+	RecoveryInterval time.Duration
+}
+
+// NewSagaCoordinator builds a coordinator from the service's rental config, falling back to defaults for unset values
+func NewSagaCoordinator(sagas SagaRepository, returns ReturnSagaRepository, rentals RentalRepository, dvdSvc ProxyService, cfg *config.Rental, logger log.Logger) *SagaCoordinator {
+	sc := &SagaCoordinator{
+		sagas:   sagas,
+		returns: returns,
+		rentals: rentals,
+		dvdSvc:  dvdSvc,
+		fees:    NewFeePolicy(cfg),
+		logger:  logger,
+
+		RecoveryInterval: defaultSagaRecoveryInterval,
+	}
+	if cfg != nil && cfg.SagaRecoveryInterval > 0 {
+		sc.RecoveryInterval = time.Duration(cfg.SagaRecoveryInterval) * time.Second
+	}
+	return sc
+}
+
+// Rent rents the dvd of r from the dvd service then stores r.
+// When a step fails after the dvd may have been rented, the dvd is released before the error is returned.
+func (sc *SagaCoordinator) Rent(ctx context.Context, r *Rental) error {
+	s, err := NewRentSaga(r)
+	if err != nil {
+		return err
+	}
+	if err := sc.sagas.Store(s); err != nil {
+		return err
+	}
+
+	if err := sc.dvdSvc.UpdateDVDStatus(ctx, r.CustomerID, r.DVDID); err != nil {
+		//* The dvd service turned the rent down, there is nothing to undo
+		if apperr.IsDomain(err) {
+			sc.finish(s, SagaCompensated, err)
+			return err
+		}
+		sc.compensate(ctx, s, err)
+		return err
+	}
+
+	s.State = SagaDVDRented
+	if err := sc.sagas.Update(s); err != nil {
+		sc.compensate(ctx, s, err)
+		return err
+	}
+	if err := sc.rentals.Store(r); err != nil {
+		sc.compensate(ctx, s, err)
+		return err
+	}
+	//* The rental is stored, recovery completes the saga if this fails
+	sc.finish(s, SagaCompleted, nil)
+	return nil
+}
+
+// Return returns the dvd of r to the dvd service then closes r, charging its late fee.
+// Once the dvd service took the dvd back, a failure to close r is left to recovery, which closes it later.
+func (sc *SagaCoordinator) Return(ctx context.Context, r *Rental) error {
+	s, err := NewReturnSaga(r)
+	if err != nil {
+		return err
+	}
+	if err := sc.returns.Store(s); err != nil {
+		return err
+	}
+
+	dvd, err := sc.dvdSvc.ReturnDVD(ctx, r.CustomerID, r.DVDID)
+	if err != nil {
+		//* The dvd service turned the return down, there is nothing to close.
+		//* Otherwise the dvd may have been taken back and recovery finds out.
+		if apperr.IsDomain(err) {
+			sc.finishReturn(s, SagaCompensated, err)
+		}
+		return err
+	}
+
+	s.ReturnedAt = time.Now()
+	s.LateFee = sc.fees.LateFee(r, s.ReturnedAt, dvd.ReplacementCost)
+	s.State = SagaDVDReturned
+	if err := sc.returns.Update(s); err != nil {
+		//* Closing the rental right away still settles the saga, recovery only needs the saga when closing fails too
+		sc.logger.Log("method", "Return", "saga_id", s.ID, "error", err)
+	}
+	if err := sc.close(r, s); err != nil {
+		return err
+	}
+	sc.finishReturn(s, SagaCompleted, nil)
+	return nil
+}
+
+// Resume settles the sagas left pending before before and returns how many it settled.
+// A rent saga whose rental was stored is completed, any other is compensated.
+// A return saga is completed once its rental is closed.
+func (sc *SagaCoordinator) Resume(ctx context.Context, before time.Time) (int, error) {
+	n, err := sc.resumeRents(ctx, before)
+	if err != nil {
+		return n, err
+	}
+	m, err := sc.resumeReturns(ctx, before)
+	return n + m, err
+}
+
+func (sc *SagaCoordinator) resumeRents(ctx context.Context, before time.Time) (int, error) {
+	sagas, err := sc.sagas.ListPending(before)
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, s := range sagas {
+		rental, err := sc.rentals.GetActiveByDVD(s.DVDID)
+		switch {
+		case err == nil && rental.ID == s.RentalID:
+			sc.finish(s, SagaCompleted, nil)
+		case err == nil && rental.CustomerID == s.CustomerID:
+			//* The dvd is rented to the customer by a later saga, releasing it would undo that one
+			sc.finish(s, SagaCompensated, nil)
+		case err == nil || apperr.CodeOf(err) == apperr.NotFound:
+			if !sc.compensate(ctx, s, nil) {
+				continue
+			}
+		default:
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+func (sc *SagaCoordinator) resumeReturns(ctx context.Context, before time.Time) (int, error) {
+	sagas, err := sc.returns.ListPending(before)
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, s := range sagas {
+		rental, err := sc.rentals.GetActiveByDVD(s.DVDID)
+		switch {
+		case err == nil && rental.ID == s.RentalID:
+			if !sc.resumeReturn(ctx, rental, s) {
+				continue
+			}
+		case err == nil || apperr.CodeOf(err) == apperr.NotFound:
+			//* The rental is closed already
+			sc.finishReturn(s, SagaCompleted, nil)
+		default:
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// resumeReturn closes the still active rental of an interrupted return saga and reports whether it did.
+// A saga interrupted before the dvd service answered returns the dvd of its customer again. The dvd service
+// turning the return down, because the dvd is not rented or is rented by someone else since, then means
+// the first attempt took the dvd back, a later rental of the dvd is never returned.
+func (sc *SagaCoordinator) resumeReturn(ctx context.Context, r *Rental, s *ReturnSaga) bool {
+	if s.State == SagaStarted {
+		var replacementCost int64
+		dvd, err := sc.dvdSvc.ReturnDVD(ctx, s.CustomerID, s.DVDID)
+		switch {
+		case err == nil:
+			replacementCost = dvd.ReplacementCost
+		case apperr.IsDomain(err):
+			//* Already returned, the replacement cost is unknown and the late fee is not capped
+		default:
+			sc.logger.Log("method", "resumeReturn", "saga_id", s.ID, "dvd_id", s.DVDID, "error", err)
+			return false
+		}
+		//* The return was asked for when the saga started
+		s.ReturnedAt = s.CreatedAt
+		s.LateFee = sc.fees.LateFee(r, s.ReturnedAt, replacementCost)
+	}
+
+	if err := sc.close(r, s); err != nil {
+		sc.logger.Log("method", "resumeReturn", "saga_id", s.ID, "rental_id", s.RentalID, "error", err)
+		return false
+	}
+	sc.finishReturn(s, SagaCompleted, nil)
+	return true
+}
+
+// close records the return of the saga on rental r and charges its late fee
+func (sc *SagaCoordinator) close(r *Rental, s *ReturnSaga) error {
+	r.ReturnedAt = s.ReturnedAt
+	r.LateFee = s.LateFee
+	return sc.rentals.Close(r)
+}
+
+// RunRecovery resumes the sagas stuck for a RecoveryInterval right away, then every RecoveryInterval until ctx is done.
+// Younger sagas may still be running, on this replica or another one, and are left alone.
+func (sc *SagaCoordinator) RunRecovery(ctx context.Context) {
+	if _, err := sc.Resume(ctx, time.Now().Add(-sc.RecoveryInterval)); err != nil {
+		sc.logger.Log("method", "RunRecovery", "error", err)
+	}
+
+	ticker := time.NewTicker(sc.RecoveryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := sc.Resume(ctx, time.Now().Add(-sc.RecoveryInterval)); err != nil {
+				sc.logger.Log("method", "RunRecovery", "error", err)
+			}
+		}
+	}
+}
+
+// compensate releases the dvd of a failed saga and reports whether it did.
+// A saga failing to release stays compensating for recovery to retry.
+func (sc *SagaCoordinator) compensate(ctx context.Context, s *RentSaga, cause error) bool {
+	s.State = SagaCompensating
+	if cause != nil {
+		s.Error = cause.Error()
+	}
+	if err := sc.sagas.Update(s); err != nil {
+		sc.logger.Log("method", "compensate", "saga_id", s.ID, "error", err)
+	}
+
+	if err := sc.dvdSvc.ReleaseDVD(ctx, s.CustomerID, s.DVDID); err != nil {
+		sc.logger.Log("method", "compensate", "saga_id", s.ID, "dvd_id", s.DVDID, "error", err)
+		return false
+	}
+	sc.finish(s, SagaCompensated, nil)
+	return true
+}
+
+// finish records the final state of a saga, logging failures as recovery settles the saga again
+func (sc *SagaCoordinator) finish(s *RentSaga, state SagaState, cause error) {
+	s.State = state
+	if cause != nil {
+		s.Error = cause.Error()
+	}
+	if err := sc.sagas.Update(s); err != nil {
+		sc.logger.Log("method", "finish", "saga_id", s.ID, "state", state, "error", err)
+	}
+}
+
+// finishReturn records the final state of a return saga, logging failures as recovery settles the saga again
+func (sc *SagaCoordinator) finishReturn(s *ReturnSaga, state SagaState, cause error) {
+	s.State = state
+	if cause != nil {
+		s.Error = cause.Error()
+	}
+	if err := sc.returns.Update(s); err != nil {
+		sc.logger.Log("method", "finishReturn", "saga_id", s.ID, "state", state, "error", err)
+	}
+}
@@ -2,80 +2,245 @@ package customer
 
 import (
 	"context"
-	"errors"
 
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/metrics"
+	"github.com/ngray1747/dvd-rental/internal/apperr"
 )
 
 var (
-	errInvalidArgument = errors.New("invalid argument(s)")
+	errInvalidArgument = apperr.New(apperr.InvalidArgument, "invalid argument(s)")
+	errRentalNotFound  = apperr.New(apperr.NotFound, "rental not found")
+	errHasActiveRental = apperr.New(apperr.Conflict, "customer has not returned all rented dvds")
 )
 
-//Service describe customer business
+// Service describe customer business
 type Service interface {
 	//Register customer
-	Register(ctx context.Context, name, address string) error
-	// Customer rent a dvd
-	Rent(ctx context.Context, customerID, dvdID string) error
-	//Customer buys a dvd
-	// Buy(ctx context.Context, id int) error
-	//Customer returns borrowed dvd
-	// Return(ctx context.Context, id int) error
+	Register(ctx context.Context, name, address string) (*Customer, error)
+	//Get a customer by id
+	Get(ctx context.Context, id string) (*Customer, error)
+	//Update customer's name and address
+	Update(ctx context.Context, id, name, address string) (*Customer, error)
+	//Delete a customer
+	Delete(ctx context.Context, id string) error
+	// Customer rent a dvd, due after the rental period
+	Rent(ctx context.Context, customerID, dvdID string) (*Rental, error)
+	//Customer buys a dvd at its current price
+	Buy(ctx context.Context, customerID, dvdID string) (*Purchase, error)
+	//Customer returns borrowed dvd, a late return is charged to the customer's balance
+	Return(ctx context.Context, customerID, dvdID string) (*Rental, error)
+	//Balance returns what a customer owes in late fees, in cents
+	Balance(ctx context.Context, id string) (int64, error)
+	//JoinWaitlist puts a customer in line for the next returned copy of a title
+	JoinWaitlist(ctx context.Context, customerID, titleID string) (*Reservation, error)
+	//LeaveWaitlist takes a customer out of line, giving up any copy held for them
+	LeaveWaitlist(ctx context.Context, customerID, titleID string) error
+	//ListWaitlist returns who is waiting for a title, first in line first
+	ListWaitlist(ctx context.Context, titleID string) ([]Reservation, error)
+	//Browse the dvd catalog, optionally by name substring and status
+	ListDVDs(ctx context.Context, name, status, cursor string, limit int) (*DVDPage, error)
 }
 
-//NewService return customerService with all expected function
-func NewService(customerRepo Repository, logger log.Logger, counter metrics.Counter, histogram metrics.Histogram, dvdSvc ProxyService) Service {
+// NewService return customerService with all expected function
+func NewService(customerRepo Repository, rentalRepo RentalRepository, purchaseRepo PurchaseRepository, sagas *SagaCoordinator, fees FeePolicy, logger log.Logger, counter metrics.Counter, histogram metrics.Histogram, dvdSvc ProxyService) Service {
 	var svc Service
 	{
-		svc = NewCustomerService(customerRepo, dvdSvc)
+		svc = NewCustomerService(customerRepo, rentalRepo, purchaseRepo, sagas, fees, dvdSvc, logger)
 		svc = NewLoggingService(logger)(svc)
 		svc = NewInstrumentService(counter, histogram)(svc)
 	}
 	return svc
 }
 
-//customerService implement Service interface
+// customerService implement Service interface
 type customerService struct {
-	repo   Repository
-	dvdSvc ProxyService
+	repo         Repository
+	rentalRepo   RentalRepository
+	purchaseRepo PurchaseRepository
+	sagas        *SagaCoordinator
+	fees         FeePolicy
+	dvdSvc       ProxyService
+	logger       log.Logger
 }
 
-//NewCustomerService init customer's service interface
-func NewCustomerService(customerRepo Repository, dvdSvc ProxyService) Service {
-	return &customerService{customerRepo, dvdSvc}
+// NewCustomerService init customer's service interface, logging to logger the purchases it fails to settle
+func NewCustomerService(customerRepo Repository, rentalRepo RentalRepository, purchaseRepo PurchaseRepository, sagas *SagaCoordinator, fees FeePolicy, dvdSvc ProxyService, logger log.Logger) Service {
+	return &customerService{customerRepo, rentalRepo, purchaseRepo, sagas, fees, dvdSvc, logger}
 }
 
-func (c *customerService) Register(ctx context.Context, name, address string) error {
+func (c *customerService) Register(ctx context.Context, name, address string) (*Customer, error) {
 	if name == "" || address == "" {
-		return errInvalidArgument
+		return nil, errInvalidArgument
 	}
 	customer, err := NewCustomer(name, address)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if err := c.repo.Store(customer); err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return customer, nil
+}
 
+func (c *customerService) Get(ctx context.Context, id string) (*Customer, error) {
+	if id == "" {
+		return nil, errInvalidArgument
+	}
+	return c.repo.GetByID(id)
 }
 
-func (c *customerService) Rent(ctx context.Context, customerID, id string) error {
-	if err := c.dvdSvc.UpdateDVDStatus(ctx, id); err != nil {
+func (c *customerService) Update(ctx context.Context, id, name, address string) (*Customer, error) {
+	if id == "" || name == "" || address == "" {
+		return nil, errInvalidArgument
+	}
+	customer, err := c.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	customer.Name = name
+	customer.Address = address
+	if err := c.repo.Update(customer); err != nil {
+		return nil, err
+	}
+	return customer, nil
+}
+
+func (c *customerService) Delete(ctx context.Context, id string) error {
+	if id == "" {
+		return errInvalidArgument
+	}
+	customer, err := c.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	rentals, err := c.rentalRepo.ListActiveByCustomer(id)
+	if err != nil {
 		return err
 	}
-	//! Need creating record for customer-dvd
-	return nil
+	if len(rentals) > 0 {
+		return errHasActiveRental
+	}
+
+	return c.repo.Delete(customer)
 }
 
-// //TODO: Need implement
-// func (c *customerService) Buy(ctx context.Context, id int) error {
-// 	return nil
-// }
+func (c *customerService) Rent(ctx context.Context, customerID, id string) (*Rental, error) {
+	if customerID == "" || id == "" {
+		return nil, errInvalidArgument
+	}
+	if _, err := c.repo.GetByID(customerID); err != nil {
+		return nil, err
+	}
+	rental, err := NewRental(customerID, id, c.fees.Period)
+	if err != nil {
+		return nil, err
+	}
 
-// //TODO: Need implement
-// func (c *customerService) Return(ctx context.Context, id int) error {
-// 	return nil
-// }
+	if err := c.sagas.Rent(ctx, rental); err != nil {
+		return nil, err
+	}
+	return rental, nil
+}
+
+func (c *customerService) JoinWaitlist(ctx context.Context, customerID, titleID string) (*Reservation, error) {
+	if customerID == "" || titleID == "" {
+		return nil, errInvalidArgument
+	}
+	if _, err := c.repo.GetByID(customerID); err != nil {
+		return nil, err
+	}
+	return c.dvdSvc.JoinWaitlist(ctx, customerID, titleID)
+}
+
+func (c *customerService) LeaveWaitlist(ctx context.Context, customerID, titleID string) error {
+	if customerID == "" || titleID == "" {
+		return errInvalidArgument
+	}
+	return c.dvdSvc.LeaveWaitlist(ctx, customerID, titleID)
+}
+
+func (c *customerService) ListWaitlist(ctx context.Context, titleID string) ([]Reservation, error) {
+	if titleID == "" {
+		return nil, errInvalidArgument
+	}
+	return c.dvdSvc.ListWaitlist(ctx, titleID)
+}
+
+func (c *customerService) ListDVDs(ctx context.Context, name, status, cursor string, limit int) (*DVDPage, error) {
+	if limit < 0 {
+		return nil, errInvalidArgument
+	}
+	return c.dvdSvc.ListDVDs(ctx, name, status, cursor, limit)
+}
+
+func (c *customerService) Buy(ctx context.Context, customerID, id string) (*Purchase, error) {
+	if customerID == "" || id == "" {
+		return nil, errInvalidArgument
+	}
+	if _, err := c.repo.GetByID(customerID); err != nil {
+		return nil, err
+	}
+
+	//* The purchase is recorded before the dvd is sold, a sold dvd can not be put back on sale
+	purchase, err := NewPurchase(customerID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.purchaseRepo.Store(purchase); err != nil {
+		return nil, err
+	}
+
+	dvd, err := c.dvdSvc.SellDVD(ctx, id)
+	if err != nil {
+		//* A purchase whose sale may have happened stays pending, one the dvd service turned down is failed
+		if apperr.IsDomain(err) {
+			purchase.State = PurchaseFailed
+			c.settle(purchase)
+		}
+		return nil, err
+	}
+	//* The dvd is sold whether or not the purchase says so, the customer gets it either way
+	purchase.Price = dvd.Price
+	purchase.State = PurchaseCompleted
+	c.settle(purchase)
+	return purchase, nil
+}
+
+// settle records the outcome of a purchase, a purchase it fails to update stays pending and is logged to be settled by hand
+func (c *customerService) settle(p *Purchase) {
+	if err := c.purchaseRepo.Update(p); err != nil {
+		c.logger.Log("method", "Buy", "purchase_id", p.ID, "dvd_id", p.DVDID, "state", p.State, "price", p.Price, "error", err)
+	}
+}
+
+func (c *customerService) Return(ctx context.Context, customerID, id string) (*Rental, error) {
+	if customerID == "" || id == "" {
+		return nil, errInvalidArgument
+	}
+	rental, err := c.rentalRepo.GetActiveByDVD(id)
+	if err != nil {
+		return nil, err
+	}
+	if rental.CustomerID != customerID {
+		return nil, errRentalNotFound
+	}
+
+	if err := c.sagas.Return(ctx, rental); err != nil {
+		return nil, err
+	}
+	return rental, nil
+}
+
+func (c *customerService) Balance(ctx context.Context, id string) (int64, error) {
+	if id == "" {
+		return 0, errInvalidArgument
+	}
+	customer, err := c.repo.GetByID(id)
+	if err != nil {
+		return 0, err
+	}
+	return customer.Balance, nil
+}
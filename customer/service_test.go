@@ -4,11 +4,17 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/metrics/discard"
+	"github.com/go-pg/pg/v9"
 	"github.com/ngray1747/dvd-rental/customer"
 	"github.com/ngray1747/dvd-rental/customer/mocks"
+	"github.com/ngray1747/dvd-rental/dvd"
+	dvdMocks "github.com/ngray1747/dvd-rental/dvd/mocks"
+	"github.com/ngray1747/dvd-rental/internal/apperr"
+	"github.com/ngray1747/dvd-rental/internal/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -17,7 +23,10 @@ func TestRegister(t *testing.T) {
 	assert := assert.New(t)
 	ctx := context.Background()
 	repo := new(mocks.Repository)
-	svc := customer.NewService(repo, log.NewNopLogger(), discard.NewCounter(), discard.NewHistogram())
+	rentalRepo := new(mocks.RentalRepository)
+	purchaseRepo := new(mocks.PurchaseRepository)
+	dvdSvc := new(mocks.ProxyService)
+	svc := customer.NewService(repo, rentalRepo, purchaseRepo, nil, customer.NewFeePolicy(nil), log.NewNopLogger(), discard.NewCounter(), discard.NewHistogram(), dvdSvc)
 	type args struct {
 		name    string
 		address string
@@ -45,7 +54,7 @@ func TestRegister(t *testing.T) {
 				address: "1102 Truong Sa Street",
 			},
 			wantErr: true,
-			mock: func() {},
+			mock:    func() {},
 		},
 		{
 			name: "missing address",
@@ -53,7 +62,7 @@ func TestRegister(t *testing.T) {
 				name: "Duynguyen",
 			},
 			wantErr: true,
-			mock: func() {},
+			mock:    func() {},
 		},
 		{
 			name: "store failed",
@@ -70,8 +79,901 @@ func TestRegister(t *testing.T) {
 	for _, v := range cases {
 		t.Run(v.name, func(t *testing.T) {
 			v.mock()
-			err := svc.Register(ctx, v.args.name, v.args.address)
+			c, err := svc.Register(ctx, v.args.name, v.args.address)
 			assert.Equalf(v.wantErr, err != nil, "name: %v , wantErr %v, got %v , err ", v.name, v.wantErr, err != nil, err)
+			if !v.wantErr {
+				assert.NotEmpty(c.ID)
+				assert.Equal(v.args.name, c.Name)
+			}
 		})
 	}
 }
+
+func TestRent(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	repo := new(mocks.Repository)
+	rentalRepo := new(mocks.RentalRepository)
+	purchaseRepo := new(mocks.PurchaseRepository)
+	dvdSvc := new(mocks.ProxyService)
+	sagaRepo := new(mocks.SagaRepository)
+	sagas := customer.NewSagaCoordinator(sagaRepo, new(mocks.ReturnSagaRepository), rentalRepo, dvdSvc, nil, log.NewNopLogger())
+	svc := customer.NewService(repo, rentalRepo, purchaseRepo, sagas, customer.NewFeePolicy(nil), log.NewNopLogger(), discard.NewCounter(), discard.NewHistogram(), dvdSvc)
+	sagaRepo.On("Update", mock.Anything).Return(nil)
+	type args struct {
+		customerID string
+		dvdID      string
+	}
+	cases := []struct {
+		name    string
+		args    args
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name: "OK",
+			args: args{
+				customerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+				dvdID:      "5e8b83c9-36f3-4084-94b5-33153246d534",
+			},
+			wantErr: false,
+			mock: func() {
+				repo.On("GetByID", "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7").Return(&customer.Customer{}, nil).Once()
+				sagaRepo.On("Store", mock.MatchedBy(func(s *customer.RentSaga) bool {
+					return s.State == customer.SagaStarted && s.DVDID == "5e8b83c9-36f3-4084-94b5-33153246d534"
+				})).Return(nil).Once()
+				dvdSvc.On("UpdateDVDStatus", mock.Anything, "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7", "5e8b83c9-36f3-4084-94b5-33153246d534").Return(nil).Once()
+				rentalRepo.On("Store", mock.MatchedBy(func(r *customer.Rental) bool {
+					return r.CustomerID == "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7" &&
+						r.DVDID == "5e8b83c9-36f3-4084-94b5-33153246d534" &&
+						r.DueAt.After(r.RentedAt)
+				})).Return(nil).Once()
+			},
+		},
+		{
+			name: "missing customer id",
+			args: args{
+				dvdID: "5e8b83c9-36f3-4084-94b5-33153246d534",
+			},
+			wantErr: true,
+			mock:    func() {},
+		},
+		{
+			name: "customer not found",
+			args: args{
+				customerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+				dvdID:      "5e8b83c9-36f3-4084-94b5-33153246d534",
+			},
+			wantErr: true,
+			mock: func() {
+				repo.On("GetByID", mock.Anything).Return(nil, errors.New("pg: no rows in result set")).Once()
+			},
+		},
+		{
+			name: "dvd not available",
+			args: args{
+				customerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+				dvdID:      "5e8b83c9-36f3-4084-94b5-33153246d534",
+			},
+			wantErr: true,
+			mock: func() {
+				repo.On("GetByID", mock.Anything).Return(&customer.Customer{}, nil).Once()
+				sagaRepo.On("Store", mock.Anything).Return(nil).Once()
+				dvdSvc.On("UpdateDVDStatus", mock.Anything, mock.Anything, mock.Anything).Return(apperr.New(apperr.Conflict, "dvd not available")).Once()
+			},
+		},
+		{
+			name: "dvd service unavailable",
+			args: args{
+				customerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+				dvdID:      "5e8b83c9-36f3-4084-94b5-33153246d534",
+			},
+			wantErr: true,
+			mock: func() {
+				repo.On("GetByID", mock.Anything).Return(&customer.Customer{}, nil).Once()
+				sagaRepo.On("Store", mock.Anything).Return(nil).Once()
+				dvdSvc.On("UpdateDVDStatus", mock.Anything, mock.Anything, mock.Anything).Return(apperr.New(apperr.Unavailable, "connection refused")).Once()
+				dvdSvc.On("ReleaseDVD", mock.Anything, "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7", "5e8b83c9-36f3-4084-94b5-33153246d534").Return(nil).Once()
+			},
+		},
+		{
+			name: "store rental failed",
+			args: args{
+				customerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+				dvdID:      "5e8b83c9-36f3-4084-94b5-33153246d534",
+			},
+			wantErr: true,
+			mock: func() {
+				repo.On("GetByID", mock.Anything).Return(&customer.Customer{}, nil).Once()
+				sagaRepo.On("Store", mock.Anything).Return(nil).Once()
+				dvdSvc.On("UpdateDVDStatus", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				rentalRepo.On("Store", mock.Anything).Return(errors.New("store failed")).Once()
+				dvdSvc.On("ReleaseDVD", mock.Anything, "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7", "5e8b83c9-36f3-4084-94b5-33153246d534").Return(nil).Once()
+			},
+		},
+		{
+			name: "store saga failed",
+			args: args{
+				customerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+				dvdID:      "5e8b83c9-36f3-4084-94b5-33153246d534",
+			},
+			wantErr: true,
+			mock: func() {
+				repo.On("GetByID", mock.Anything).Return(&customer.Customer{}, nil).Once()
+				sagaRepo.On("Store", mock.Anything).Return(errors.New("store failed")).Once()
+			},
+		},
+	}
+	for _, v := range cases {
+		t.Run(v.name, func(t *testing.T) {
+			v.mock()
+			r, err := svc.Rent(ctx, v.args.customerID, v.args.dvdID)
+			assert.Equalf(v.wantErr, err != nil, "name: %v , wantErr %v, got %v , err ", v.name, v.wantErr, err != nil, err)
+			assert.Equal(v.wantErr, r == nil)
+		})
+	}
+	dvdSvc.AssertExpectations(t)
+}
+
+func TestResumeSagas(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	rentalRepo := new(mocks.RentalRepository)
+	dvdSvc := new(mocks.ProxyService)
+	sagaRepo := new(mocks.SagaRepository)
+	returnRepo := new(mocks.ReturnSagaRepository)
+	sagas := customer.NewSagaCoordinator(sagaRepo, returnRepo, rentalRepo, dvdSvc, nil, log.NewNopLogger())
+
+	stored := &customer.RentSaga{RentalID: "rental-1", CustomerID: "customer-1", DVDID: "dvd-1", State: customer.SagaDVDRented}
+	interrupted := &customer.RentSaga{RentalID: "rental-2", CustomerID: "customer-1", DVDID: "dvd-2", State: customer.SagaStarted}
+	unreleased := &customer.RentSaga{RentalID: "rental-3", CustomerID: "customer-2", DVDID: "dvd-3", State: customer.SagaCompensating}
+	sagaRepo.On("ListPending", mock.Anything).Return([]*customer.RentSaga{stored, interrupted, unreleased}, nil).Once()
+	sagaRepo.On("Update", mock.Anything).Return(nil)
+
+	rentalRepo.On("GetActiveByDVD", "dvd-1").Return(&customer.Rental{Base: model.Base{ID: "rental-1"}, CustomerID: "customer-1"}, nil).Once()
+	rentalRepo.On("GetActiveByDVD", "dvd-2").Return(nil, apperr.New(apperr.NotFound, "rental not found")).Once()
+	dvdSvc.On("ReleaseDVD", mock.Anything, "customer-1", "dvd-2").Return(nil).Once()
+	rentalRepo.On("GetActiveByDVD", "dvd-3").Return(nil, apperr.New(apperr.NotFound, "rental not found")).Once()
+	dvdSvc.On("ReleaseDVD", mock.Anything, "customer-2", "dvd-3").Return(apperr.New(apperr.Unavailable, "connection refused")).Once()
+
+	due := time.Now().Add(-50 * time.Hour)
+	returned := &customer.ReturnSaga{RentalID: "rental-4", CustomerID: "customer-3", DVDID: "dvd-4", ReturnedAt: time.Now(), LateFee: 300, State: customer.SagaDVDReturned}
+	unanswered := &customer.ReturnSaga{RentalID: "rental-5", CustomerID: "customer-3", DVDID: "dvd-5", State: customer.SagaStarted}
+	closed := &customer.ReturnSaga{RentalID: "rental-6", CustomerID: "customer-3", DVDID: "dvd-6", State: customer.SagaDVDReturned}
+	unclosed := &customer.ReturnSaga{RentalID: "rental-7", CustomerID: "customer-3", DVDID: "dvd-7", LateFee: 100, State: customer.SagaDVDReturned}
+	rentedAgain := &customer.ReturnSaga{RentalID: "rental-8", CustomerID: "customer-3", DVDID: "dvd-8", State: customer.SagaStarted}
+	unanswered.CreatedAt = time.Now()
+	rentedAgain.CreatedAt = time.Now()
+	returnRepo.On("ListPending", mock.Anything).Return([]*customer.ReturnSaga{returned, unanswered, closed, unclosed, rentedAgain}, nil).Once()
+	returnRepo.On("Update", mock.Anything).Return(nil)
+
+	rentalRepo.On("GetActiveByDVD", "dvd-4").Return(&customer.Rental{Base: model.Base{ID: "rental-4"}, CustomerID: "customer-3", DueAt: due}, nil).Once()
+	rentalRepo.On("Close", mock.MatchedBy(func(r *customer.Rental) bool { return r.ID == "rental-4" && r.LateFee == 300 })).Return(nil).Once()
+	rentalRepo.On("GetActiveByDVD", "dvd-5").Return(&customer.Rental{Base: model.Base{ID: "rental-5"}, CustomerID: "customer-3", DueAt: due}, nil).Once()
+	dvdSvc.On("ReturnDVD", mock.Anything, "customer-3", "dvd-5").Return(nil, apperr.New(apperr.Conflict, "dvd is not rented")).Once()
+	rentalRepo.On("Close", mock.MatchedBy(func(r *customer.Rental) bool { return r.ID == "rental-5" && r.IsReturned() && r.LateFee == 300 })).Return(nil).Once()
+	rentalRepo.On("GetActiveByDVD", "dvd-6").Return(nil, apperr.New(apperr.NotFound, "rental not found")).Once()
+	rentalRepo.On("GetActiveByDVD", "dvd-7").Return(&customer.Rental{Base: model.Base{ID: "rental-7"}, CustomerID: "customer-3", DueAt: due}, nil).Once()
+	rentalRepo.On("Close", mock.MatchedBy(func(r *customer.Rental) bool { return r.ID == "rental-7" })).Return(errors.New("close failed")).Once()
+	//* The first attempt took dvd-8 back and customer-4 rented it since, their rental is not returned
+	rentalRepo.On("GetActiveByDVD", "dvd-8").Return(&customer.Rental{Base: model.Base{ID: "rental-8"}, CustomerID: "customer-3", DueAt: due}, nil).Once()
+	dvdSvc.On("ReturnDVD", mock.Anything, "customer-3", "dvd-8").Return(nil, apperr.New(apperr.Conflict, "dvd is rented by another customer")).Once()
+	rentalRepo.On("Close", mock.MatchedBy(func(r *customer.Rental) bool { return r.ID == "rental-8" && r.IsReturned() })).Return(nil).Once()
+
+	n, err := sagas.Resume(ctx, time.Now())
+	assert.NoError(err)
+	assert.Equal(6, n)
+	assert.Equal(customer.SagaCompleted, stored.State)
+	assert.Equal(customer.SagaCompensated, interrupted.State)
+	assert.Equal(customer.SagaCompensating, unreleased.State, "a saga failing to release is retried later")
+	assert.Equal(customer.SagaCompleted, returned.State)
+	assert.Equal(customer.SagaCompleted, unanswered.State, "a dvd already back on the shelf was returned by the interrupted attempt")
+	assert.Equal(customer.SagaCompleted, closed.State)
+	assert.Equal(customer.SagaDVDReturned, unclosed.State, "a saga failing to close is retried later")
+	assert.Equal(customer.SagaCompleted, rentedAgain.State, "a dvd rented by another customer since was returned by the interrupted attempt")
+	dvdSvc.AssertExpectations(t)
+	rentalRepo.AssertExpectations(t)
+}
+
+func TestRunRecovery(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	sagaRepo := new(mocks.SagaRepository)
+	returnRepo := new(mocks.ReturnSagaRepository)
+	sagas := customer.NewSagaCoordinator(sagaRepo, returnRepo, new(mocks.RentalRepository), new(mocks.ProxyService), nil, log.NewNopLogger())
+
+	//* Sagas younger than the recovery interval may still be running on another replica
+	cutoff := mock.MatchedBy(func(before time.Time) bool {
+		return before.Before(time.Now().Add(-sagas.RecoveryInterval + time.Second))
+	})
+	sagaRepo.On("ListPending", cutoff).Return(nil, nil).Once()
+	returnRepo.On("ListPending", cutoff).Return(nil, nil).Once()
+
+	cancel()
+	sagas.RunRecovery(ctx)
+	sagaRepo.AssertExpectations(t)
+	returnRepo.AssertExpectations(t)
+}
+
+func TestBuy(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	repo := new(mocks.Repository)
+	rentalRepo := new(mocks.RentalRepository)
+	purchaseRepo := new(mocks.PurchaseRepository)
+	dvdSvc := new(mocks.ProxyService)
+	svc := customer.NewService(repo, rentalRepo, purchaseRepo, nil, customer.NewFeePolicy(nil), log.NewNopLogger(), discard.NewCounter(), discard.NewHistogram(), dvdSvc)
+	type args struct {
+		customerID string
+		dvdID      string
+	}
+	cases := []struct {
+		name    string
+		args    args
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name: "OK",
+			args: args{
+				customerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+				dvdID:      "5e8b83c9-36f3-4084-94b5-33153246d534",
+			},
+			wantErr: false,
+			mock: func() {
+				repo.On("GetByID", "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7").Return(&customer.Customer{}, nil).Once()
+				purchaseRepo.On("Store", mock.MatchedBy(func(p *customer.Purchase) bool {
+					return p.CustomerID == "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7" &&
+						p.DVDID == "5e8b83c9-36f3-4084-94b5-33153246d534" &&
+						p.State == customer.PurchasePending
+				})).Return(nil).Once()
+				dvdSvc.On("SellDVD", mock.Anything, "5e8b83c9-36f3-4084-94b5-33153246d534").Return(&customer.DVD{Price: 1500}, nil).Once()
+				purchaseRepo.On("Update", mock.MatchedBy(func(p *customer.Purchase) bool {
+					return p.Price == 1500 && p.State == customer.PurchaseCompleted
+				})).Return(nil).Once()
+			},
+		},
+		{
+			name: "missing dvd id",
+			args: args{
+				customerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+			},
+			wantErr: true,
+			mock:    func() {},
+		},
+		{
+			name: "customer not found",
+			args: args{
+				customerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+				dvdID:      "5e8b83c9-36f3-4084-94b5-33153246d534",
+			},
+			wantErr: true,
+			mock: func() {
+				repo.On("GetByID", mock.Anything).Return(nil, pg.ErrNoRows).Once()
+			},
+		},
+		{
+			name: "dvd not for sale",
+			args: args{
+				customerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+				dvdID:      "5e8b83c9-36f3-4084-94b5-33153246d534",
+			},
+			wantErr: true,
+			mock: func() {
+				repo.On("GetByID", mock.Anything).Return(&customer.Customer{}, nil).Once()
+				purchaseRepo.On("Store", mock.Anything).Return(nil).Once()
+				dvdSvc.On("SellDVD", mock.Anything, mock.Anything).Return(nil, apperr.New(apperr.Conflict, "dvd has been sold")).Once()
+				purchaseRepo.On("Update", mock.MatchedBy(func(p *customer.Purchase) bool {
+					return p.State == customer.PurchaseFailed
+				})).Return(nil).Once()
+			},
+		},
+		{
+			name: "store purchase failed",
+			args: args{
+				customerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+				dvdID:      "5e8b83c9-36f3-4084-94b5-33153246d534",
+			},
+			wantErr: true,
+			mock: func() {
+				//* The dvd is not sold when the purchase can not be recorded
+				repo.On("GetByID", mock.Anything).Return(&customer.Customer{}, nil).Once()
+				purchaseRepo.On("Store", mock.Anything).Return(errors.New("store failed")).Once()
+			},
+		},
+		{
+			name: "dvd service unreachable",
+			args: args{
+				customerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+				dvdID:      "5e8b83c9-36f3-4084-94b5-33153246d534",
+			},
+			wantErr: true,
+			mock: func() {
+				//* The dvd may have been sold, the purchase stays pending
+				repo.On("GetByID", mock.Anything).Return(&customer.Customer{}, nil).Once()
+				purchaseRepo.On("Store", mock.Anything).Return(nil).Once()
+				dvdSvc.On("SellDVD", mock.Anything, mock.Anything).Return(nil, apperr.New(apperr.Unavailable, "connection refused")).Once()
+			},
+		},
+		{
+			name: "sold but not recorded",
+			args: args{
+				customerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+				dvdID:      "5e8b83c9-36f3-4084-94b5-33153246d534",
+			},
+			wantErr: false,
+			mock: func() {
+				//* The customer got the dvd, the purchase left pending is logged
+				repo.On("GetByID", mock.Anything).Return(&customer.Customer{}, nil).Once()
+				purchaseRepo.On("Store", mock.Anything).Return(nil).Once()
+				dvdSvc.On("SellDVD", mock.Anything, mock.Anything).Return(&customer.DVD{Price: 1500}, nil).Once()
+				purchaseRepo.On("Update", mock.Anything).Return(errors.New("update failed")).Once()
+			},
+		},
+	}
+	for _, v := range cases {
+		t.Run(v.name, func(t *testing.T) {
+			v.mock()
+			p, err := svc.Buy(ctx, v.args.customerID, v.args.dvdID)
+			assert.Equalf(v.wantErr, err != nil, "name: %v , wantErr %v, got %v , err ", v.name, v.wantErr, err != nil, err)
+			assert.Equal(v.wantErr, p == nil)
+		})
+	}
+	dvdSvc.AssertExpectations(t)
+	purchaseRepo.AssertExpectations(t)
+}
+
+func TestReturn(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	repo := new(mocks.Repository)
+	rentalRepo := new(mocks.RentalRepository)
+	purchaseRepo := new(mocks.PurchaseRepository)
+	dvdSvc := new(mocks.ProxyService)
+	returnRepo := new(mocks.ReturnSagaRepository)
+	sagas := customer.NewSagaCoordinator(new(mocks.SagaRepository), returnRepo, rentalRepo, dvdSvc, nil, log.NewNopLogger())
+	svc := customer.NewService(repo, rentalRepo, purchaseRepo, sagas, customer.NewFeePolicy(nil), log.NewNopLogger(), discard.NewCounter(), discard.NewHistogram(), dvdSvc)
+	returnRepo.On("Update", mock.Anything).Return(nil)
+	type args struct {
+		customerID string
+		dvdID      string
+	}
+	cases := []struct {
+		name    string
+		args    args
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name: "OK",
+			args: args{
+				customerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+				dvdID:      "5e8b83c9-36f3-4084-94b5-33153246d534",
+			},
+			wantErr: false,
+			mock: func() {
+				rentalRepo.On("GetActiveByDVD", "5e8b83c9-36f3-4084-94b5-33153246d534").Return(&customer.Rental{
+					CustomerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+					DVDID:      "5e8b83c9-36f3-4084-94b5-33153246d534",
+					DueAt:      time.Now().Add(time.Hour),
+				}, nil).Once()
+				returnRepo.On("Store", mock.Anything).Return(nil).Once()
+				dvdSvc.On("ReturnDVD", mock.Anything, "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7", "5e8b83c9-36f3-4084-94b5-33153246d534").Return(&customer.DVD{}, nil).Once()
+				rentalRepo.On("Close", mock.MatchedBy(func(r *customer.Rental) bool {
+					return r.IsReturned() && r.LateFee == 0
+				})).Return(nil).Once()
+			},
+		},
+		{
+			name: "late return charged",
+			args: args{
+				customerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+				dvdID:      "5e8b83c9-36f3-4084-94b5-33153246d534",
+			},
+			wantErr: false,
+			mock: func() {
+				rentalRepo.On("GetActiveByDVD", mock.Anything).Return(&customer.Rental{
+					CustomerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+					DueAt:      time.Now().Add(-50 * time.Hour),
+				}, nil).Once()
+				returnRepo.On("Store", mock.Anything).Return(nil).Once()
+				dvdSvc.On("ReturnDVD", mock.Anything, mock.Anything, mock.Anything).Return(&customer.DVD{ReplacementCost: 1999}, nil).Once()
+				rentalRepo.On("Close", mock.MatchedBy(func(r *customer.Rental) bool {
+					return r.LateFee == 300
+				})).Return(nil).Once()
+			},
+		},
+		{
+			name: "close failed",
+			args: args{
+				customerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+				dvdID:      "5e8b83c9-36f3-4084-94b5-33153246d534",
+			},
+			wantErr: true,
+			mock: func() {
+				rentalRepo.On("GetActiveByDVD", mock.Anything).Return(&customer.Rental{
+					CustomerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+					DueAt:      time.Now().Add(-50 * time.Hour),
+				}, nil).Once()
+				returnRepo.On("Store", mock.Anything).Return(nil).Once()
+				dvdSvc.On("ReturnDVD", mock.Anything, mock.Anything, mock.Anything).Return(&customer.DVD{}, nil).Once()
+				rentalRepo.On("Close", mock.Anything).Return(errors.New("close failed")).Once()
+			},
+		},
+		{
+			name: "missing customer id",
+			args: args{
+				dvdID: "5e8b83c9-36f3-4084-94b5-33153246d534",
+			},
+			wantErr: true,
+			mock:    func() {},
+		},
+		{
+			name: "missing dvd id",
+			args: args{
+				customerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+			},
+			wantErr: true,
+			mock:    func() {},
+		},
+		{
+			name: "rented by another customer",
+			args: args{
+				customerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+				dvdID:      "5e8b83c9-36f3-4084-94b5-33153246d534",
+			},
+			wantErr: true,
+			mock: func() {
+				rentalRepo.On("GetActiveByDVD", mock.Anything).Return(&customer.Rental{
+					CustomerID: "66d112da-07e3-41de-bce3-86fe2bd52b24",
+				}, nil).Once()
+			},
+		},
+		{
+			name: "dvd service failed",
+			args: args{
+				customerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+				dvdID:      "5e8b83c9-36f3-4084-94b5-33153246d534",
+			},
+			wantErr: true,
+			mock: func() {
+				rentalRepo.On("GetActiveByDVD", mock.Anything).Return(&customer.Rental{
+					CustomerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+				}, nil).Once()
+				returnRepo.On("Store", mock.Anything).Return(nil).Once()
+				dvdSvc.On("ReturnDVD", mock.Anything, mock.Anything, mock.Anything).Return(nil, apperr.New(apperr.Conflict, "dvd is not rented")).Once()
+			},
+		},
+	}
+	for _, v := range cases {
+		t.Run(v.name, func(t *testing.T) {
+			v.mock()
+			r, err := svc.Return(ctx, v.args.customerID, v.args.dvdID)
+			assert.Equalf(v.wantErr, err != nil, "name: %v , wantErr %v, got %v , err ", v.name, v.wantErr, err != nil, err)
+			assert.Equal(v.wantErr, r == nil)
+		})
+	}
+}
+
+func TestBalance(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	repo := new(mocks.Repository)
+	rentalRepo := new(mocks.RentalRepository)
+	purchaseRepo := new(mocks.PurchaseRepository)
+	dvdSvc := new(mocks.ProxyService)
+	svc := customer.NewService(repo, rentalRepo, purchaseRepo, nil, customer.NewFeePolicy(nil), log.NewNopLogger(), discard.NewCounter(), discard.NewHistogram(), dvdSvc)
+	cases := []struct {
+		name    string
+		id      string
+		want    int64
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name: "OK",
+			id:   "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+			want: 300,
+			mock: func() {
+				repo.On("GetByID", "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7").Return(&customer.Customer{Balance: 300}, nil).Once()
+			},
+		},
+		{
+			name:    "missing id",
+			wantErr: true,
+			mock:    func() {},
+		},
+		{
+			name:    "not found",
+			id:      "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+			wantErr: true,
+			mock: func() {
+				repo.On("GetByID", mock.Anything).Return(nil, pg.ErrNoRows).Once()
+			},
+		},
+	}
+	for _, v := range cases {
+		t.Run(v.name, func(t *testing.T) {
+			v.mock()
+			b, err := svc.Balance(ctx, v.id)
+			assert.Equalf(v.wantErr, err != nil, "name: %v , wantErr %v, got %v , err ", v.name, v.wantErr, err != nil, err)
+			assert.Equal(v.want, b)
+		})
+	}
+}
+
+func TestLateFee(t *testing.T) {
+	assert := assert.New(t)
+	policy := customer.FeePolicy{
+		Period:      7 * 24 * time.Hour,
+		GracePeriod: 12 * time.Hour,
+		DailyRate:   100,
+	}
+	due := time.Date(2020, 1, 10, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name            string
+		returnedAt      time.Time
+		replacementCost int64
+		want            int64
+	}{
+		{
+			name:       "on time",
+			returnedAt: due.Add(-time.Hour),
+			want:       0,
+		},
+		{
+			name:       "within grace period",
+			returnedAt: due.Add(12 * time.Hour),
+			want:       0,
+		},
+		{
+			name:       "past grace period",
+			returnedAt: due.Add(13 * time.Hour),
+			want:       100,
+		},
+		{
+			name:       "started days are charged",
+			returnedAt: due.Add(49 * time.Hour),
+			want:       300,
+		},
+		{
+			name:            "capped at replacement cost",
+			returnedAt:      due.Add(100 * 24 * time.Hour),
+			replacementCost: 1999,
+			want:            1999,
+		},
+		{
+			name:       "unknown replacement cost is not capped",
+			returnedAt: due.Add(100 * 24 * time.Hour),
+			want:       10000,
+		},
+	}
+	for _, v := range cases {
+		t.Run(v.name, func(t *testing.T) {
+			fee := policy.LateFee(&customer.Rental{DueAt: due}, v.returnedAt, v.replacementCost)
+			assert.Equalf(v.want, fee, "name: %v", v.name)
+		})
+	}
+}
+
+func TestGet(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	repo := new(mocks.Repository)
+	rentalRepo := new(mocks.RentalRepository)
+	purchaseRepo := new(mocks.PurchaseRepository)
+	dvdSvc := new(mocks.ProxyService)
+	svc := customer.NewService(repo, rentalRepo, purchaseRepo, nil, customer.NewFeePolicy(nil), log.NewNopLogger(), discard.NewCounter(), discard.NewHistogram(), dvdSvc)
+	cases := []struct {
+		name    string
+		id      string
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name:    "OK",
+			id:      "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+			wantErr: false,
+			mock: func() {
+				repo.On("GetByID", "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7").Return(&customer.Customer{Name: "Duynguyen"}, nil).Once()
+			},
+		},
+		{
+			name:    "missing id",
+			wantErr: true,
+			mock:    func() {},
+		},
+		{
+			name:    "not found",
+			id:      "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+			wantErr: true,
+			mock: func() {
+				repo.On("GetByID", mock.Anything).Return(nil, pg.ErrNoRows).Once()
+			},
+		},
+	}
+	for _, v := range cases {
+		t.Run(v.name, func(t *testing.T) {
+			v.mock()
+			c, err := svc.Get(ctx, v.id)
+			assert.Equalf(v.wantErr, err != nil, "name: %v , wantErr %v, got %v , err ", v.name, v.wantErr, err != nil, err)
+			assert.Equal(v.wantErr, c == nil)
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	repo := new(mocks.Repository)
+	rentalRepo := new(mocks.RentalRepository)
+	purchaseRepo := new(mocks.PurchaseRepository)
+	dvdSvc := new(mocks.ProxyService)
+	svc := customer.NewService(repo, rentalRepo, purchaseRepo, nil, customer.NewFeePolicy(nil), log.NewNopLogger(), discard.NewCounter(), discard.NewHistogram(), dvdSvc)
+	type args struct {
+		id      string
+		name    string
+		address string
+	}
+	cases := []struct {
+		name    string
+		args    args
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name: "OK",
+			args: args{
+				id:      "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+				name:    "Duynguyen",
+				address: "1102 Truong Sa Street",
+			},
+			wantErr: false,
+			mock: func() {
+				repo.On("GetByID", mock.Anything).Return(&customer.Customer{Name: "Duy"}, nil).Once()
+				repo.On("Update", mock.MatchedBy(func(c *customer.Customer) bool {
+					return c.Name == "Duynguyen" && c.Address == "1102 Truong Sa Street"
+				})).Return(nil).Once()
+			},
+		},
+		{
+			name: "missing address",
+			args: args{
+				id:   "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+				name: "Duynguyen",
+			},
+			wantErr: true,
+			mock:    func() {},
+		},
+		{
+			name: "not found",
+			args: args{
+				id:      "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+				name:    "Duynguyen",
+				address: "1102 Truong Sa Street",
+			},
+			wantErr: true,
+			mock: func() {
+				repo.On("GetByID", mock.Anything).Return(nil, pg.ErrNoRows).Once()
+			},
+		},
+		{
+			name: "update failed",
+			args: args{
+				id:      "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+				name:    "Duynguyen",
+				address: "1102 Truong Sa Street",
+			},
+			wantErr: true,
+			mock: func() {
+				repo.On("GetByID", mock.Anything).Return(&customer.Customer{}, nil).Once()
+				repo.On("Update", mock.Anything).Return(errors.New("update failed")).Once()
+			},
+		},
+	}
+	for _, v := range cases {
+		t.Run(v.name, func(t *testing.T) {
+			v.mock()
+			_, err := svc.Update(ctx, v.args.id, v.args.name, v.args.address)
+			assert.Equalf(v.wantErr, err != nil, "name: %v , wantErr %v, got %v , err ", v.name, v.wantErr, err != nil, err)
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	repo := new(mocks.Repository)
+	rentalRepo := new(mocks.RentalRepository)
+	purchaseRepo := new(mocks.PurchaseRepository)
+	dvdSvc := new(mocks.ProxyService)
+	svc := customer.NewService(repo, rentalRepo, purchaseRepo, nil, customer.NewFeePolicy(nil), log.NewNopLogger(), discard.NewCounter(), discard.NewHistogram(), dvdSvc)
+	cases := []struct {
+		name    string
+		id      string
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name:    "OK",
+			id:      "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+			wantErr: false,
+			mock: func() {
+				repo.On("GetByID", mock.Anything).Return(&customer.Customer{}, nil).Once()
+				rentalRepo.On("ListActiveByCustomer", "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7").Return(nil, nil).Once()
+				repo.On("Delete", mock.Anything).Return(nil).Once()
+			},
+		},
+		{
+			name:    "missing id",
+			wantErr: true,
+			mock:    func() {},
+		},
+		{
+			name:    "not found",
+			id:      "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+			wantErr: true,
+			mock: func() {
+				repo.On("GetByID", mock.Anything).Return(nil, pg.ErrNoRows).Once()
+			},
+		},
+		{
+			name:    "has active rentals",
+			id:      "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+			wantErr: true,
+			mock: func() {
+				repo.On("GetByID", mock.Anything).Return(&customer.Customer{}, nil).Once()
+				rentalRepo.On("ListActiveByCustomer", mock.Anything).Return([]*customer.Rental{{}}, nil).Once()
+			},
+		},
+	}
+	for _, v := range cases {
+		t.Run(v.name, func(t *testing.T) {
+			v.mock()
+			err := svc.Delete(ctx, v.id)
+			assert.Equalf(v.wantErr, err != nil, "name: %v , wantErr %v, got %v , err ", v.name, v.wantErr, err != nil, err)
+		})
+	}
+}
+
+func TestJoinWaitlist(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	repo := new(mocks.Repository)
+	rentalRepo := new(mocks.RentalRepository)
+	purchaseRepo := new(mocks.PurchaseRepository)
+	dvdSvc := new(mocks.ProxyService)
+	svc := customer.NewService(repo, rentalRepo, purchaseRepo, nil, customer.NewFeePolicy(nil), log.NewNopLogger(), discard.NewCounter(), discard.NewHistogram(), dvdSvc)
+	type args struct {
+		customerID string
+		titleID    string
+	}
+	cases := []struct {
+		name    string
+		args    args
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name: "OK",
+			args: args{
+				customerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+				titleID:    "66d112da-07e3-41de-bce3-86fe2bd52b24",
+			},
+			wantErr: false,
+			mock: func() {
+				repo.On("GetByID", "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7").Return(&customer.Customer{}, nil).Once()
+				dvdSvc.On("JoinWaitlist", mock.Anything, "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7", "66d112da-07e3-41de-bce3-86fe2bd52b24").Return(&customer.Reservation{}, nil).Once()
+			},
+		},
+		{
+			name: "missing title id",
+			args: args{
+				customerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+			},
+			wantErr: true,
+			mock:    func() {},
+		},
+		{
+			name: "customer not found",
+			args: args{
+				customerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+				titleID:    "66d112da-07e3-41de-bce3-86fe2bd52b24",
+			},
+			wantErr: true,
+			mock: func() {
+				repo.On("GetByID", mock.Anything).Return(nil, pg.ErrNoRows).Once()
+			},
+		},
+		{
+			name: "already waiting",
+			args: args{
+				customerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+				titleID:    "66d112da-07e3-41de-bce3-86fe2bd52b24",
+			},
+			wantErr: true,
+			mock: func() {
+				repo.On("GetByID", mock.Anything).Return(&customer.Customer{}, nil).Once()
+				dvdSvc.On("JoinWaitlist", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("customer is already on the waitlist")).Once()
+			},
+		},
+	}
+	for _, v := range cases {
+		t.Run(v.name, func(t *testing.T) {
+			v.mock()
+			r, err := svc.JoinWaitlist(ctx, v.args.customerID, v.args.titleID)
+			assert.Equalf(v.wantErr, err != nil, "name: %v , wantErr %v, got %v , err ", v.name, v.wantErr, err != nil, err)
+			assert.Equal(v.wantErr, r == nil)
+		})
+	}
+}
+
+func TestLeaveWaitlist(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	repo := new(mocks.Repository)
+	rentalRepo := new(mocks.RentalRepository)
+	purchaseRepo := new(mocks.PurchaseRepository)
+	dvdSvc := new(mocks.ProxyService)
+	svc := customer.NewService(repo, rentalRepo, purchaseRepo, nil, customer.NewFeePolicy(nil), log.NewNopLogger(), discard.NewCounter(), discard.NewHistogram(), dvdSvc)
+
+	dvdSvc.On("LeaveWaitlist", mock.Anything, "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7", "66d112da-07e3-41de-bce3-86fe2bd52b24").Return(nil).Once()
+	assert.NoError(svc.LeaveWaitlist(ctx, "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7", "66d112da-07e3-41de-bce3-86fe2bd52b24"))
+	assert.Error(svc.LeaveWaitlist(ctx, "", "66d112da-07e3-41de-bce3-86fe2bd52b24"))
+
+	dvdSvc.On("ListWaitlist", mock.Anything, "66d112da-07e3-41de-bce3-86fe2bd52b24").Return([]customer.Reservation{{CustomerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7"}}, nil).Once()
+	waiting, err := svc.ListWaitlist(ctx, "66d112da-07e3-41de-bce3-86fe2bd52b24")
+	assert.NoError(err)
+	assert.Len(waiting, 1)
+	dvdSvc.AssertExpectations(t)
+}
+
+func TestListDVDs(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	repo := new(mocks.Repository)
+	rentalRepo := new(mocks.RentalRepository)
+	purchaseRepo := new(mocks.PurchaseRepository)
+	dvdSvc := new(mocks.ProxyService)
+	svc := customer.NewService(repo, rentalRepo, purchaseRepo, nil, customer.NewFeePolicy(nil), log.NewNopLogger(), discard.NewCounter(), discard.NewHistogram(), dvdSvc)
+
+	dvdSvc.On("ListDVDs", mock.Anything, "title", "Available", "", 10).Return(&customer.DVDPage{
+		DVDs: []customer.DVD{{ID: "5e8b83c9-36f3-4084-94b5-33153246d534", Name: "Title 1", Status: "Available"}},
+	}, nil).Once()
+	page, err := svc.ListDVDs(ctx, "title", "Available", "", 10)
+	assert.NoError(err)
+	assert.Len(page.DVDs, 1)
+
+	_, err = svc.ListDVDs(ctx, "", "", "", -1)
+	assert.Error(err)
+}
+
+func TestLocalProxy(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	repo := new(dvdMocks.Repository)
+	proxy := customer.NewLocalProxy(dvd.NewService(repo, dvd.NewWaitlistPolicy(nil), log.NewNopLogger(), discard.NewCounter(), discard.NewHistogram()))
+	const (
+		id      = "5e8b83c9-36f3-4084-94b5-33153246d534"
+		titleID = "0b7a1c3e-52a4-4c1b-8d7e-3f6b2f0d9a01"
+	)
+
+	repo.On("Get", id).Return(&dvd.Copy{Base: model.Base{ID: id}, TitleID: titleID, Title: &dvd.Title{Name: "Metropolis", ReplacementCost: 2000}}, nil).Once()
+	repo.On("Update", id, dvd.Status(dvd.Sold)).Return(&dvd.Copy{
+		Base:      model.Base{ID: id},
+		TitleID:   titleID,
+		Condition: dvd.Good,
+		Status:    dvd.Sold,
+	}, nil).Once()
+	d, err := proxy.SellDVD(ctx, id)
+	assert.NoError(err)
+	assert.Equal("Metropolis", d.Name)
+	assert.Equal(int64(1500), d.Price)
+	assert.Equal(dvd.Status(dvd.Sold).ToString(), d.Status)
+
+	//* Errors of the dvd service reach the customer service with their code, as they do over gRPC
+	_, err = proxy.SellDVD(ctx, "some-id")
+	assert.Equal(apperr.InvalidArgument, apperr.CodeOf(err))
+	_, err = proxy.ListDVDs(ctx, "", "lost", "", 10)
+	assert.Equal(apperr.InvalidArgument, apperr.CodeOf(err))
+	repo.AssertExpectations(t)
+}
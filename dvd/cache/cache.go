@@ -15,16 +15,38 @@ type cacheClient struct {
 	client *redis.Client
 }
 
-//NewCacheClient init a new cache client
+// NewCacheClient init a new cache client
 func NewCacheClient(cli *redis.Client) repository.Cache {
 	return &cacheClient{client: cli}
 }
 
-func (c *cacheClient) StoreToCache(key string, dvd dvd.DVD) error {
-	bytes, err := msgpack.Marshal(dvd)
+func (c *cacheClient) StoreToCache(key string, copy dvd.Copy) error {
+	bytes, err := msgpack.Marshal(copy)
 	if err != nil {
 		return err
 	}
 
-	return c.client.HSet(key, dvd.ID, bytes).Err()
+	return c.client.HSet(key, copy.ID, bytes).Err()
+}
+
+func (c *cacheClient) StoreTitleToCache(key string, title dvd.Title) error {
+	bytes, err := msgpack.Marshal(title)
+	if err != nil {
+		return err
+	}
+
+	return c.client.HSet(key, title.ID, bytes).Err()
+}
+
+func (c *cacheClient) GetTitleFromCache(key, field string) (*dvd.Title, error) {
+	val, err := c.client.HGet(key, field).Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	var result = new(dvd.Title)
+	if err := msgpack.Unmarshal(val, result); err != nil {
+		return nil, err
+	}
+	return result, nil
 }
@@ -7,15 +7,16 @@ import (
 	"testing"
 	"time"
 
-	"github.com/ory/dockertest"
 	"github.com/go-redis/redis/v7"
-	"github.com/stretchr/testify/assert"
 	"github.com/ngray1747/dvd-rental/dvd"
 	"github.com/ngray1747/dvd-rental/dvd/cache"
 	"github.com/ngray1747/dvd-rental/internal/model"
+	"github.com/ory/dockertest"
+	"github.com/stretchr/testify/assert"
 )
 
 var db *redis.Client
+
 func TestMain(m *testing.M) {
 
 	var err error
@@ -49,8 +50,8 @@ func TestMain(m *testing.M) {
 func TestStoreToCache(t *testing.T) {
 	client := cache.NewCacheClient(db)
 	type args struct {
-		key      string
-		dvd dvd.DVD
+		key  string
+		copy dvd.Copy
 	}
 	cases := []struct {
 		name    string
@@ -61,13 +62,14 @@ func TestStoreToCache(t *testing.T) {
 			name: "Ok",
 			args: args{
 				key: "dvds",
-				dvd: dvd.DVD{
+				copy: dvd.Copy{
 					Base: model.Base{
 						ID:        "66d112da-07e3-41de-bce3-86fe2bd52b24",
 						CreatedAt: time.Now(),
 						UpdatedAt: time.Now(),
 					},
-					Name:      "Title 1",
+					TitleID: "0b7a1c3e-52a4-4c1b-8d7e-3f6b2f0d9a01",
+					Barcode: "0001",
 				},
 			},
 			wantErr: false,
@@ -75,8 +77,28 @@ func TestStoreToCache(t *testing.T) {
 	}
 	for _, v := range cases {
 		t.Run(v.name, func(t *testing.T) {
-			err := client.StoreToCache(v.args.key, v.args.dvd)
+			err := client.StoreToCache(v.args.key, v.args.copy)
 			assert.Equal(t, v.wantErr, err != nil)
 		})
 	}
 }
+
+func TestTitleCache(t *testing.T) {
+	client := cache.NewCacheClient(db)
+	title := dvd.Title{
+		Base: model.Base{
+			ID: "0b7a1c3e-52a4-4c1b-8d7e-3f6b2f0d9a01",
+		},
+		Name:   "Title 1",
+		Genres: []string{"Drama"},
+	}
+	assert.NoError(t, client.StoreTitleToCache("dvds:titles", title))
+
+	got, err := client.GetTitleFromCache("dvds:titles", title.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, title.Name, got.Name)
+	assert.Equal(t, title.Genres, got.Genres)
+
+	_, err = client.GetTitleFromCache("dvds:titles", "unknown")
+	assert.Equal(t, redis.Nil, err)
+}
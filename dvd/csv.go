@@ -0,0 +1,204 @@
+package dvd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ngray1747/dvd-rental/internal/apperr"
+)
+
+const (
+	// ExportCSV exports copies as CSV with a header row, the file can be imported back
+	ExportCSV = "csv"
+	// ExportJSONL exports copies as JSON Lines, a JSON copy per line
+	ExportJSONL = "jsonl"
+
+	defaultImportBatchSize = 100
+)
+
+var (
+	errInvalidExportFormat = apperr.New(apperr.InvalidArgument, "export format must be csv or jsonl")
+	errDuplicateBarcode    = apperr.New(apperr.Conflict, "barcode is already used")
+)
+
+// importColumns are the columns an import requires, in any order, other columns are ignored
+var importColumns = []string{"title_id", "barcode", "condition"}
+
+// exportColumns are the columns of a CSV export
+var exportColumns = []string{"id", "title_id", "title", "barcode", "condition", "status", "created_at"}
+
+// ImportRow is a copy to import, Row is its position in the file, the header being row 1
+type ImportRow struct {
+	Row       int
+	TitleID   string
+	Barcode   string
+	Condition Condition
+}
+
+// RowError tells why a row was not imported
+type RowError struct {
+	Row     int    `json:"row"`
+	Barcode string `json:"barcode,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportResult counts the imported rows and reports the others
+type ImportResult struct {
+	Imported int        `json:"imported"`
+	Errors   []RowError `json:"errors,omitempty"`
+}
+
+func (r *ImportResult) fail(row ImportRow, err error) {
+	r.Errors = append(r.Errors, RowError{Row: row.Row, Barcode: row.Barcode, Message: err.Error()})
+}
+
+func (r *ImportResult) merge(other *ImportResult) {
+	r.Imported += other.Imported
+	r.Errors = append(r.Errors, other.Errors...)
+}
+
+// Importer imports a batch of rows, Service and DVDEndpoints are importers
+type Importer interface {
+	ImportDVDs(ctx context.Context, rows []ImportRow) (*ImportResult, error)
+}
+
+// ImportCSV reads copies from a CSV file and imports them through imp in batches of batchSize rows,
+// the default batch size being used when batchSize is not positive. Rows which can not be read or
+// imported are reported in the result, the import goes on with the next ones.
+func ImportCSV(ctx context.Context, imp Importer, r io.Reader, batchSize int) (*ImportResult, error) {
+	if batchSize <= 0 {
+		batchSize = defaultImportBatchSize
+	}
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, apperr.New(apperr.InvalidArgument, "import file is empty")
+	} else if err != nil {
+		return nil, apperr.Errorf(apperr.InvalidArgument, "invalid import header: %v", err)
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range importColumns {
+		if _, ok := index[name]; !ok {
+			return nil, apperr.Errorf(apperr.InvalidArgument, "import header is missing the %s column", name)
+		}
+	}
+
+	result := new(ImportResult)
+	batch := make([]ImportRow, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		res, err := imp.ImportDVDs(ctx, batch)
+		if err != nil {
+			return err
+		}
+		result.merge(res)
+		batch = batch[:0]
+		return nil
+	}
+	for n := 2; ; n++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if perr, ok := err.(*csv.ParseError); ok {
+			result.Errors = append(result.Errors, RowError{Row: n, Message: perr.Err.Error()})
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		row, err := newImportRow(n, record, index)
+		if err != nil {
+			result.fail(row, err)
+			continue
+		}
+		batch = append(batch, row)
+		if len(batch) == batchSize {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(result.Errors, func(i, j int) bool {
+		return result.Errors[i].Row < result.Errors[j].Row
+	})
+	return result, nil
+}
+
+func newImportRow(n int, record []string, index map[string]int) (ImportRow, error) {
+	row := ImportRow{Row: n}
+	field := func(name string) (string, error) {
+		i := index[name]
+		if i >= len(record) {
+			return "", fmt.Errorf("missing %s", name)
+		}
+		return strings.TrimSpace(record[i]), nil
+	}
+	var err error
+	if row.Barcode, err = field("barcode"); err != nil {
+		return row, err
+	}
+	if row.TitleID, err = field("title_id"); err != nil {
+		return row, err
+	}
+	condition, err := field("condition")
+	if err != nil {
+		return row, err
+	}
+	row.Condition = Condition(strings.ToLower(condition))
+	return row, nil
+}
+
+// Export writes the copies matching f to w in format, ExportCSV or ExportJSONL
+func Export(ctx context.Context, svc Service, w io.Writer, format string, f Filter) error {
+	switch format {
+	case ExportCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(exportColumns); err != nil {
+			return err
+		}
+		err := svc.ExportDVDs(ctx, f, func(c *Copy) error {
+			title := ""
+			if c.Title != nil {
+				title = c.Title.Name
+			}
+			return cw.Write([]string{
+				c.ID,
+				c.TitleID,
+				title,
+				c.Barcode,
+				string(c.Condition),
+				c.Status.ToString(),
+				c.CreatedAt.UTC().Format(time.RFC3339),
+			})
+		})
+		if err != nil {
+			return err
+		}
+		cw.Flush()
+		return cw.Error()
+	case ExportJSONL:
+		enc := json.NewEncoder(w)
+		return svc.ExportDVDs(ctx, f, func(c *Copy) error {
+			return enc.Encode(c)
+		})
+	default:
+		return errInvalidExportFormat
+	}
+}
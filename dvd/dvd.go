@@ -1,7 +1,10 @@
 package dvd
 
 import (
+	"time"
+
 	"github.com/google/uuid"
+	"github.com/ngray1747/dvd-rental/internal/apperr"
 	"github.com/ngray1747/dvd-rental/internal/model"
 )
 
@@ -10,47 +13,207 @@ type Status uint8
 const (
 	Available = iota + 1
 	NotAvailable
+	// Sold is terminal, a sold copy leaves the catalog for good
+	Sold
+	// Reserved copies are held for the first customer of the title's waitlist
+	Reserved
 )
 
-var Statuss = []Status {
+var Statuss = []Status{
 	Available,
 	NotAvailable,
+	Sold,
+	Reserved,
 }
 
+var errInvalidStatus = apperr.New(apperr.InvalidArgument, "invalid dvd status")
+
 func (s Status) ToString() string {
 	switch s {
 	case Available:
 		return "Available"
 	case NotAvailable:
 		return "NotAvailable"
+	case Sold:
+		return "Sold"
+	case Reserved:
+		return "Reserved"
 	default:
 		return "Unknown"
 	}
 }
 
-type DVD struct {
+// CanBecome reports whether a copy in status s may be moved to next:
+// an available copy can be rented or sold, a rented one is returned to the shelf
+// or held for the waitlist, a held one is rented by its holder or released.
+func (s Status) CanBecome(next Status) bool {
+	switch s {
+	case Available:
+		return next == NotAvailable || next == Sold
+	case NotAvailable:
+		return next == Available || next == Reserved
+	case Reserved:
+		return next == NotAvailable || next == Available
+	default:
+		return false
+	}
+}
+
+// ParseStatus returns the status named s, an empty s means no status
+func ParseStatus(s string) (Status, error) {
+	if s == "" {
+		return 0, nil
+	}
+	for _, status := range Statuss {
+		if status.ToString() == s {
+			return status, nil
+		}
+	}
+	return 0, errInvalidStatus
+}
+
+// Condition describes the wear of a physical copy
+type Condition string
+
+const (
+	New  Condition = "new"
+	Good Condition = "good"
+	Fair Condition = "fair"
+	Poor Condition = "poor"
+)
+
+var Conditions = []Condition{
+	New,
+	Good,
+	Fair,
+	Poor,
+}
+
+// IsValid reports whether c is one of the known conditions
+func (c Condition) IsValid() bool {
+	for _, condition := range Conditions {
+		if c == condition {
+			return true
+		}
+	}
+	return false
+}
+
+// resaleRate is the share of the replacement cost, in percent, a copy sells for in each condition
+var resaleRate = map[Condition]int64{
+	New:  100,
+	Good: 75,
+	Fair: 50,
+	Poor: 25,
+}
+
+// Title is a movie of the catalog, rented out through its copies
+type Title struct {
+	model.Base
+	Name    string   `pg:",notnull" json:"name"`
+	Year    int      `json:"year,omitempty"`
+	Runtime int      `json:"runtime,omitempty"` // minutes
+	Rating  string   `json:"rating,omitempty"`
+	Genres  []string `pg:",array" json:"genres,omitempty"`
+	// ReplacementCost is what a lost or damaged copy costs, in cents
+	ReplacementCost int64 `pg:",use_zero" json:"replacement_cost"`
+}
+
+// Copy is a physical disc of a title, the unit being rented
+type Copy struct {
 	model.Base
-	Name string `pg:",notnull"`
-	Status Status 
+	TitleID   string    `pg:",notnull" json:"title_id"`
+	Title     *Title    `json:"title,omitempty"`
+	Barcode   string    `pg:",notnull,unique" json:"barcode"`
+	Condition Condition `pg:",notnull" json:"condition"`
+	Status    Status    `json:"status"`
+	// RentedBy is the customer renting a NotAvailable copy
+	RentedBy string `json:"rented_by,omitempty"`
+	// ReservedFor is the customer a Reserved copy is held for until HeldUntil
+	ReservedFor string    `json:"reserved_for,omitempty"`
+	HeldUntil   time.Time `json:"held_until,omitempty"`
 }
 
 type Repository interface {
-	Store(dvd *DVD) error
-	Update(id string, status Status) error
+	StoreTitle(t *Title) error
+	GetTitle(id string) (*Title, error)
+	// UpdateTitle writes the details of a title read with GetTitle
+	UpdateTitle(t *Title) error
+	Store(c *Copy) error
+	// StoreMany adds copies in a single transaction
+	StoreMany(copies []*Copy) error
+	// TakenBarcodes returns the barcodes among barcodes already used by a copy
+	TakenBarcodes(barcodes []string) ([]string, error)
+	// Get returns a copy along with its title
+	Get(id string) (*Copy, error)
+	// Update sets the status of a copy and returns it
+	Update(id string, status Status) (*Copy, error)
+	// Rent marks the copy id as not available, a reserved copy is only rented to its holder
+	Rent(id, customerID string) (*Copy, error)
+	// RentAnyCopy rents the copy of a title held for the customer, or else any available one
+	RentAnyCopy(titleID, customerID string) (*Copy, error)
+	// Return puts a copy rented by customerID back, holding it for hold when the title's waitlist is not empty
+	Return(id, customerID string, hold time.Duration) (*Copy, error)
+	// Release undoes the rent of a copy by customerID the way Return does, it does nothing when the copy is not rented by them
+	Release(id, customerID string, hold time.Duration) (*Copy, error)
+	// ExpireHolds releases the copies held past their time to the next in line and returns how many
+	ExpireHolds(hold time.Duration) (int, error)
+	// Reserve adds a customer at the end of a title's waitlist
+	Reserve(r *Reservation) error
+	// CancelReservation removes a customer from a title's waitlist, releasing any copy held for them
+	CancelReservation(titleID, customerID string, hold time.Duration) error
+	// ListReservations returns the waitlist of a title, first in line first
+	ListReservations(titleID string) ([]*Reservation, error)
+	// List returns up to limit copies created after the cursor, oldest first
+	List(after *Cursor, limit int) ([]*Copy, error)
+	// Search is List narrowed down by a filter
+	Search(f Filter, after *Cursor, limit int) ([]*Copy, error)
 }
 
-//NewDVD generate a dvd model with input name
-func NewDVD(name string) (*DVD, error) {
+// Price is what the copy sells for, in cents: the replacement cost of its title discounted by wear.
+// It is zero while the title is not loaded.
+func (c *Copy) Price() int64 {
+	if c.Title == nil {
+		return 0
+	}
+	return c.Title.ReplacementCost * resaleRate[c.Condition] / 100
+}
+
+// NewTitle generate a title model
+func NewTitle(name string, year, runtime int, rating string, genres []string, replacementCost int64) (*Title, error) {
 	id, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
 	}
 
-	return &DVD{
+	return &Title{
 		Base: model.Base{
 			ID: id.String(),
 		},
 		Name:    name,
-		Status: Available,
+		Year:    year,
+		Runtime: runtime,
+		Rating:  rating,
+		Genres:  genres,
+
+		ReplacementCost: replacementCost,
 	}, nil
-}
\ No newline at end of file
+}
+
+// NewCopy generate an available copy of a title
+func NewCopy(titleID, barcode string, condition Condition) (*Copy, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Copy{
+		Base: model.Base{
+			ID: id.String(),
+		},
+		TitleID:   titleID,
+		Barcode:   barcode,
+		Condition: condition,
+		Status:    Available,
+	}, nil
+}
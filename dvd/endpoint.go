@@ -2,98 +2,629 @@ package dvd
 
 import (
 	"context"
-	"time"
+	"io"
+	"net/http"
+	"strconv"
 
-	"github.com/go-kit/kit/circuitbreaker"
 	"github.com/go-kit/kit/endpoint"
-	"github.com/go-kit/kit/ratelimit"
 	"github.com/go-kit/kit/tracing/opentracing"
+	"github.com/ngray1747/dvd-rental/internal/idempotency"
+	"github.com/ngray1747/dvd-rental/internal/limits"
 	stdopentracing "github.com/opentracing/opentracing-go"
-	"github.com/sony/gobreaker"
-	"golang.org/x/time/rate"
 )
 
+type CreateTitleRequest struct {
+	Name    string   `json:"name"`
+	Year    int      `json:"year"`
+	Runtime int      `json:"runtime"`
+	Rating  string   `json:"rating"`
+	Genres  []string `json:"genres"`
+
+	ReplacementCost int64 `json:"replacement_cost"`
+}
+
+type CreateTitleResponse struct {
+	Title *Title `json:"title,omitempty"`
+	Err   error  `json:"error,omitempty"`
+}
+
+func (r CreateTitleResponse) Failed() error {
+	return r.Err
+}
+
+func makeCreateTitleEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(CreateTitleRequest)
+		t, err := s.CreateTitle(ctx, req.Name, req.Year, req.Runtime, req.Rating, req.Genres, req.ReplacementCost)
+		return CreateTitleResponse{Title: t, Err: err}, nil
+	}
+}
+
+func (ep DVDEndpoints) CreateTitle(ctx context.Context, name string, year, runtime int, rating string, genres []string, replacementCost int64) (*Title, error) {
+	res, err := ep.CreateTitleEndpoint(ctx, CreateTitleRequest{
+		Name:    name,
+		Year:    year,
+		Runtime: runtime,
+		Rating:  rating,
+		Genres:  genres,
+
+		ReplacementCost: replacementCost,
+	})
+	if err != nil {
+		return nil, err
+	}
+	response := res.(CreateTitleResponse)
+	return response.Title, response.Err
+}
+
 type CreateDVDRequest struct {
-	Name string `json:"name"`
+	TitleID   string    `json:"title_id"`
+	Barcode   string    `json:"barcode"`
+	Condition Condition `json:"condition"`
 }
 
 type CreateDVDResponse struct {
-	Err error `json:"error,omitempty"`
+	Copy *Copy `json:"copy,omitempty"`
+	Err  error `json:"error,omitempty"`
 }
 
-func (r CreateDVDResponse) error() error {
+func (r CreateDVDResponse) Failed() error {
 	return r.Err
 }
 
+// StatusCode implements kithttp.StatusCoder, a created copy is a created resource
+func (r CreateDVDResponse) StatusCode() int { return http.StatusCreated }
+
+// Headers implements kithttp.Headerer, pointing to the created copy
+func (r CreateDVDResponse) Headers() http.Header {
+	h := http.Header{}
+	if r.Copy != nil {
+		h.Set("Location", "/dvd/v1/dvds/"+r.Copy.ID)
+	}
+	return h
+}
+
 func makeCreateDVDEndpoint(s Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(CreateDVDRequest)
-		err := s.CreateDVD(ctx, req.Name)
-		return CreateDVDResponse{Err: err}, nil
+		c, err := s.CreateDVD(ctx, req.TitleID, req.Barcode, req.Condition)
+		return CreateDVDResponse{Copy: c, Err: err}, nil
+	}
+}
+
+type GetDVDRequest struct {
+	ID string `json:"id"`
+}
+
+type GetDVDResponse struct {
+	Copy *Copy `json:"copy,omitempty"`
+	Err  error `json:"error,omitempty"`
+}
+
+func (r GetDVDResponse) Failed() error {
+	return r.Err
+}
+
+func (ep DVDEndpoints) GetDVD(ctx context.Context, id string) (*Copy, error) {
+	res, err := ep.GetDVDEndpoint(ctx, GetDVDRequest{ID: id})
+	if err != nil {
+		return nil, err
+	}
+	response := res.(GetDVDResponse)
+	return response.Copy, response.Err
+}
+
+func makeGetDVDEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(GetDVDRequest)
+		c, err := s.GetDVD(ctx, req.ID)
+		return GetDVDResponse{Copy: c, Err: err}, nil
 	}
 }
 
 type DVDEndpoints struct {
-	CreateDVDEndpoint endpoint.Endpoint
-	RentDVDEndpoint   endpoint.Endpoint
+	CreateTitleEndpoint   endpoint.Endpoint
+	CreateDVDEndpoint     endpoint.Endpoint
+	GetDVDEndpoint        endpoint.Endpoint
+	RentDVDEndpoint       endpoint.Endpoint
+	ReturnDVDEndpoint     endpoint.Endpoint
+	ReleaseDVDEndpoint    endpoint.Endpoint
+	SellDVDEndpoint       endpoint.Endpoint
+	JoinWaitlistEndpoint  endpoint.Endpoint
+	LeaveWaitlistEndpoint endpoint.Endpoint
+	ListWaitlistEndpoint  endpoint.Endpoint
+	ListDVDsEndpoint      endpoint.Endpoint
+	ImportDVDsEndpoint    endpoint.Endpoint
+	ImportBatchEndpoint   endpoint.Endpoint
+	ExportDVDsEndpoint    endpoint.Endpoint
 }
 
-func (ep DVDEndpoints) CreateDVD(ctx context.Context, name string) error {
-	res, err := ep.CreateDVDEndpoint(ctx, CreateDVDRequest{Name: name})
+func (ep DVDEndpoints) CreateDVD(ctx context.Context, titleID, barcode string, condition Condition) (*Copy, error) {
+	res, err := ep.CreateDVDEndpoint(ctx, CreateDVDRequest{TitleID: titleID, Barcode: barcode, Condition: condition})
 	if err != nil {
-		return err
+		return nil, err
 	}
 	response := res.(CreateDVDResponse)
-	return response.Err
-
+	return response.Copy, response.Err
 }
 
 type RentDVDRequest struct {
-	ID string `json:"id"`
+	ID         string `json:"id"`
+	TitleID    string `json:"title_id"`
+	CustomerID string `json:"customer_id"`
 }
 
 type RentDVDResponse struct {
-	Err error `json:"error,omitempty"`
+	Copy *Copy `json:"copy,omitempty"`
+	Err  error `json:"error,omitempty"`
 }
 
-func (r RentDVDResponse) error() error {
+func (r RentDVDResponse) Failed() error {
 	return r.Err
 }
 
-func (ep DVDEndpoints) RentDVD(ctx context.Context, id string) error {
-	res, err := ep.RentDVDEndpoint(ctx, RentDVDRequest{ID: id})
+func (ep DVDEndpoints) RentDVD(ctx context.Context, id, titleID, customerID string) (*Copy, error) {
+	res, err := ep.RentDVDEndpoint(ctx, RentDVDRequest{ID: id, TitleID: titleID, CustomerID: customerID})
 	if err != nil {
-		return err
+		return nil, err
 	}
 	response := res.(RentDVDResponse)
-	return response.Err
+	return response.Copy, response.Err
 }
 
 func makeRentDVDEndpoint(s Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(RentDVDRequest)
-		err := s.RentDVD(ctx, req.ID)
-		return RentDVDResponse{Err: err}, nil
+		c, err := s.RentDVD(ctx, req.ID, req.TitleID, req.CustomerID)
+		return RentDVDResponse{Copy: c, Err: err}, nil
 	}
 }
-func NewDVDEndpoint(svc Service, ot stdopentracing.Tracer) DVDEndpoints {
+
+type ReturnDVDRequest struct {
+	ID         string `json:"id"`
+	CustomerID string `json:"customer_id"`
+}
+
+type ReturnDVDResponse struct {
+	Copy *Copy `json:"copy,omitempty"`
+	Err  error `json:"error,omitempty"`
+}
+
+func (r ReturnDVDResponse) Failed() error {
+	return r.Err
+}
+
+func (ep DVDEndpoints) ReturnDVD(ctx context.Context, id, customerID string) (*Copy, error) {
+	res, err := ep.ReturnDVDEndpoint(ctx, ReturnDVDRequest{ID: id, CustomerID: customerID})
+	if err != nil {
+		return nil, err
+	}
+	response := res.(ReturnDVDResponse)
+	return response.Copy, response.Err
+}
+
+func makeReturnDVDEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ReturnDVDRequest)
+		c, err := s.ReturnDVD(ctx, req.ID, req.CustomerID)
+		return ReturnDVDResponse{Copy: c, Err: err}, nil
+	}
+}
+
+type ReleaseDVDRequest struct {
+	ID         string `json:"id"`
+	CustomerID string `json:"customer_id"`
+}
+
+type ReleaseDVDResponse struct {
+	Copy *Copy `json:"copy,omitempty"`
+	Err  error `json:"error,omitempty"`
+}
+
+func (r ReleaseDVDResponse) Failed() error {
+	return r.Err
+}
+
+func (ep DVDEndpoints) ReleaseDVD(ctx context.Context, id, customerID string) (*Copy, error) {
+	res, err := ep.ReleaseDVDEndpoint(ctx, ReleaseDVDRequest{ID: id, CustomerID: customerID})
+	if err != nil {
+		return nil, err
+	}
+	response := res.(ReleaseDVDResponse)
+	return response.Copy, response.Err
+}
+
+func makeReleaseDVDEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ReleaseDVDRequest)
+		c, err := s.ReleaseDVD(ctx, req.ID, req.CustomerID)
+		return ReleaseDVDResponse{Copy: c, Err: err}, nil
+	}
+}
+
+type SellDVDRequest struct {
+	ID string `json:"id"`
+}
+
+type SellDVDResponse struct {
+	Copy *Copy `json:"copy,omitempty"`
+	Err  error `json:"error,omitempty"`
+}
+
+func (r SellDVDResponse) Failed() error {
+	return r.Err
+}
+
+func (ep DVDEndpoints) SellDVD(ctx context.Context, id string) (*Copy, error) {
+	res, err := ep.SellDVDEndpoint(ctx, SellDVDRequest{ID: id})
+	if err != nil {
+		return nil, err
+	}
+	response := res.(SellDVDResponse)
+	return response.Copy, response.Err
+}
+
+func makeSellDVDEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(SellDVDRequest)
+		c, err := s.SellDVD(ctx, req.ID)
+		return SellDVDResponse{Copy: c, Err: err}, nil
+	}
+}
+
+type JoinWaitlistRequest struct {
+	TitleID    string `json:"title_id"`
+	CustomerID string `json:"customer_id"`
+}
+
+type JoinWaitlistResponse struct {
+	Reservation *Reservation `json:"reservation,omitempty"`
+	Err         error        `json:"error,omitempty"`
+}
+
+func (r JoinWaitlistResponse) Failed() error {
+	return r.Err
+}
+
+func (ep DVDEndpoints) JoinWaitlist(ctx context.Context, titleID, customerID string) (*Reservation, error) {
+	res, err := ep.JoinWaitlistEndpoint(ctx, JoinWaitlistRequest{TitleID: titleID, CustomerID: customerID})
+	if err != nil {
+		return nil, err
+	}
+	response := res.(JoinWaitlistResponse)
+	return response.Reservation, response.Err
+}
+
+func makeJoinWaitlistEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(JoinWaitlistRequest)
+		r, err := s.JoinWaitlist(ctx, req.TitleID, req.CustomerID)
+		return JoinWaitlistResponse{Reservation: r, Err: err}, nil
+	}
+}
+
+type LeaveWaitlistRequest struct {
+	TitleID    string `json:"title_id"`
+	CustomerID string `json:"customer_id"`
+}
+
+type LeaveWaitlistResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r LeaveWaitlistResponse) Failed() error {
+	return r.Err
+}
+
+func (ep DVDEndpoints) LeaveWaitlist(ctx context.Context, titleID, customerID string) error {
+	res, err := ep.LeaveWaitlistEndpoint(ctx, LeaveWaitlistRequest{TitleID: titleID, CustomerID: customerID})
+	if err != nil {
+		return err
+	}
+	response := res.(LeaveWaitlistResponse)
+	return response.Err
+}
+
+func makeLeaveWaitlistEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(LeaveWaitlistRequest)
+		err := s.LeaveWaitlist(ctx, req.TitleID, req.CustomerID)
+		return LeaveWaitlistResponse{Err: err}, nil
+	}
+}
+
+type ListWaitlistRequest struct {
+	TitleID string `json:"title_id"`
+}
+
+type ListWaitlistResponse struct {
+	Reservations []*Reservation `json:"reservations,omitempty"`
+	Err          error          `json:"error,omitempty"`
+}
+
+func (r ListWaitlistResponse) Failed() error {
+	return r.Err
+}
+
+func (ep DVDEndpoints) ListWaitlist(ctx context.Context, titleID string) ([]*Reservation, error) {
+	res, err := ep.ListWaitlistEndpoint(ctx, ListWaitlistRequest{TitleID: titleID})
+	if err != nil {
+		return nil, err
+	}
+	response := res.(ListWaitlistResponse)
+	return response.Reservations, response.Err
+}
+
+func makeListWaitlistEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ListWaitlistRequest)
+		r, err := s.ListWaitlist(ctx, req.TitleID)
+		return ListWaitlistResponse{Reservations: r, Err: err}, nil
+	}
+}
+
+type ListDVDsRequest struct {
+	Filter Filter `json:"filter"`
+	Cursor string `json:"cursor"`
+	Limit  int    `json:"limit"`
+}
+
+type ListDVDsResponse struct {
+	Page *Page `json:"page,omitempty"`
+	Err  error `json:"error,omitempty"`
+}
+
+func (r ListDVDsResponse) Failed() error {
+	return r.Err
+}
+
+func (ep DVDEndpoints) ListDVDs(ctx context.Context, cursor string, limit int) (*Page, error) {
+	return ep.SearchDVDs(ctx, Filter{}, cursor, limit)
+}
+
+func (ep DVDEndpoints) SearchDVDs(ctx context.Context, f Filter, cursor string, limit int) (*Page, error) {
+	res, err := ep.ListDVDsEndpoint(ctx, ListDVDsRequest{Filter: f, Cursor: cursor, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	response := res.(ListDVDsResponse)
+	return response.Page, response.Err
+}
+
+func makeListDVDsEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ListDVDsRequest)
+		var (
+			page *Page
+			err  error
+		)
+		if req.Filter.IsZero() {
+			page, err = s.ListDVDs(ctx, req.Cursor, req.Limit)
+		} else {
+			page, err = s.SearchDVDs(ctx, req.Filter, req.Cursor, req.Limit)
+		}
+		return ListDVDsResponse{Page: page, Err: err}, nil
+	}
+}
+
+// ImportDVDsRequest streams an import file from File, the reader of the transport
+type ImportDVDsRequest struct {
+	File io.Reader `json:"-"`
+}
+
+type ImportDVDsResponse struct {
+	Result *ImportResult `json:"result,omitempty"`
+	Err    error         `json:"error,omitempty"`
+}
+
+func (r ImportDVDsResponse) Failed() error {
+	return r.Err
+}
+
+func makeImportDVDsEndpoint(imp Importer) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ImportDVDsRequest)
+		res, err := ImportCSV(ctx, imp, req.File, defaultImportBatchSize)
+		return ImportDVDsResponse{Result: res, Err: err}, nil
+	}
+}
+
+// ImportBatchRequest is a batch of the rows of an import
+type ImportBatchRequest struct {
+	Rows []ImportRow `json:"rows"`
+}
+
+type ImportBatchResponse struct {
+	Result *ImportResult `json:"result,omitempty"`
+	Err    error         `json:"error,omitempty"`
+}
+
+func (r ImportBatchResponse) Failed() error {
+	return r.Err
+}
+
+// ImportDVDs imports a batch of rows through ImportBatchEndpoint. Each batch is made idempotent on its own, keyed
+// by the key of the import and the row the batch starts at, so a retried import replays the batches already done.
+func (ep DVDEndpoints) ImportDVDs(ctx context.Context, rows []ImportRow) (*ImportResult, error) {
+	if key := idempotency.FromContext(ctx); key != "" && len(rows) > 0 {
+		ctx = idempotency.NewContext(ctx, key+":"+strconv.Itoa(rows[0].Row))
+	}
+	res, err := ep.ImportBatchEndpoint(ctx, ImportBatchRequest{Rows: rows})
+	if err != nil {
+		return nil, err
+	}
+	response := res.(ImportBatchResponse)
+	return response.Result, response.Err
+}
+
+func makeImportBatchEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ImportBatchRequest)
+		res, err := s.ImportDVDs(ctx, req.Rows)
+		return ImportBatchResponse{Result: res, Err: err}, nil
+	}
+}
+
+// ExportDVDsRequest streams the copies matching Filter to W, the writer of the transport
+type ExportDVDsRequest struct {
+	Format string    `json:"format"`
+	Filter Filter    `json:"filter"`
+	W      io.Writer `json:"-"`
+}
+
+type ExportDVDsResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r ExportDVDsResponse) Failed() error {
+	return r.Err
+}
+
+func makeExportDVDsEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ExportDVDsRequest)
+		err := Export(ctx, s, req.W, req.Format, req.Filter)
+		return ExportDVDsResponse{Err: err}, nil
+	}
+}
+
+// NewDVDEndpoint wraps all dvd service with all middlewares, calls changing state are made idempotent by keeper
+// and every call is rate limited and circuit broken by guard
+func NewDVDEndpoint(svc Service, ot stdopentracing.Tracer, keeper *idempotency.Keeper, guard *limits.Guard) DVDEndpoints {
+	var createTitleEndpoint endpoint.Endpoint
+	{
+		createTitleEndpoint = makeCreateTitleEndpoint(svc)
+		createTitleEndpoint = guard.Limit()(createTitleEndpoint)
+		createTitleEndpoint = guard.Break("create_title")(createTitleEndpoint)
+		createTitleEndpoint = opentracing.TraceServer(ot, "create_title")(createTitleEndpoint)
+		createTitleEndpoint = keeper.Middleware("createTitle", CreateTitleResponse{})(createTitleEndpoint)
+	}
+
 	var createDVDEndpoint endpoint.Endpoint
 	{
 		createDVDEndpoint = makeCreateDVDEndpoint(svc)
-		createDVDEndpoint = ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Every(time.Second), 1))(createDVDEndpoint)
-		createDVDEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{}))(createDVDEndpoint)
+		createDVDEndpoint = guard.Limit()(createDVDEndpoint)
+		createDVDEndpoint = guard.Break("create_dvd")(createDVDEndpoint)
 		createDVDEndpoint = opentracing.TraceServer(ot, "create_dvd")(createDVDEndpoint)
+		createDVDEndpoint = keeper.Middleware("createDVD", CreateDVDResponse{})(createDVDEndpoint)
+	}
+
+	var getDVDEndpoint endpoint.Endpoint
+	{
+		getDVDEndpoint = makeGetDVDEndpoint(svc)
+		getDVDEndpoint = guard.Limit()(getDVDEndpoint)
+		getDVDEndpoint = guard.Break("get_dvd")(getDVDEndpoint)
+		getDVDEndpoint = opentracing.TraceServer(ot, "get_dvd")(getDVDEndpoint)
 	}
 
 	var rentDVDEndpoint endpoint.Endpoint
 	{
 		rentDVDEndpoint = makeRentDVDEndpoint(svc)
-		rentDVDEndpoint = ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Every(time.Second), 1))(rentDVDEndpoint)
-		rentDVDEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{}))(rentDVDEndpoint)
+		rentDVDEndpoint = guard.Limit()(rentDVDEndpoint)
+		rentDVDEndpoint = guard.Break("rent_dvd")(rentDVDEndpoint)
 		rentDVDEndpoint = opentracing.TraceClient(ot, "rent_dvd")(rentDVDEndpoint)
+		rentDVDEndpoint = keeper.Middleware("rentDVD", RentDVDResponse{})(rentDVDEndpoint)
+	}
+
+	var returnDVDEndpoint endpoint.Endpoint
+	{
+		returnDVDEndpoint = makeReturnDVDEndpoint(svc)
+		returnDVDEndpoint = guard.Limit()(returnDVDEndpoint)
+		returnDVDEndpoint = guard.Break("return_dvd")(returnDVDEndpoint)
+		returnDVDEndpoint = opentracing.TraceServer(ot, "return_dvd")(returnDVDEndpoint)
+		returnDVDEndpoint = keeper.Middleware("returnDVD", ReturnDVDResponse{})(returnDVDEndpoint)
+	}
+
+	var releaseDVDEndpoint endpoint.Endpoint
+	{
+		releaseDVDEndpoint = makeReleaseDVDEndpoint(svc)
+		releaseDVDEndpoint = guard.Limit()(releaseDVDEndpoint)
+		releaseDVDEndpoint = guard.Break("release_dvd")(releaseDVDEndpoint)
+		releaseDVDEndpoint = opentracing.TraceServer(ot, "release_dvd")(releaseDVDEndpoint)
+		releaseDVDEndpoint = keeper.Middleware("releaseDVD", ReleaseDVDResponse{})(releaseDVDEndpoint)
+	}
+
+	var sellDVDEndpoint endpoint.Endpoint
+	{
+		sellDVDEndpoint = makeSellDVDEndpoint(svc)
+		sellDVDEndpoint = guard.Limit()(sellDVDEndpoint)
+		sellDVDEndpoint = guard.Break("sell_dvd")(sellDVDEndpoint)
+		sellDVDEndpoint = opentracing.TraceServer(ot, "sell_dvd")(sellDVDEndpoint)
+		sellDVDEndpoint = keeper.Middleware("sellDVD", SellDVDResponse{})(sellDVDEndpoint)
+	}
+
+	var joinWaitlistEndpoint endpoint.Endpoint
+	{
+		joinWaitlistEndpoint = makeJoinWaitlistEndpoint(svc)
+		joinWaitlistEndpoint = guard.Limit()(joinWaitlistEndpoint)
+		joinWaitlistEndpoint = guard.Break("join_waitlist")(joinWaitlistEndpoint)
+		joinWaitlistEndpoint = opentracing.TraceServer(ot, "join_waitlist")(joinWaitlistEndpoint)
+		joinWaitlistEndpoint = keeper.Middleware("joinWaitlist", JoinWaitlistResponse{})(joinWaitlistEndpoint)
+	}
+
+	var leaveWaitlistEndpoint endpoint.Endpoint
+	{
+		leaveWaitlistEndpoint = makeLeaveWaitlistEndpoint(svc)
+		leaveWaitlistEndpoint = guard.Limit()(leaveWaitlistEndpoint)
+		leaveWaitlistEndpoint = guard.Break("leave_waitlist")(leaveWaitlistEndpoint)
+		leaveWaitlistEndpoint = opentracing.TraceServer(ot, "leave_waitlist")(leaveWaitlistEndpoint)
+		leaveWaitlistEndpoint = keeper.Middleware("leaveWaitlist", LeaveWaitlistResponse{})(leaveWaitlistEndpoint)
+	}
+
+	var listWaitlistEndpoint endpoint.Endpoint
+	{
+		listWaitlistEndpoint = makeListWaitlistEndpoint(svc)
+		listWaitlistEndpoint = guard.Limit()(listWaitlistEndpoint)
+		listWaitlistEndpoint = guard.Break("list_waitlist")(listWaitlistEndpoint)
+		listWaitlistEndpoint = opentracing.TraceServer(ot, "list_waitlist")(listWaitlistEndpoint)
+	}
+
+	var listDVDsEndpoint endpoint.Endpoint
+	{
+		listDVDsEndpoint = makeListDVDsEndpoint(svc)
+		listDVDsEndpoint = guard.Limit()(listDVDsEndpoint)
+		listDVDsEndpoint = guard.Break("list_dvds")(listDVDsEndpoint)
+		listDVDsEndpoint = opentracing.TraceServer(ot, "list_dvds")(listDVDsEndpoint)
+	}
+
+	//* An import is rate limited and circuit broken as a whole, its batches are made idempotent one by one
+	var importBatchEndpoint endpoint.Endpoint
+	{
+		importBatchEndpoint = makeImportBatchEndpoint(svc)
+		importBatchEndpoint = opentracing.TraceServer(ot, "import_batch")(importBatchEndpoint)
+		importBatchEndpoint = keeper.Middleware("importBatch", ImportBatchResponse{})(importBatchEndpoint)
+	}
+
+	var importDVDsEndpoint endpoint.Endpoint
+	{
+		importDVDsEndpoint = makeImportDVDsEndpoint(DVDEndpoints{ImportBatchEndpoint: importBatchEndpoint})
+		importDVDsEndpoint = guard.Limit()(importDVDsEndpoint)
+		importDVDsEndpoint = guard.Break("import_dvds")(importDVDsEndpoint)
+		importDVDsEndpoint = opentracing.TraceServer(ot, "import_dvds")(importDVDsEndpoint)
+	}
+
+	var exportDVDsEndpoint endpoint.Endpoint
+	{
+		exportDVDsEndpoint = makeExportDVDsEndpoint(svc)
+		exportDVDsEndpoint = guard.Limit()(exportDVDsEndpoint)
+		exportDVDsEndpoint = guard.Break("export_dvds")(exportDVDsEndpoint)
+		exportDVDsEndpoint = opentracing.TraceServer(ot, "export_dvds")(exportDVDsEndpoint)
 	}
 	return DVDEndpoints{
-		CreateDVDEndpoint: createDVDEndpoint,
-		RentDVDEndpoint: rentDVDEndpoint,
+		CreateTitleEndpoint: createTitleEndpoint,
+		CreateDVDEndpoint:   createDVDEndpoint,
+		GetDVDEndpoint:      getDVDEndpoint,
+		RentDVDEndpoint:     rentDVDEndpoint,
+		ReturnDVDEndpoint:   returnDVDEndpoint,
+		ReleaseDVDEndpoint:  releaseDVDEndpoint,
+		SellDVDEndpoint:     sellDVDEndpoint,
+
+		JoinWaitlistEndpoint:  joinWaitlistEndpoint,
+		LeaveWaitlistEndpoint: leaveWaitlistEndpoint,
+		ListWaitlistEndpoint:  listWaitlistEndpoint,
+		ListDVDsEndpoint:      listDVDsEndpoint,
+		ImportDVDsEndpoint:    importDVDsEndpoint,
+		ImportBatchEndpoint:   importBatchEndpoint,
+		ExportDVDsEndpoint:    exportDVDsEndpoint,
 	}
 }
@@ -1,69 +1,404 @@
 package dvd
 
 import (
+	"bufio"
 	"context"
 
+	"github.com/go-kit/kit/endpoint"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/tracing/opentracing"
 	"github.com/go-kit/kit/transport"
 	grpctransport "github.com/go-kit/kit/transport/grpc"
 	"github.com/ngray1747/dvd-rental/dvd/pb"
+	"github.com/ngray1747/dvd-rental/internal/apperr"
+	"github.com/ngray1747/dvd-rental/internal/idempotency"
 	stdopentracing "github.com/opentracing/opentracing-go"
+	"google.golang.org/grpc/metadata"
 )
 
 type grpcServer struct {
-	createDVD grpctransport.Handler
-	rentDVD   grpctransport.Handler
+	createTitle   grpctransport.Handler
+	createDVD     grpctransport.Handler
+	rentDVD       grpctransport.Handler
+	returnDVD     grpctransport.Handler
+	releaseDVD    grpctransport.Handler
+	sellDVD       grpctransport.Handler
+	joinWaitlist  grpctransport.Handler
+	leaveWaitlist grpctransport.Handler
+	listWaitlist  grpctransport.Handler
+	listDVDs      grpctransport.Handler
+	// importDVDs and exportDVDs serve the streaming calls, which go-kit gRPC handlers do not support
+	importDVDs endpoint.Endpoint
+	exportDVDs endpoint.Endpoint
+	ot         stdopentracing.Tracer
+	logger     log.Logger
+}
+
+// streamContext prepares the context of the streaming call name the way the handlers do,
+// with the idempotency key and the trace of its metadata
+func (g *grpcServer) streamContext(ctx context.Context, name string) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = idempotency.GRPCToContext(ctx, md)
+	return opentracing.GRPCToContext(g.ot, name, g.logger)(ctx, md)
+}
+
+func (g *grpcServer) CreateTitle(ctx context.Context, req *pb.CreateTitleRequest) (*pb.CreateTitleResponse, error) {
+	_, res, err := g.createTitle.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, apperr.ToGRPC(err)
+	}
+	return res.(*pb.CreateTitleResponse), nil
+}
+
+func decodeGRPCCreateTitleRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(*pb.CreateTitleRequest)
+	return CreateTitleRequest{
+		Name:    req.Name,
+		Year:    int(req.Year),
+		Runtime: int(req.Runtime),
+		Rating:  req.Rating,
+		Genres:  req.Genres,
+
+		ReplacementCost: req.ReplacementCost,
+	}, nil
+}
+
+func encodeGRPCCreateTitleResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(CreateTitleResponse)
+	if resp.Err != nil {
+		return nil, resp.Err
+	}
+	return &pb.CreateTitleResponse{Id: resp.Title.ID}, nil
 }
 
 func (g *grpcServer) CreateDVD(ctx context.Context, req *pb.CreateDVDRequest) (*pb.CreateDVDResponse, error) {
 	_, res, err := g.createDVD.ServeGRPC(ctx, req)
 	if err != nil {
-		return nil, err
+		return nil, apperr.ToGRPC(err)
 	}
 	return res.(*pb.CreateDVDResponse), nil
 }
 
 func decodeGRPCCreateDVDRequest(_ context.Context, request interface{}) (interface{}, error) {
 	req := request.(*pb.CreateDVDRequest)
-	return CreateDVDRequest{Name: req.Name}, nil
+	return CreateDVDRequest{
+		TitleID:   req.TitleId,
+		Barcode:   req.Barcode,
+		Condition: Condition(req.Condition),
+	}, nil
 }
 
 func encodeGRPCCreateDVDResponse(_ context.Context, response interface{}) (interface{}, error) {
 	resp := response.(CreateDVDResponse)
-	return &pb.CreateDVDResponse{Err: errToString(resp.Err)}, nil
-}
-
-func errToString(err error) string {
-	if err != nil {
-		return err.Error()
+	if resp.Err != nil {
+		return nil, resp.Err
 	}
-	return ""
+	return &pb.CreateDVDResponse{Id: resp.Copy.ID}, nil
 }
 
 func decodeGRPCRentDVDRequest(_ context.Context, request interface{}) (interface{}, error) {
 	req := request.(*pb.RentDVDRequest)
-	return RentDVDRequest{ID: req.Id}, nil
+	return RentDVDRequest{ID: req.Id, TitleID: req.TitleId, CustomerID: req.CustomerId}, nil
 }
 
 func encodeGRPCRentDVDResponse(_ context.Context, response interface{}) (interface{}, error) {
 	res := response.(RentDVDResponse)
-	return &pb.RentDVDResponse{Err: errToString(res.Err)}, nil
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	return &pb.RentDVDResponse{Id: res.Copy.ID}, nil
 }
 
 func (g *grpcServer) RentDVD(ctx context.Context, req *pb.RentDVDRequest) (*pb.RentDVDResponse, error) {
 	_, res, err := g.rentDVD.ServeGRPC(ctx, req)
 	if err != nil {
-		return nil, err
+		return nil, apperr.ToGRPC(err)
 	}
 	return res.(*pb.RentDVDResponse), nil
 }
 
+func decodeGRPCReturnDVDRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(*pb.ReturnDVDRequest)
+	return ReturnDVDRequest{ID: req.Id, CustomerID: req.CustomerId}, nil
+}
+
+func encodeGRPCReturnDVDResponse(_ context.Context, response interface{}) (interface{}, error) {
+	res := response.(ReturnDVDResponse)
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	return &pb.ReturnDVDResponse{Dvd: toPBDVD(res.Copy)}, nil
+}
+
+func (g *grpcServer) ReturnDVD(ctx context.Context, req *pb.ReturnDVDRequest) (*pb.ReturnDVDResponse, error) {
+	_, res, err := g.returnDVD.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, apperr.ToGRPC(err)
+	}
+	return res.(*pb.ReturnDVDResponse), nil
+}
+
+func decodeGRPCReleaseDVDRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(*pb.ReleaseDVDRequest)
+	return ReleaseDVDRequest{ID: req.Id, CustomerID: req.CustomerId}, nil
+}
+
+func encodeGRPCReleaseDVDResponse(_ context.Context, response interface{}) (interface{}, error) {
+	res := response.(ReleaseDVDResponse)
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	return &pb.ReleaseDVDResponse{Dvd: toPBDVD(res.Copy)}, nil
+}
+
+func (g *grpcServer) ReleaseDVD(ctx context.Context, req *pb.ReleaseDVDRequest) (*pb.ReleaseDVDResponse, error) {
+	_, res, err := g.releaseDVD.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, apperr.ToGRPC(err)
+	}
+	return res.(*pb.ReleaseDVDResponse), nil
+}
+
+func decodeGRPCSellDVDRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(*pb.SellDVDRequest)
+	return SellDVDRequest{ID: req.Id}, nil
+}
+
+func encodeGRPCSellDVDResponse(_ context.Context, response interface{}) (interface{}, error) {
+	res := response.(SellDVDResponse)
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	return &pb.SellDVDResponse{Dvd: toPBDVD(res.Copy)}, nil
+}
+
+func (g *grpcServer) SellDVD(ctx context.Context, req *pb.SellDVDRequest) (*pb.SellDVDResponse, error) {
+	_, res, err := g.sellDVD.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, apperr.ToGRPC(err)
+	}
+	return res.(*pb.SellDVDResponse), nil
+}
+
+func decodeGRPCJoinWaitlistRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(*pb.JoinWaitlistRequest)
+	return JoinWaitlistRequest{TitleID: req.TitleId, CustomerID: req.CustomerId}, nil
+}
+
+func encodeGRPCJoinWaitlistResponse(_ context.Context, response interface{}) (interface{}, error) {
+	res := response.(JoinWaitlistResponse)
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	return &pb.JoinWaitlistResponse{Reservation: toPBReservation(res.Reservation)}, nil
+}
+
+func (g *grpcServer) JoinWaitlist(ctx context.Context, req *pb.JoinWaitlistRequest) (*pb.JoinWaitlistResponse, error) {
+	_, res, err := g.joinWaitlist.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, apperr.ToGRPC(err)
+	}
+	return res.(*pb.JoinWaitlistResponse), nil
+}
+
+func decodeGRPCLeaveWaitlistRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(*pb.LeaveWaitlistRequest)
+	return LeaveWaitlistRequest{TitleID: req.TitleId, CustomerID: req.CustomerId}, nil
+}
+
+func encodeGRPCLeaveWaitlistResponse(_ context.Context, response interface{}) (interface{}, error) {
+	res := response.(LeaveWaitlistResponse)
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	return &pb.LeaveWaitlistResponse{}, nil
+}
+
+func (g *grpcServer) LeaveWaitlist(ctx context.Context, req *pb.LeaveWaitlistRequest) (*pb.LeaveWaitlistResponse, error) {
+	_, res, err := g.leaveWaitlist.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, apperr.ToGRPC(err)
+	}
+	return res.(*pb.LeaveWaitlistResponse), nil
+}
+
+func decodeGRPCListWaitlistRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(*pb.ListWaitlistRequest)
+	return ListWaitlistRequest{TitleID: req.TitleId}, nil
+}
+
+func encodeGRPCListWaitlistResponse(_ context.Context, response interface{}) (interface{}, error) {
+	res := response.(ListWaitlistResponse)
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	reservations := make([]*pb.Reservation, 0, len(res.Reservations))
+	for _, r := range res.Reservations {
+		reservations = append(reservations, toPBReservation(r))
+	}
+	return &pb.ListWaitlistResponse{Reservations: reservations}, nil
+}
+
+func (g *grpcServer) ListWaitlist(ctx context.Context, req *pb.ListWaitlistRequest) (*pb.ListWaitlistResponse, error) {
+	_, res, err := g.listWaitlist.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, apperr.ToGRPC(err)
+	}
+	return res.(*pb.ListWaitlistResponse), nil
+}
+
+func toPBReservation(r *Reservation) *pb.Reservation {
+	return &pb.Reservation{
+		Id:         r.ID,
+		TitleId:    r.TitleID,
+		CustomerId: r.CustomerID,
+		CreatedAt:  r.CreatedAt.Unix(),
+	}
+}
+
+func decodeGRPCListDVDsRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(*pb.ListDVDsRequest)
+	status, err := ParseStatus(req.Status)
+	if err != nil {
+		return nil, err
+	}
+	return ListDVDsRequest{
+		Filter: Filter{Name: req.Name, TitleID: req.TitleId, Status: status},
+		Cursor: req.Cursor,
+		Limit:  int(req.Limit),
+	}, nil
+}
+
+func encodeGRPCListDVDsResponse(_ context.Context, response interface{}) (interface{}, error) {
+	res := response.(ListDVDsResponse)
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	dvds := make([]*pb.DVD, 0, len(res.Page.Copies))
+	for _, c := range res.Page.Copies {
+		dvds = append(dvds, toPBDVD(c))
+	}
+	return &pb.ListDVDsResponse{Dvds: dvds, NextCursor: res.Page.NextCursor}, nil
+}
+
+func toPBDVD(c *Copy) *pb.DVD {
+	d := &pb.DVD{
+		Id:        c.ID,
+		Status:    c.Status.ToString(),
+		CreatedAt: c.CreatedAt.Unix(),
+		TitleId:   c.TitleID,
+		Barcode:   c.Barcode,
+		Condition: string(c.Condition),
+
+		ReservedFor: c.ReservedFor,
+	}
+	if !c.HeldUntil.IsZero() {
+		d.HeldUntil = c.HeldUntil.Unix()
+	}
+	if c.Title != nil {
+		d.Name = c.Title.Name
+		d.ReplacementCost = c.Title.ReplacementCost
+		d.Price = c.Price()
+	}
+	return d
+}
+
+func (g *grpcServer) ListDVDs(ctx context.Context, req *pb.ListDVDsRequest) (*pb.ListDVDsResponse, error) {
+	_, res, err := g.listDVDs.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, apperr.ToGRPC(err)
+	}
+	return res.(*pb.ListDVDsResponse), nil
+}
+
+// exportChunkSize is the size of the chunks an export is streamed in
+const exportChunkSize = 32 << 10
+
+func (g *grpcServer) ImportDVDs(stream pb.DVDRental_ImportDVDsServer) error {
+	res, err := g.importDVDs(g.streamContext(stream.Context(), "import DVDs"), ImportDVDsRequest{File: &importStreamReader{stream: stream}})
+	if err != nil {
+		return apperr.ToGRPC(err)
+	}
+	resp := res.(ImportDVDsResponse)
+	if resp.Err != nil {
+		return apperr.ToGRPC(resp.Err)
+	}
+	errs := make([]*pb.ImportError, 0, len(resp.Result.Errors))
+	for _, e := range resp.Result.Errors {
+		errs = append(errs, &pb.ImportError{Row: int32(e.Row), Barcode: e.Barcode, Message: e.Message})
+	}
+	return stream.SendAndClose(&pb.ImportDVDsResponse{Imported: int32(resp.Result.Imported), Errors: errs})
+}
+
+// importStreamReader reads the chunks of an import stream as a single file
+type importStreamReader struct {
+	stream pb.DVDRental_ImportDVDsServer
+	chunk  []byte
+}
+
+func (r *importStreamReader) Read(p []byte) (int, error) {
+	for len(r.chunk) == 0 {
+		req, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		r.chunk = req.Chunk
+	}
+	n := copy(p, r.chunk)
+	r.chunk = r.chunk[n:]
+	return n, nil
+}
+
+func (g *grpcServer) ExportDVDs(req *pb.ExportDVDsRequest, stream pb.DVDRental_ExportDVDsServer) error {
+	status, err := ParseStatus(req.Status)
+	if err != nil {
+		return apperr.ToGRPC(err)
+	}
+	w := bufio.NewWriterSize(exportStreamWriter{stream}, exportChunkSize)
+	res, err := g.exportDVDs(g.streamContext(stream.Context(), "export DVDs"), ExportDVDsRequest{
+		Format: req.Format,
+		Filter: Filter{Name: req.Name, TitleID: req.TitleId, Status: status},
+		W:      w,
+	})
+	if err != nil {
+		return apperr.ToGRPC(err)
+	}
+	if err := res.(ExportDVDsResponse).Err; err != nil {
+		return apperr.ToGRPC(err)
+	}
+	if err := w.Flush(); err != nil {
+		return apperr.ToGRPC(err)
+	}
+	return nil
+}
+
+// exportStreamWriter sends every write as a chunk of an export stream
+type exportStreamWriter struct {
+	stream pb.DVDRental_ExportDVDsServer
+}
+
+func (w exportStreamWriter) Write(p []byte) (int, error) {
+	//* The message is marshalled by Send, p may be reused once it returns
+	if err := w.stream.Send(&pb.ExportDVDsResponse{Chunk: p}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// NewGRPCServer serves endpoints over gRPC
 func NewGRPCServer(endpoints DVDEndpoints, ot stdopentracing.Tracer, logger log.Logger) pb.DVDRentalServer {
 	opts := []grpctransport.ServerOption{
 		grpctransport.ServerErrorHandler(transport.NewLogErrorHandler(logger)),
+		grpctransport.ServerBefore(idempotency.GRPCToContext),
 	}
 
+	createTitleHandler := grpctransport.NewServer(
+		endpoints.CreateTitleEndpoint,
+		decodeGRPCCreateTitleRequest,
+		encodeGRPCCreateTitleResponse,
+		append(opts, grpctransport.ServerBefore(opentracing.GRPCToContext(ot, "create title", logger)))...,
+	)
+
 	createDVDHandler := grpctransport.NewServer(
 		endpoints.CreateDVDEndpoint,
 		decodeGRPCCreateDVDRequest,
@@ -77,9 +412,70 @@ func NewGRPCServer(endpoints DVDEndpoints, ot stdopentracing.Tracer, logger log.
 		encodeGRPCRentDVDResponse,
 		append(opts, grpctransport.ServerBefore(opentracing.GRPCToContext(ot, "rent DVD", logger)))...,
 	)
-	
+
+	returnDVDHandler := grpctransport.NewServer(
+		endpoints.ReturnDVDEndpoint,
+		decodeGRPCReturnDVDRequest,
+		encodeGRPCReturnDVDResponse,
+		append(opts, grpctransport.ServerBefore(opentracing.GRPCToContext(ot, "return DVD", logger)))...,
+	)
+
+	releaseDVDHandler := grpctransport.NewServer(
+		endpoints.ReleaseDVDEndpoint,
+		decodeGRPCReleaseDVDRequest,
+		encodeGRPCReleaseDVDResponse,
+		append(opts, grpctransport.ServerBefore(opentracing.GRPCToContext(ot, "release DVD", logger)))...,
+	)
+
+	sellDVDHandler := grpctransport.NewServer(
+		endpoints.SellDVDEndpoint,
+		decodeGRPCSellDVDRequest,
+		encodeGRPCSellDVDResponse,
+		append(opts, grpctransport.ServerBefore(opentracing.GRPCToContext(ot, "sell DVD", logger)))...,
+	)
+
+	joinWaitlistHandler := grpctransport.NewServer(
+		endpoints.JoinWaitlistEndpoint,
+		decodeGRPCJoinWaitlistRequest,
+		encodeGRPCJoinWaitlistResponse,
+		append(opts, grpctransport.ServerBefore(opentracing.GRPCToContext(ot, "join waitlist", logger)))...,
+	)
+
+	leaveWaitlistHandler := grpctransport.NewServer(
+		endpoints.LeaveWaitlistEndpoint,
+		decodeGRPCLeaveWaitlistRequest,
+		encodeGRPCLeaveWaitlistResponse,
+		append(opts, grpctransport.ServerBefore(opentracing.GRPCToContext(ot, "leave waitlist", logger)))...,
+	)
+
+	listWaitlistHandler := grpctransport.NewServer(
+		endpoints.ListWaitlistEndpoint,
+		decodeGRPCListWaitlistRequest,
+		encodeGRPCListWaitlistResponse,
+		append(opts, grpctransport.ServerBefore(opentracing.GRPCToContext(ot, "list waitlist", logger)))...,
+	)
+
+	listDVDsHandler := grpctransport.NewServer(
+		endpoints.ListDVDsEndpoint,
+		decodeGRPCListDVDsRequest,
+		encodeGRPCListDVDsResponse,
+		append(opts, grpctransport.ServerBefore(opentracing.GRPCToContext(ot, "list DVDs", logger)))...,
+	)
+
 	return &grpcServer{
+		createTitleHandler,
 		createDVDHandler,
 		rentDVDHandler,
+		returnDVDHandler,
+		releaseDVDHandler,
+		sellDVDHandler,
+		joinWaitlistHandler,
+		leaveWaitlistHandler,
+		listWaitlistHandler,
+		listDVDsHandler,
+		endpoints.ImportDVDsEndpoint,
+		endpoints.ExportDVDsEndpoint,
+		ot,
+		logger,
 	}
 }
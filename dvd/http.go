@@ -0,0 +1,283 @@
+package dvd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/tracing/opentracing"
+	"github.com/go-kit/kit/transport"
+	kithttp "github.com/go-kit/kit/transport/http"
+	"github.com/gorilla/mux"
+	"github.com/ngray1747/dvd-rental/internal/apperr"
+	"github.com/ngray1747/dvd-rental/internal/idempotency"
+	stdopentracing "github.com/opentracing/opentracing-go"
+)
+
+// maxImportSize bounds the size of an uploaded import file
+const maxImportSize = 64 << 20
+
+var (
+	errBadRoute     = apperr.New(apperr.InvalidArgument, "bad route")
+	errInvalidLimit = apperr.New(apperr.InvalidArgument, "invalid limit")
+)
+
+func decodeCreateDVDRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req CreateDVDRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, apperr.Errorf(apperr.InvalidArgument, "invalid request body: %v", err)
+	}
+	return req, nil
+}
+
+func decodeRentDVDRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req RentDVDRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, apperr.Errorf(apperr.InvalidArgument, "invalid request body: %v", err)
+	}
+	return req, nil
+}
+
+func decodeGetDVDRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	id, ok := mux.Vars(r)["id"]
+	if !ok {
+		return nil, errBadRoute
+	}
+	return GetDVDRequest{ID: id}, nil
+}
+
+func decodeListDVDsRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	q := r.URL.Query()
+	var limit int
+	if l := q.Get("limit"); l != "" {
+		var err error
+		if limit, err = strconv.Atoi(l); err != nil {
+			return nil, errInvalidLimit
+		}
+	}
+	status, err := ParseStatus(q.Get("status"))
+	if err != nil {
+		return nil, err
+	}
+	return ListDVDsRequest{
+		Filter: Filter{Name: q.Get("name"), TitleID: q.Get("title_id"), Status: status},
+		Cursor: q.Get("cursor"),
+		Limit:  limit,
+	}, nil
+}
+
+func encodeResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	if f, ok := response.(endpoint.Failer); ok && f.Failed() != nil {
+		encodeError(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-type", "application/json; charset=utf-8")
+	if h, ok := response.(kithttp.Headerer); ok {
+		for k, values := range h.Headers() {
+			for _, v := range values {
+				w.Header().Add(k, v)
+			}
+		}
+	}
+	if sc, ok := response.(kithttp.StatusCoder); ok {
+		w.WriteHeader(sc.StatusCode())
+	}
+	return json.NewEncoder(w).Encode(response)
+}
+
+// MakeHandler serves the dvd endpoints as JSON over HTTP, for ad-hoc inspection and admin tools next to gRPC.
+//
+//	POST /dvd/v1/dvds        create a copy of a title
+//	GET  /dvd/v1/dvds        ?name=&title_id=&status=&cursor=&limit=
+//	GET  /dvd/v1/dvds/{id}   get a copy along with its title
+//	POST /dvd/v1/rent        rent the copy id, or any available copy of title_id
+func MakeHandler(endpoints DVDEndpoints, logger log.Logger, ot stdopentracing.Tracer) http.Handler {
+	opts := []kithttp.ServerOption{
+		kithttp.ServerErrorHandler(transport.NewLogErrorHandler(logger)),
+		kithttp.ServerErrorEncoder(encodeError),
+		kithttp.ServerBefore(idempotency.HTTPToContext),
+	}
+
+	createDVDHandler := kithttp.NewServer(
+		endpoints.CreateDVDEndpoint,
+		decodeCreateDVDRequest,
+		encodeResponse,
+		append(opts, kithttp.ServerBefore(opentracing.HTTPToContext(ot, "create DVD", logger)))...,
+	)
+
+	rentDVDHandler := kithttp.NewServer(
+		endpoints.RentDVDEndpoint,
+		decodeRentDVDRequest,
+		encodeResponse,
+		append(opts, kithttp.ServerBefore(opentracing.HTTPToContext(ot, "rent DVD", logger)))...,
+	)
+
+	getDVDHandler := kithttp.NewServer(
+		endpoints.GetDVDEndpoint,
+		decodeGetDVDRequest,
+		encodeResponse,
+		append(opts, kithttp.ServerBefore(opentracing.HTTPToContext(ot, "get DVD", logger)))...,
+	)
+
+	listDVDsHandler := kithttp.NewServer(
+		endpoints.ListDVDsEndpoint,
+		decodeListDVDsRequest,
+		encodeResponse,
+		append(opts, kithttp.ServerBefore(opentracing.HTTPToContext(ot, "list DVDs", logger)))...,
+	)
+
+	r := mux.NewRouter()
+
+	r.Handle("/dvd/v1/dvds", createDVDHandler).Methods("POST")
+	r.Handle("/dvd/v1/dvds", listDVDsHandler).Methods("GET")
+	r.Handle("/dvd/v1/dvds/{id}", getDVDHandler).Methods("GET")
+	r.Handle("/dvd/v1/rent", rentDVDHandler).Methods("POST")
+	return r
+}
+
+// MakeInventoryHandler serves the bulk import and export of copies over HTTP. Both stream their file,
+// an import reads the copies from the request as its endpoint imports them, an export writes them to the response.
+//
+//	POST /dvd/v1/dvds/import   CSV body, or a multipart form with the CSV in its file field
+//	GET  /dvd/v1/dvds/export   ?format=csv|jsonl&name=&title_id=&status=
+func MakeInventoryHandler(endpoints DVDEndpoints, logger log.Logger, ot stdopentracing.Tracer) http.Handler {
+	importDVDsHandler := kithttp.NewServer(
+		endpoints.ImportDVDsEndpoint,
+		decodeImportDVDsRequest,
+		encodeImportDVDsResponse,
+		kithttp.ServerErrorHandler(transport.NewLogErrorHandler(logger)),
+		kithttp.ServerErrorEncoder(encodeError),
+		kithttp.ServerBefore(idempotency.HTTPToContext),
+		kithttp.ServerBefore(opentracing.HTTPToContext(ot, "import DVDs", logger)),
+	)
+
+	r := mux.NewRouter()
+	r.Handle("/dvd/v1/dvds/import", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+		importDVDsHandler.ServeHTTP(w, r)
+	})).Methods("POST")
+	r.Handle("/dvd/v1/dvds/export", exportHandler(endpoints.ExportDVDsEndpoint, logger, ot)).Methods("GET")
+	return r
+}
+
+func decodeImportDVDsRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var body io.Reader = r.Body
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		file, err := formFile(r, "file")
+		if err != nil {
+			return nil, apperr.Errorf(apperr.InvalidArgument, "invalid upload: %v", err)
+		}
+		body = file
+	}
+	return ImportDVDsRequest{File: uploadReader{body}}, nil
+}
+
+// formFile returns the part of a multipart request holding the file field name, reading it streams the request body
+func formFile(r *http.Request, name string) (io.Reader, error) {
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		part, err := mr.NextPart()
+		if err != nil {
+			return nil, err
+		}
+		if part.FormName() == name {
+			return part, nil
+		}
+	}
+}
+
+// uploadReader reports a failure to read an upload, such as one over maxImportSize, as an invalid argument
+type uploadReader struct {
+	r io.Reader
+}
+
+func (u uploadReader) Read(p []byte) (int, error) {
+	n, err := u.r.Read(p)
+	if err != nil && err != io.EOF {
+		err = apperr.Errorf(apperr.InvalidArgument, "invalid upload: %v", err)
+	}
+	return n, err
+}
+
+// encodeImportDVDsResponse answers with the import result alone
+func encodeImportDVDsResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(ImportDVDsResponse)
+	if resp.Err != nil {
+		encodeError(ctx, resp.Err, w)
+		return nil
+	}
+	w.Header().Set("Content-type", "application/json; charset=utf-8")
+	return json.NewEncoder(w).Encode(resp.Result)
+}
+
+func exportHandler(export endpoint.Endpoint, logger log.Logger, ot stdopentracing.Tracer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		format := q.Get("format")
+		if format == "" {
+			format = ExportCSV
+		}
+		contentType := map[string]string{
+			ExportCSV:   "text/csv; charset=utf-8",
+			ExportJSONL: "application/x-ndjson",
+		}[format]
+		if contentType == "" {
+			encodeError(r.Context(), errInvalidExportFormat, w)
+			return
+		}
+		status, err := ParseStatus(q.Get("status"))
+		if err != nil {
+			encodeError(r.Context(), err, w)
+			return
+		}
+
+		ctx := opentracing.HTTPToContext(ot, "export DVDs", logger)(r.Context(), r)
+		w.Header().Set("Content-type", contentType)
+		w.Header().Set("Content-Disposition", `attachment; filename="dvds.`+format+`"`)
+		res := &exportResponseWriter{ResponseWriter: w}
+		resp, err := export(ctx, ExportDVDsRequest{
+			Format: format,
+			Filter: Filter{Name: q.Get("name"), TitleID: q.Get("title_id"), Status: status},
+			W:      res,
+		})
+		if err == nil {
+			err = resp.(ExportDVDsResponse).Err
+		}
+		if err == nil {
+			return
+		}
+		logger.Log("method", "export", "error", err)
+		//* Once streaming started the status can not be changed anymore, a failure cuts the file short
+		if !res.started {
+			w.Header().Del("Content-Disposition")
+			encodeError(ctx, err, w)
+		}
+	})
+}
+
+// exportResponseWriter tells whether an export started writing its file
+type exportResponseWriter struct {
+	http.ResponseWriter
+	started bool
+}
+
+func (w *exportResponseWriter) Write(p []byte) (int, error) {
+	w.started = true
+	return w.ResponseWriter.Write(p)
+}
+
+func encodeError(_ context.Context, err error, w http.ResponseWriter) {
+	w.Header().Set("Content-type", "application/json; charset=utf-8")
+	w.WriteHeader(apperr.HTTPStatus(err))
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": err.Error(),
+	})
+}
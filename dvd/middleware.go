@@ -13,7 +13,7 @@ type Middleware func(Service) Service
 
 type loggerMiddleware struct {
 	logger log.Logger
-	svc Service
+	svc    Service
 }
 
 func NewLoggerMiddleware(logger log.Logger) Middleware {
@@ -22,49 +22,262 @@ func NewLoggerMiddleware(logger log.Logger) Middleware {
 	}
 }
 
-func (lm *loggerMiddleware) CreateDVD(ctx context.Context, name string) (err error) {
+func (lm *loggerMiddleware) CreateTitle(ctx context.Context, name string, year, runtime int, rating string, genres []string, replacementCost int64) (t *Title, err error) {
 	defer func(begin time.Time) {
-		lm.logger.Log("method", "CreateDVD", "request_name", name, "error", err, "took", time.Since(begin))
+		lm.logger.Log("method", "CreateTitle", "request_name", name, "year", year, "error", err, "took", time.Since(begin))
 	}(time.Now())
-	return lm.svc.CreateDVD(ctx, name)
+	return lm.svc.CreateTitle(ctx, name, year, runtime, rating, genres, replacementCost)
 }
 
-func (lm *loggerMiddleware) RentDVD(ctx context.Context, id string) (err error) {
+func (lm *loggerMiddleware) CreateDVD(ctx context.Context, titleID, barcode string, condition Condition) (c *Copy, err error) {
 	defer func(begin time.Time) {
-		lm.logger.Log("method", "RentDVD", "request_name", id, "error", err, "took", time.Since(begin))
+		lm.logger.Log("method", "CreateDVD", "title_id", titleID, "barcode", barcode, "condition", condition, "error", err, "took", time.Since(begin))
 	}(time.Now())
-	return lm.svc.RentDVD(ctx, id)
+	return lm.svc.CreateDVD(ctx, titleID, barcode, condition)
 }
+
+func (lm *loggerMiddleware) GetDVD(ctx context.Context, id string) (c *Copy, err error) {
+	defer func(begin time.Time) {
+		lm.logger.Log("method", "GetDVD", "id", id, "error", err, "took", time.Since(begin))
+	}(time.Now())
+	return lm.svc.GetDVD(ctx, id)
+}
+
+func (lm *loggerMiddleware) RentDVD(ctx context.Context, id, titleID, customerID string) (c *Copy, err error) {
+	defer func(begin time.Time) {
+		lm.logger.Log("method", "RentDVD", "request_name", id, "title_id", titleID, "customer_id", customerID, "error", err, "took", time.Since(begin))
+	}(time.Now())
+	return lm.svc.RentDVD(ctx, id, titleID, customerID)
+}
+
+func (lm *loggerMiddleware) ReturnDVD(ctx context.Context, id, customerID string) (c *Copy, err error) {
+	defer func(begin time.Time) {
+		lm.logger.Log("method", "ReturnDVD", "request_name", id, "customer_id", customerID, "error", err, "took", time.Since(begin))
+	}(time.Now())
+	return lm.svc.ReturnDVD(ctx, id, customerID)
+}
+
+func (lm *loggerMiddleware) ReleaseDVD(ctx context.Context, id, customerID string) (c *Copy, err error) {
+	defer func(begin time.Time) {
+		lm.logger.Log("method", "ReleaseDVD", "request_name", id, "customer_id", customerID, "error", err, "took", time.Since(begin))
+	}(time.Now())
+	return lm.svc.ReleaseDVD(ctx, id, customerID)
+}
+
+func (lm *loggerMiddleware) SellDVD(ctx context.Context, id string) (c *Copy, err error) {
+	defer func(begin time.Time) {
+		lm.logger.Log("method", "SellDVD", "request_name", id, "error", err, "took", time.Since(begin))
+	}(time.Now())
+	return lm.svc.SellDVD(ctx, id)
+}
+
+func (lm *loggerMiddleware) JoinWaitlist(ctx context.Context, titleID, customerID string) (r *Reservation, err error) {
+	defer func(begin time.Time) {
+		lm.logger.Log("method", "JoinWaitlist", "title_id", titleID, "customer_id", customerID, "error", err, "took", time.Since(begin))
+	}(time.Now())
+	return lm.svc.JoinWaitlist(ctx, titleID, customerID)
+}
+
+func (lm *loggerMiddleware) LeaveWaitlist(ctx context.Context, titleID, customerID string) (err error) {
+	defer func(begin time.Time) {
+		lm.logger.Log("method", "LeaveWaitlist", "title_id", titleID, "customer_id", customerID, "error", err, "took", time.Since(begin))
+	}(time.Now())
+	return lm.svc.LeaveWaitlist(ctx, titleID, customerID)
+}
+
+func (lm *loggerMiddleware) ListWaitlist(ctx context.Context, titleID string) (r []*Reservation, err error) {
+	defer func(begin time.Time) {
+		lm.logger.Log("method", "ListWaitlist", "title_id", titleID, "error", err, "took", time.Since(begin))
+	}(time.Now())
+	return lm.svc.ListWaitlist(ctx, titleID)
+}
+
+func (lm *loggerMiddleware) ExpireHolds(ctx context.Context) (n int, err error) {
+	defer func(begin time.Time) {
+		lm.logger.Log("method", "ExpireHolds", "expired", n, "error", err, "took", time.Since(begin))
+	}(time.Now())
+	return lm.svc.ExpireHolds(ctx)
+}
+
+func (lm *loggerMiddleware) ListDVDs(ctx context.Context, cursor string, limit int) (p *Page, err error) {
+	defer func(begin time.Time) {
+		lm.logger.Log("method", "ListDVDs", "cursor", cursor, "limit", limit, "error", err, "took", time.Since(begin))
+	}(time.Now())
+	return lm.svc.ListDVDs(ctx, cursor, limit)
+}
+
+func (lm *loggerMiddleware) SearchDVDs(ctx context.Context, f Filter, cursor string, limit int) (p *Page, err error) {
+	defer func(begin time.Time) {
+		lm.logger.Log("method", "SearchDVDs", "name", f.Name, "status", f.Status.ToString(), "cursor", cursor, "limit", limit, "error", err, "took", time.Since(begin))
+	}(time.Now())
+	return lm.svc.SearchDVDs(ctx, f, cursor, limit)
+}
+
+func (lm *loggerMiddleware) ImportDVDs(ctx context.Context, rows []ImportRow) (r *ImportResult, err error) {
+	defer func(begin time.Time) {
+		imported, failed := 0, 0
+		if r != nil {
+			imported, failed = r.Imported, len(r.Errors)
+		}
+		lm.logger.Log("method", "ImportDVDs", "rows", len(rows), "imported", imported, "failed", failed, "error", err, "took", time.Since(begin))
+	}(time.Now())
+	return lm.svc.ImportDVDs(ctx, rows)
+}
+
+func (lm *loggerMiddleware) ExportDVDs(ctx context.Context, f Filter, each func(*Copy) error) (err error) {
+	defer func(begin time.Time) {
+		lm.logger.Log("method", "ExportDVDs", "name", f.Name, "title_id", f.TitleID, "status", f.Status.ToString(), "error", err, "took", time.Since(begin))
+	}(time.Now())
+	return lm.svc.ExportDVDs(ctx, f, each)
+}
+
 type metricMiddleware struct {
-	counter metrics.Counter
+	counter   metrics.Counter
 	histogram metrics.Histogram
-	svc Service
+	svc       Service
 }
 
 func NewMetrictMiddleware(counter metrics.Counter, histogram metrics.Histogram) Middleware {
 	return func(svc Service) Service {
 		return &metricMiddleware{
-			counter: counter,
+			counter:   counter,
 			histogram: histogram,
-			svc: svc,
+			svc:       svc,
 		}
 	}
 }
 
-func (mw *metricMiddleware) CreateDVD(ctx context.Context, name string) error {
-	err := mw.svc.CreateDVD(ctx, name)
+func (mw *metricMiddleware) CreateTitle(ctx context.Context, name string, year, runtime int, rating string, genres []string, replacementCost int64) (*Title, error) {
+	t, err := mw.svc.CreateTitle(ctx, name, year, runtime, rating, genres, replacementCost)
+	defer func(begin time.Time) {
+		mw.counter.With("method", "CreateTitle").Add(1)
+		mw.histogram.With("method", "CreateTitle", "success", fmt.Sprint(err == nil)).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	return t, err
+}
+
+func (mw *metricMiddleware) CreateDVD(ctx context.Context, titleID, barcode string, condition Condition) (*Copy, error) {
+	d, err := mw.svc.CreateDVD(ctx, titleID, barcode, condition)
 	defer func(begin time.Time) {
 		mw.counter.With("method", "CreateDVD").Add(1)
-		mw.histogram.With("method", "CreateDVD", "success", fmt.Sprint(err!= nil)).Observe(time.Since(begin).Seconds())
+		mw.histogram.With("method", "CreateDVD", "success", fmt.Sprint(err == nil)).Observe(time.Since(begin).Seconds())
 	}(time.Now())
-	return err
+	return d, err
+}
+
+func (mw *metricMiddleware) GetDVD(ctx context.Context, id string) (*Copy, error) {
+	d, err := mw.svc.GetDVD(ctx, id)
+	defer func(begin time.Time) {
+		mw.counter.With("method", "GetDVD").Add(1)
+		mw.histogram.With("method", "GetDVD", "success", fmt.Sprint(err == nil)).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	return d, err
 }
 
-func (mw *metricMiddleware) RentDVD(ctx context.Context, id string) error {
-	err := mw.svc.RentDVD(ctx, id)
+func (mw *metricMiddleware) RentDVD(ctx context.Context, id, titleID, customerID string) (*Copy, error) {
+	c, err := mw.svc.RentDVD(ctx, id, titleID, customerID)
 	defer func(begin time.Time) {
 		mw.counter.With("method", "RentDVD").Add(1)
-		mw.histogram.With("method", "RentDVD", "success", fmt.Sprint(err!= nil)).Observe(time.Since(begin).Seconds())
+		mw.histogram.With("method", "RentDVD", "success", fmt.Sprint(err == nil)).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	return c, err
+}
+
+func (mw *metricMiddleware) ReturnDVD(ctx context.Context, id, customerID string) (*Copy, error) {
+	c, err := mw.svc.ReturnDVD(ctx, id, customerID)
+	defer func(begin time.Time) {
+		mw.counter.With("method", "ReturnDVD").Add(1)
+		mw.histogram.With("method", "ReturnDVD", "success", fmt.Sprint(err == nil)).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	return c, err
+}
+
+func (mw *metricMiddleware) ReleaseDVD(ctx context.Context, id, customerID string) (*Copy, error) {
+	c, err := mw.svc.ReleaseDVD(ctx, id, customerID)
+	defer func(begin time.Time) {
+		mw.counter.With("method", "ReleaseDVD").Add(1)
+		mw.histogram.With("method", "ReleaseDVD", "success", fmt.Sprint(err == nil)).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	return c, err
+}
+
+func (mw *metricMiddleware) SellDVD(ctx context.Context, id string) (*Copy, error) {
+	c, err := mw.svc.SellDVD(ctx, id)
+	defer func(begin time.Time) {
+		mw.counter.With("method", "SellDVD").Add(1)
+		mw.histogram.With("method", "SellDVD", "success", fmt.Sprint(err == nil)).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	return c, err
+}
+
+func (mw *metricMiddleware) JoinWaitlist(ctx context.Context, titleID, customerID string) (*Reservation, error) {
+	r, err := mw.svc.JoinWaitlist(ctx, titleID, customerID)
+	defer func(begin time.Time) {
+		mw.counter.With("method", "JoinWaitlist").Add(1)
+		mw.histogram.With("method", "JoinWaitlist", "success", fmt.Sprint(err == nil)).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	return r, err
+}
+
+func (mw *metricMiddleware) LeaveWaitlist(ctx context.Context, titleID, customerID string) error {
+	err := mw.svc.LeaveWaitlist(ctx, titleID, customerID)
+	defer func(begin time.Time) {
+		mw.counter.With("method", "LeaveWaitlist").Add(1)
+		mw.histogram.With("method", "LeaveWaitlist", "success", fmt.Sprint(err == nil)).Observe(time.Since(begin).Seconds())
 	}(time.Now())
 	return err
-}
\ No newline at end of file
+}
+
+func (mw *metricMiddleware) ListWaitlist(ctx context.Context, titleID string) ([]*Reservation, error) {
+	r, err := mw.svc.ListWaitlist(ctx, titleID)
+	defer func(begin time.Time) {
+		mw.counter.With("method", "ListWaitlist").Add(1)
+		mw.histogram.With("method", "ListWaitlist", "success", fmt.Sprint(err == nil)).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	return r, err
+}
+
+func (mw *metricMiddleware) ExpireHolds(ctx context.Context) (int, error) {
+	n, err := mw.svc.ExpireHolds(ctx)
+	defer func(begin time.Time) {
+		mw.counter.With("method", "ExpireHolds").Add(1)
+		mw.histogram.With("method", "ExpireHolds", "success", fmt.Sprint(err == nil)).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	return n, err
+}
+
+func (mw *metricMiddleware) ListDVDs(ctx context.Context, cursor string, limit int) (*Page, error) {
+	p, err := mw.svc.ListDVDs(ctx, cursor, limit)
+	defer func(begin time.Time) {
+		mw.counter.With("method", "ListDVDs").Add(1)
+		mw.histogram.With("method", "ListDVDs", "success", fmt.Sprint(err == nil)).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	return p, err
+}
+
+func (mw *metricMiddleware) SearchDVDs(ctx context.Context, f Filter, cursor string, limit int) (*Page, error) {
+	p, err := mw.svc.SearchDVDs(ctx, f, cursor, limit)
+	defer func(begin time.Time) {
+		mw.counter.With("method", "SearchDVDs").Add(1)
+		mw.histogram.With("method", "SearchDVDs", "success", fmt.Sprint(err == nil)).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	return p, err
+}
+
+func (mw *metricMiddleware) ImportDVDs(ctx context.Context, rows []ImportRow) (*ImportResult, error) {
+	r, err := mw.svc.ImportDVDs(ctx, rows)
+	defer func(begin time.Time) {
+		mw.counter.With("method", "ImportDVDs").Add(1)
+		mw.histogram.With("method", "ImportDVDs", "success", fmt.Sprint(err == nil)).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	return r, err
+}
+
+func (mw *metricMiddleware) ExportDVDs(ctx context.Context, f Filter, each func(*Copy) error) error {
+	err := mw.svc.ExportDVDs(ctx, f, each)
+	defer func(begin time.Time) {
+		mw.counter.With("method", "ExportDVDs").Add(1)
+		mw.histogram.With("method", "ExportDVDs", "success", fmt.Sprint(err == nil)).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	return err
+}
@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	time "time"
+
 	dvd "github.com/ngray1747/dvd-rental/dvd"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -12,13 +14,13 @@ type Repository struct {
 	mock.Mock
 }
 
-// Store provides a mock function with given fields: _a0
-func (_m *Repository) Store(_a0 *dvd.DVD) error {
-	ret := _m.Called(_a0)
+// CancelReservation provides a mock function with given fields: titleID, customerID, hold
+func (_m *Repository) CancelReservation(titleID string, customerID string, hold time.Duration) error {
+	ret := _m.Called(titleID, customerID, hold)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*dvd.DVD) error); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(string, string, time.Duration) error); ok {
+		r0 = rf(titleID, customerID, hold)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -26,13 +28,343 @@ func (_m *Repository) Store(_a0 *dvd.DVD) error {
 	return r0
 }
 
+// ExpireHolds provides a mock function with given fields: hold
+func (_m *Repository) ExpireHolds(hold time.Duration) (int, error) {
+	ret := _m.Called(hold)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(time.Duration) int); ok {
+		r0 = rf(hold)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(time.Duration) error); ok {
+		r1 = rf(hold)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Get provides a mock function with given fields: id
+func (_m *Repository) Get(id string) (*dvd.Copy, error) {
+	ret := _m.Called(id)
+
+	var r0 *dvd.Copy
+	if rf, ok := ret.Get(0).(func(string) *dvd.Copy); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dvd.Copy)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTitle provides a mock function with given fields: id
+func (_m *Repository) GetTitle(id string) (*dvd.Title, error) {
+	ret := _m.Called(id)
+
+	var r0 *dvd.Title
+	if rf, ok := ret.Get(0).(func(string) *dvd.Title); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dvd.Title)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// List provides a mock function with given fields: after, limit
+func (_m *Repository) List(after *dvd.Cursor, limit int) ([]*dvd.Copy, error) {
+	ret := _m.Called(after, limit)
+
+	var r0 []*dvd.Copy
+	if rf, ok := ret.Get(0).(func(*dvd.Cursor, int) []*dvd.Copy); ok {
+		r0 = rf(after, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*dvd.Copy)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*dvd.Cursor, int) error); ok {
+		r1 = rf(after, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListReservations provides a mock function with given fields: titleID
+func (_m *Repository) ListReservations(titleID string) ([]*dvd.Reservation, error) {
+	ret := _m.Called(titleID)
+
+	var r0 []*dvd.Reservation
+	if rf, ok := ret.Get(0).(func(string) []*dvd.Reservation); ok {
+		r0 = rf(titleID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*dvd.Reservation)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(titleID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Release provides a mock function with given fields: id, customerID, hold
+func (_m *Repository) Release(id string, customerID string, hold time.Duration) (*dvd.Copy, error) {
+	ret := _m.Called(id, customerID, hold)
+
+	var r0 *dvd.Copy
+	if rf, ok := ret.Get(0).(func(string, string, time.Duration) *dvd.Copy); ok {
+		r0 = rf(id, customerID, hold)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dvd.Copy)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, time.Duration) error); ok {
+		r1 = rf(id, customerID, hold)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Rent provides a mock function with given fields: id, customerID
+func (_m *Repository) Rent(id string, customerID string) (*dvd.Copy, error) {
+	ret := _m.Called(id, customerID)
+
+	var r0 *dvd.Copy
+	if rf, ok := ret.Get(0).(func(string, string) *dvd.Copy); ok {
+		r0 = rf(id, customerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dvd.Copy)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(id, customerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RentAnyCopy provides a mock function with given fields: titleID, customerID
+func (_m *Repository) RentAnyCopy(titleID string, customerID string) (*dvd.Copy, error) {
+	ret := _m.Called(titleID, customerID)
+
+	var r0 *dvd.Copy
+	if rf, ok := ret.Get(0).(func(string, string) *dvd.Copy); ok {
+		r0 = rf(titleID, customerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dvd.Copy)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(titleID, customerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Reserve provides a mock function with given fields: r
+func (_m *Repository) Reserve(r *dvd.Reservation) error {
+	ret := _m.Called(r)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*dvd.Reservation) error); ok {
+		r0 = rf(r)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Return provides a mock function with given fields: id, customerID, hold
+func (_m *Repository) Return(id string, customerID string, hold time.Duration) (*dvd.Copy, error) {
+	ret := _m.Called(id, customerID, hold)
+
+	var r0 *dvd.Copy
+	if rf, ok := ret.Get(0).(func(string, string, time.Duration) *dvd.Copy); ok {
+		r0 = rf(id, customerID, hold)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dvd.Copy)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, time.Duration) error); ok {
+		r1 = rf(id, customerID, hold)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Search provides a mock function with given fields: f, after, limit
+func (_m *Repository) Search(f dvd.Filter, after *dvd.Cursor, limit int) ([]*dvd.Copy, error) {
+	ret := _m.Called(f, after, limit)
+
+	var r0 []*dvd.Copy
+	if rf, ok := ret.Get(0).(func(dvd.Filter, *dvd.Cursor, int) []*dvd.Copy); ok {
+		r0 = rf(f, after, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*dvd.Copy)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dvd.Filter, *dvd.Cursor, int) error); ok {
+		r1 = rf(f, after, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Store provides a mock function with given fields: c
+func (_m *Repository) Store(c *dvd.Copy) error {
+	ret := _m.Called(c)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*dvd.Copy) error); ok {
+		r0 = rf(c)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// StoreMany provides a mock function with given fields: copies
+func (_m *Repository) StoreMany(copies []*dvd.Copy) error {
+	ret := _m.Called(copies)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]*dvd.Copy) error); ok {
+		r0 = rf(copies)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// StoreTitle provides a mock function with given fields: t
+func (_m *Repository) StoreTitle(t *dvd.Title) error {
+	ret := _m.Called(t)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*dvd.Title) error); ok {
+		r0 = rf(t)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// TakenBarcodes provides a mock function with given fields: barcodes
+func (_m *Repository) TakenBarcodes(barcodes []string) ([]string, error) {
+	ret := _m.Called(barcodes)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func([]string) []string); ok {
+		r0 = rf(barcodes)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]string) error); ok {
+		r1 = rf(barcodes)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Update provides a mock function with given fields: id, status
-func (_m *Repository) Update(id string, status dvd.Status) error {
+func (_m *Repository) Update(id string, status dvd.Status) (*dvd.Copy, error) {
 	ret := _m.Called(id, status)
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(string, dvd.Status) error); ok {
+	var r0 *dvd.Copy
+	if rf, ok := ret.Get(0).(func(string, dvd.Status) *dvd.Copy); ok {
 		r0 = rf(id, status)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dvd.Copy)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, dvd.Status) error); ok {
+		r1 = rf(id, status)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateTitle provides a mock function with given fields: t
+func (_m *Repository) UpdateTitle(t *dvd.Title) error {
+	ret := _m.Called(t)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*dvd.Title) error); ok {
+		r0 = rf(t)
 	} else {
 		r0 = ret.Error(0)
 	}
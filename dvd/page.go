@@ -0,0 +1,88 @@
+package dvd
+
+import (
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/ngray1747/dvd-rental/internal/apperr"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+var errInvalidCursor = apperr.New(apperr.InvalidArgument, "invalid cursor")
+
+// Filter narrows down a copies listing
+type Filter struct {
+	// Name matches copies whose title name contains it, case insensitive
+	Name    string
+	TitleID string
+	Status  Status
+}
+
+// IsZero reports whether the filter matches every dvd
+func (f Filter) IsZero() bool {
+	return f.Name == "" && f.TitleID == "" && f.Status == 0
+}
+
+// Cursor is the position of the last copy of a page in the CreatedAt ordering
+type Cursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// Page is a chunk of copies, NextCursor is empty on the last page
+type Page struct {
+	Copies     []*Copy `json:"copies"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
+
+// EncodeCursor returns the opaque token of a cursor
+func EncodeCursor(c Cursor) string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a token made by EncodeCursor, an empty token is the first page
+func DecodeCursor(token string) (*Cursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return nil, errInvalidCursor
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	return &Cursor{CreatedAt: createdAt, ID: parts[1]}, nil
+}
+
+func pageSize(limit int) int {
+	if limit <= 0 {
+		return defaultPageSize
+	}
+	if limit > maxPageSize {
+		return maxPageSize
+	}
+	return limit
+}
+
+// newPage cuts copies, fetched with one extra row, down to size
+func newPage(copies []*Copy, size int) *Page {
+	page := &Page{Copies: copies}
+	if len(copies) > size {
+		page.Copies = copies[:size]
+		last := page.Copies[size-1]
+		page.NextCursor = EncodeCursor(Cursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+	return page
+}
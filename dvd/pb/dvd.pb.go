@@ -24,8 +24,315 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
-type CreateDVDRequest struct {
+// DVD is a physical copy of a title
+type DVD struct {
+	Id        string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name      string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Status    string `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	CreatedAt int64  `protobuf:"varint,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	TitleId   string `protobuf:"bytes,5,opt,name=title_id,json=titleId,proto3" json:"title_id,omitempty"`
+	Barcode   string `protobuf:"bytes,6,opt,name=barcode,proto3" json:"barcode,omitempty"`
+	Condition string `protobuf:"bytes,7,opt,name=condition,proto3" json:"condition,omitempty"`
+	// replacement_cost of the title in cents, set when the title is loaded
+	ReplacementCost int64 `protobuf:"varint,8,opt,name=replacement_cost,json=replacementCost,proto3" json:"replacement_cost,omitempty"`
+	// price the copy sells for in cents, set when the title is loaded
+	Price int64 `protobuf:"varint,9,opt,name=price,proto3" json:"price,omitempty"`
+	// reserved_for is the customer a Reserved copy is held for until held_until
+	ReservedFor          string   `protobuf:"bytes,10,opt,name=reserved_for,json=reservedFor,proto3" json:"reserved_for,omitempty"`
+	HeldUntil            int64    `protobuf:"varint,11,opt,name=held_until,json=heldUntil,proto3" json:"held_until,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DVD) Reset()         { *m = DVD{} }
+func (m *DVD) String() string { return proto.CompactTextString(m) }
+func (*DVD) ProtoMessage()    {}
+func (*DVD) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ffc8f8b3f26a27f, []int{0}
+}
+
+func (m *DVD) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DVD.Unmarshal(m, b)
+}
+func (m *DVD) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DVD.Marshal(b, m, deterministic)
+}
+func (m *DVD) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DVD.Merge(m, src)
+}
+func (m *DVD) XXX_Size() int {
+	return xxx_messageInfo_DVD.Size(m)
+}
+func (m *DVD) XXX_DiscardUnknown() {
+	xxx_messageInfo_DVD.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DVD proto.InternalMessageInfo
+
+func (m *DVD) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *DVD) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *DVD) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *DVD) GetCreatedAt() int64 {
+	if m != nil {
+		return m.CreatedAt
+	}
+	return 0
+}
+
+func (m *DVD) GetTitleId() string {
+	if m != nil {
+		return m.TitleId
+	}
+	return ""
+}
+
+func (m *DVD) GetBarcode() string {
+	if m != nil {
+		return m.Barcode
+	}
+	return ""
+}
+
+func (m *DVD) GetCondition() string {
+	if m != nil {
+		return m.Condition
+	}
+	return ""
+}
+
+func (m *DVD) GetReplacementCost() int64 {
+	if m != nil {
+		return m.ReplacementCost
+	}
+	return 0
+}
+
+func (m *DVD) GetPrice() int64 {
+	if m != nil {
+		return m.Price
+	}
+	return 0
+}
+
+func (m *DVD) GetReservedFor() string {
+	if m != nil {
+		return m.ReservedFor
+	}
+	return ""
+}
+
+func (m *DVD) GetHeldUntil() int64 {
+	if m != nil {
+		return m.HeldUntil
+	}
+	return 0
+}
+
+// Reservation is a place in the waitlist of a title
+type Reservation struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	TitleId              string   `protobuf:"bytes,2,opt,name=title_id,json=titleId,proto3" json:"title_id,omitempty"`
+	CustomerId           string   `protobuf:"bytes,3,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	CreatedAt            int64    `protobuf:"varint,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Reservation) Reset()         { *m = Reservation{} }
+func (m *Reservation) String() string { return proto.CompactTextString(m) }
+func (*Reservation) ProtoMessage()    {}
+func (*Reservation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ffc8f8b3f26a27f, []int{1}
+}
+
+func (m *Reservation) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Reservation.Unmarshal(m, b)
+}
+func (m *Reservation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Reservation.Marshal(b, m, deterministic)
+}
+func (m *Reservation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Reservation.Merge(m, src)
+}
+func (m *Reservation) XXX_Size() int {
+	return xxx_messageInfo_Reservation.Size(m)
+}
+func (m *Reservation) XXX_DiscardUnknown() {
+	xxx_messageInfo_Reservation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Reservation proto.InternalMessageInfo
+
+func (m *Reservation) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Reservation) GetTitleId() string {
+	if m != nil {
+		return m.TitleId
+	}
+	return ""
+}
+
+func (m *Reservation) GetCustomerId() string {
+	if m != nil {
+		return m.CustomerId
+	}
+	return ""
+}
+
+func (m *Reservation) GetCreatedAt() int64 {
+	if m != nil {
+		return m.CreatedAt
+	}
+	return 0
+}
+
+type CreateTitleRequest struct {
 	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Year                 int32    `protobuf:"varint,2,opt,name=year,proto3" json:"year,omitempty"`
+	Runtime              int32    `protobuf:"varint,3,opt,name=runtime,proto3" json:"runtime,omitempty"`
+	Rating               string   `protobuf:"bytes,4,opt,name=rating,proto3" json:"rating,omitempty"`
+	Genres               []string `protobuf:"bytes,5,rep,name=genres,proto3" json:"genres,omitempty"`
+	ReplacementCost      int64    `protobuf:"varint,6,opt,name=replacement_cost,json=replacementCost,proto3" json:"replacement_cost,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CreateTitleRequest) Reset()         { *m = CreateTitleRequest{} }
+func (m *CreateTitleRequest) String() string { return proto.CompactTextString(m) }
+func (*CreateTitleRequest) ProtoMessage()    {}
+func (*CreateTitleRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ffc8f8b3f26a27f, []int{2}
+}
+
+func (m *CreateTitleRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateTitleRequest.Unmarshal(m, b)
+}
+func (m *CreateTitleRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateTitleRequest.Marshal(b, m, deterministic)
+}
+func (m *CreateTitleRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateTitleRequest.Merge(m, src)
+}
+func (m *CreateTitleRequest) XXX_Size() int {
+	return xxx_messageInfo_CreateTitleRequest.Size(m)
+}
+func (m *CreateTitleRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateTitleRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateTitleRequest proto.InternalMessageInfo
+
+func (m *CreateTitleRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *CreateTitleRequest) GetYear() int32 {
+	if m != nil {
+		return m.Year
+	}
+	return 0
+}
+
+func (m *CreateTitleRequest) GetRuntime() int32 {
+	if m != nil {
+		return m.Runtime
+	}
+	return 0
+}
+
+func (m *CreateTitleRequest) GetRating() string {
+	if m != nil {
+		return m.Rating
+	}
+	return ""
+}
+
+func (m *CreateTitleRequest) GetGenres() []string {
+	if m != nil {
+		return m.Genres
+	}
+	return nil
+}
+
+func (m *CreateTitleRequest) GetReplacementCost() int64 {
+	if m != nil {
+		return m.ReplacementCost
+	}
+	return 0
+}
+
+type CreateTitleResponse struct {
+	Id                   string   `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CreateTitleResponse) Reset()         { *m = CreateTitleResponse{} }
+func (m *CreateTitleResponse) String() string { return proto.CompactTextString(m) }
+func (*CreateTitleResponse) ProtoMessage()    {}
+func (*CreateTitleResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ffc8f8b3f26a27f, []int{3}
+}
+
+func (m *CreateTitleResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateTitleResponse.Unmarshal(m, b)
+}
+func (m *CreateTitleResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateTitleResponse.Marshal(b, m, deterministic)
+}
+func (m *CreateTitleResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateTitleResponse.Merge(m, src)
+}
+func (m *CreateTitleResponse) XXX_Size() int {
+	return xxx_messageInfo_CreateTitleResponse.Size(m)
+}
+func (m *CreateTitleResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateTitleResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateTitleResponse proto.InternalMessageInfo
+
+func (m *CreateTitleResponse) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type CreateDVDRequest struct {
+	TitleId              string   `protobuf:"bytes,2,opt,name=title_id,json=titleId,proto3" json:"title_id,omitempty"`
+	Barcode              string   `protobuf:"bytes,3,opt,name=barcode,proto3" json:"barcode,omitempty"`
+	Condition            string   `protobuf:"bytes,4,opt,name=condition,proto3" json:"condition,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -35,7 +342,7 @@ func (m *CreateDVDRequest) Reset()         { *m = CreateDVDRequest{} }
 func (m *CreateDVDRequest) String() string { return proto.CompactTextString(m) }
 func (*CreateDVDRequest) ProtoMessage()    {}
 func (*CreateDVDRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_3ffc8f8b3f26a27f, []int{0}
+	return fileDescriptor_3ffc8f8b3f26a27f, []int{4}
 }
 
 func (m *CreateDVDRequest) XXX_Unmarshal(b []byte) error {
@@ -56,15 +363,29 @@ func (m *CreateDVDRequest) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_CreateDVDRequest proto.InternalMessageInfo
 
-func (m *CreateDVDRequest) GetName() string {
+func (m *CreateDVDRequest) GetTitleId() string {
 	if m != nil {
-		return m.Name
+		return m.TitleId
+	}
+	return ""
+}
+
+func (m *CreateDVDRequest) GetBarcode() string {
+	if m != nil {
+		return m.Barcode
+	}
+	return ""
+}
+
+func (m *CreateDVDRequest) GetCondition() string {
+	if m != nil {
+		return m.Condition
 	}
 	return ""
 }
 
 type CreateDVDResponse struct {
-	Err                  string   `protobuf:"bytes,1,opt,name=err,proto3" json:"err,omitempty"`
+	Id                   string   `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -74,7 +395,7 @@ func (m *CreateDVDResponse) Reset()         { *m = CreateDVDResponse{} }
 func (m *CreateDVDResponse) String() string { return proto.CompactTextString(m) }
 func (*CreateDVDResponse) ProtoMessage()    {}
 func (*CreateDVDResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_3ffc8f8b3f26a27f, []int{1}
+	return fileDescriptor_3ffc8f8b3f26a27f, []int{5}
 }
 
 func (m *CreateDVDResponse) XXX_Unmarshal(b []byte) error {
@@ -95,15 +416,18 @@ func (m *CreateDVDResponse) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_CreateDVDResponse proto.InternalMessageInfo
 
-func (m *CreateDVDResponse) GetErr() string {
+func (m *CreateDVDResponse) GetId() string {
 	if m != nil {
-		return m.Err
+		return m.Id
 	}
 	return ""
 }
 
+// RentDVDRequest rents the copy id, or any available copy of title_id
 type RentDVDRequest struct {
 	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	TitleId              string   `protobuf:"bytes,2,opt,name=title_id,json=titleId,proto3" json:"title_id,omitempty"`
+	CustomerId           string   `protobuf:"bytes,3,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -113,7 +437,7 @@ func (m *RentDVDRequest) Reset()         { *m = RentDVDRequest{} }
 func (m *RentDVDRequest) String() string { return proto.CompactTextString(m) }
 func (*RentDVDRequest) ProtoMessage()    {}
 func (*RentDVDRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_3ffc8f8b3f26a27f, []int{2}
+	return fileDescriptor_3ffc8f8b3f26a27f, []int{6}
 }
 
 func (m *RentDVDRequest) XXX_Unmarshal(b []byte) error {
@@ -141,8 +465,22 @@ func (m *RentDVDRequest) GetId() string {
 	return ""
 }
 
+func (m *RentDVDRequest) GetTitleId() string {
+	if m != nil {
+		return m.TitleId
+	}
+	return ""
+}
+
+func (m *RentDVDRequest) GetCustomerId() string {
+	if m != nil {
+		return m.CustomerId
+	}
+	return ""
+}
+
 type RentDVDResponse struct {
-	Err                  string   `protobuf:"bytes,1,opt,name=err,proto3" json:"err,omitempty"`
+	Id                   string   `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -152,7 +490,7 @@ func (m *RentDVDResponse) Reset()         { *m = RentDVDResponse{} }
 func (m *RentDVDResponse) String() string { return proto.CompactTextString(m) }
 func (*RentDVDResponse) ProtoMessage()    {}
 func (*RentDVDResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_3ffc8f8b3f26a27f, []int{3}
+	return fileDescriptor_3ffc8f8b3f26a27f, []int{7}
 }
 
 func (m *RentDVDResponse) XXX_Unmarshal(b []byte) error {
@@ -173,101 +511,1241 @@ func (m *RentDVDResponse) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_RentDVDResponse proto.InternalMessageInfo
 
-func (m *RentDVDResponse) GetErr() string {
+func (m *RentDVDResponse) GetId() string {
 	if m != nil {
-		return m.Err
+		return m.Id
 	}
 	return ""
 }
 
-func init() {
-	proto.RegisterType((*CreateDVDRequest)(nil), "pb.CreateDVDRequest")
-	proto.RegisterType((*CreateDVDResponse)(nil), "pb.CreateDVDResponse")
-	proto.RegisterType((*RentDVDRequest)(nil), "pb.RentDVDRequest")
-	proto.RegisterType((*RentDVDResponse)(nil), "pb.RentDVDResponse")
+// ReturnDVDRequest returns id rented by customer_id, see dvd.Service.ReturnDVD
+type ReturnDVDRequest struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CustomerId           string   `protobuf:"bytes,2,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func init() { proto.RegisterFile("dvd.proto", fileDescriptor_3ffc8f8b3f26a27f) }
-
-var fileDescriptor_3ffc8f8b3f26a27f = []byte{
-	// 181 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0x4c, 0x29, 0x4b, 0xd1,
-	0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x2a, 0x48, 0x52, 0x52, 0xe3, 0x12, 0x70, 0x2e, 0x4a,
-	0x4d, 0x2c, 0x49, 0x75, 0x09, 0x73, 0x09, 0x4a, 0x2d, 0x2c, 0x4d, 0x2d, 0x2e, 0x11, 0x12, 0xe2,
-	0x62, 0xc9, 0x4b, 0xcc, 0x4d, 0x95, 0x60, 0x54, 0x60, 0xd4, 0xe0, 0x0c, 0x02, 0xb3, 0x95, 0x54,
-	0xb9, 0x04, 0x91, 0xd4, 0x15, 0x17, 0xe4, 0xe7, 0x15, 0xa7, 0x0a, 0x09, 0x70, 0x31, 0xa7, 0x16,
-	0x15, 0x41, 0xd5, 0x81, 0x98, 0x4a, 0x0a, 0x5c, 0x7c, 0x41, 0xa9, 0x79, 0x25, 0x48, 0x86, 0xf1,
-	0x71, 0x31, 0x65, 0xa6, 0x40, 0x95, 0x30, 0x65, 0xa6, 0x28, 0x29, 0x73, 0xf1, 0xc3, 0x55, 0xe0,
-	0x32, 0xc6, 0xa8, 0x92, 0x8b, 0x13, 0xac, 0x20, 0xaf, 0x24, 0x31, 0x47, 0xc8, 0x82, 0x8b, 0x13,
-	0x6e, 0xb5, 0x90, 0x88, 0x5e, 0x41, 0x92, 0x1e, 0xba, 0x8b, 0xa5, 0x44, 0xd1, 0x44, 0xa1, 0x06,
-	0x1b, 0x71, 0xb1, 0x43, 0xed, 0x12, 0x12, 0x02, 0xa9, 0x40, 0x75, 0x9a, 0x94, 0x30, 0x8a, 0x18,
-	0x44, 0x4f, 0x12, 0x1b, 0x38, 0x6c, 0x8c, 0x01, 0x01, 0x00, 0x00, 0xff, 0xff, 0xce, 0x8d, 0x60,
-	0x2f, 0x28, 0x01, 0x00, 0x00,
+func (m *ReturnDVDRequest) Reset()         { *m = ReturnDVDRequest{} }
+func (m *ReturnDVDRequest) String() string { return proto.CompactTextString(m) }
+func (*ReturnDVDRequest) ProtoMessage()    {}
+func (*ReturnDVDRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ffc8f8b3f26a27f, []int{8}
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
-
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
-
-// DVDRentalClient is the client API for DVDRental service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type DVDRentalClient interface {
-	CreateDVD(ctx context.Context, in *CreateDVDRequest, opts ...grpc.CallOption) (*CreateDVDResponse, error)
-	RentDVD(ctx context.Context, in *RentDVDRequest, opts ...grpc.CallOption) (*RentDVDResponse, error)
+func (m *ReturnDVDRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReturnDVDRequest.Unmarshal(m, b)
 }
-
-type dVDRentalClient struct {
-	cc *grpc.ClientConn
+func (m *ReturnDVDRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReturnDVDRequest.Marshal(b, m, deterministic)
 }
-
-func NewDVDRentalClient(cc *grpc.ClientConn) DVDRentalClient {
-	return &dVDRentalClient{cc}
+func (m *ReturnDVDRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReturnDVDRequest.Merge(m, src)
+}
+func (m *ReturnDVDRequest) XXX_Size() int {
+	return xxx_messageInfo_ReturnDVDRequest.Size(m)
+}
+func (m *ReturnDVDRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReturnDVDRequest.DiscardUnknown(m)
 }
 
-func (c *dVDRentalClient) CreateDVD(ctx context.Context, in *CreateDVDRequest, opts ...grpc.CallOption) (*CreateDVDResponse, error) {
-	out := new(CreateDVDResponse)
-	err := c.cc.Invoke(ctx, "/pb.DVDRental/CreateDVD", in, out, opts...)
-	if err != nil {
-		return nil, err
+var xxx_messageInfo_ReturnDVDRequest proto.InternalMessageInfo
+
+func (m *ReturnDVDRequest) GetId() string {
+	if m != nil {
+		return m.Id
 	}
-	return out, nil
+	return ""
 }
 
-func (c *dVDRentalClient) RentDVD(ctx context.Context, in *RentDVDRequest, opts ...grpc.CallOption) (*RentDVDResponse, error) {
-	out := new(RentDVDResponse)
-	err := c.cc.Invoke(ctx, "/pb.DVDRental/RentDVD", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *ReturnDVDRequest) GetCustomerId() string {
+	if m != nil {
+		return m.CustomerId
 	}
-	return out, nil
+	return ""
 }
 
-// DVDRentalServer is the server API for DVDRental service.
-type DVDRentalServer interface {
-	CreateDVD(context.Context, *CreateDVDRequest) (*CreateDVDResponse, error)
-	RentDVD(context.Context, *RentDVDRequest) (*RentDVDResponse, error)
+type ReturnDVDResponse struct {
+	Dvd                  *DVD     `protobuf:"bytes,2,opt,name=dvd,proto3" json:"dvd,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-// UnimplementedDVDRentalServer can be embedded to have forward compatible implementations.
+func (m *ReturnDVDResponse) Reset()         { *m = ReturnDVDResponse{} }
+func (m *ReturnDVDResponse) String() string { return proto.CompactTextString(m) }
+func (*ReturnDVDResponse) ProtoMessage()    {}
+func (*ReturnDVDResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ffc8f8b3f26a27f, []int{9}
+}
+
+func (m *ReturnDVDResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReturnDVDResponse.Unmarshal(m, b)
+}
+func (m *ReturnDVDResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReturnDVDResponse.Marshal(b, m, deterministic)
+}
+func (m *ReturnDVDResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReturnDVDResponse.Merge(m, src)
+}
+func (m *ReturnDVDResponse) XXX_Size() int {
+	return xxx_messageInfo_ReturnDVDResponse.Size(m)
+}
+func (m *ReturnDVDResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReturnDVDResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReturnDVDResponse proto.InternalMessageInfo
+
+func (m *ReturnDVDResponse) GetDvd() *DVD {
+	if m != nil {
+		return m.Dvd
+	}
+	return nil
+}
+
+// ReleaseDVDRequest compensates a rent of id by customer_id, see dvd.Service.ReleaseDVD
+type ReleaseDVDRequest struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CustomerId           string   `protobuf:"bytes,2,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReleaseDVDRequest) Reset()         { *m = ReleaseDVDRequest{} }
+func (m *ReleaseDVDRequest) String() string { return proto.CompactTextString(m) }
+func (*ReleaseDVDRequest) ProtoMessage()    {}
+func (*ReleaseDVDRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ffc8f8b3f26a27f, []int{10}
+}
+
+func (m *ReleaseDVDRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReleaseDVDRequest.Unmarshal(m, b)
+}
+func (m *ReleaseDVDRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReleaseDVDRequest.Marshal(b, m, deterministic)
+}
+func (m *ReleaseDVDRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReleaseDVDRequest.Merge(m, src)
+}
+func (m *ReleaseDVDRequest) XXX_Size() int {
+	return xxx_messageInfo_ReleaseDVDRequest.Size(m)
+}
+func (m *ReleaseDVDRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReleaseDVDRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReleaseDVDRequest proto.InternalMessageInfo
+
+func (m *ReleaseDVDRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *ReleaseDVDRequest) GetCustomerId() string {
+	if m != nil {
+		return m.CustomerId
+	}
+	return ""
+}
+
+type ReleaseDVDResponse struct {
+	Dvd                  *DVD     `protobuf:"bytes,1,opt,name=dvd,proto3" json:"dvd,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReleaseDVDResponse) Reset()         { *m = ReleaseDVDResponse{} }
+func (m *ReleaseDVDResponse) String() string { return proto.CompactTextString(m) }
+func (*ReleaseDVDResponse) ProtoMessage()    {}
+func (*ReleaseDVDResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ffc8f8b3f26a27f, []int{11}
+}
+
+func (m *ReleaseDVDResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReleaseDVDResponse.Unmarshal(m, b)
+}
+func (m *ReleaseDVDResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReleaseDVDResponse.Marshal(b, m, deterministic)
+}
+func (m *ReleaseDVDResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReleaseDVDResponse.Merge(m, src)
+}
+func (m *ReleaseDVDResponse) XXX_Size() int {
+	return xxx_messageInfo_ReleaseDVDResponse.Size(m)
+}
+func (m *ReleaseDVDResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReleaseDVDResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReleaseDVDResponse proto.InternalMessageInfo
+
+func (m *ReleaseDVDResponse) GetDvd() *DVD {
+	if m != nil {
+		return m.Dvd
+	}
+	return nil
+}
+
+type SellDVDRequest struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SellDVDRequest) Reset()         { *m = SellDVDRequest{} }
+func (m *SellDVDRequest) String() string { return proto.CompactTextString(m) }
+func (*SellDVDRequest) ProtoMessage()    {}
+func (*SellDVDRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ffc8f8b3f26a27f, []int{12}
+}
+
+func (m *SellDVDRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SellDVDRequest.Unmarshal(m, b)
+}
+func (m *SellDVDRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SellDVDRequest.Marshal(b, m, deterministic)
+}
+func (m *SellDVDRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SellDVDRequest.Merge(m, src)
+}
+func (m *SellDVDRequest) XXX_Size() int {
+	return xxx_messageInfo_SellDVDRequest.Size(m)
+}
+func (m *SellDVDRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SellDVDRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SellDVDRequest proto.InternalMessageInfo
+
+func (m *SellDVDRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type SellDVDResponse struct {
+	Dvd                  *DVD     `protobuf:"bytes,2,opt,name=dvd,proto3" json:"dvd,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SellDVDResponse) Reset()         { *m = SellDVDResponse{} }
+func (m *SellDVDResponse) String() string { return proto.CompactTextString(m) }
+func (*SellDVDResponse) ProtoMessage()    {}
+func (*SellDVDResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ffc8f8b3f26a27f, []int{13}
+}
+
+func (m *SellDVDResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SellDVDResponse.Unmarshal(m, b)
+}
+func (m *SellDVDResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SellDVDResponse.Marshal(b, m, deterministic)
+}
+func (m *SellDVDResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SellDVDResponse.Merge(m, src)
+}
+func (m *SellDVDResponse) XXX_Size() int {
+	return xxx_messageInfo_SellDVDResponse.Size(m)
+}
+func (m *SellDVDResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SellDVDResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SellDVDResponse proto.InternalMessageInfo
+
+func (m *SellDVDResponse) GetDvd() *DVD {
+	if m != nil {
+		return m.Dvd
+	}
+	return nil
+}
+
+type JoinWaitlistRequest struct {
+	TitleId              string   `protobuf:"bytes,1,opt,name=title_id,json=titleId,proto3" json:"title_id,omitempty"`
+	CustomerId           string   `protobuf:"bytes,2,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *JoinWaitlistRequest) Reset()         { *m = JoinWaitlistRequest{} }
+func (m *JoinWaitlistRequest) String() string { return proto.CompactTextString(m) }
+func (*JoinWaitlistRequest) ProtoMessage()    {}
+func (*JoinWaitlistRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ffc8f8b3f26a27f, []int{14}
+}
+
+func (m *JoinWaitlistRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_JoinWaitlistRequest.Unmarshal(m, b)
+}
+func (m *JoinWaitlistRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_JoinWaitlistRequest.Marshal(b, m, deterministic)
+}
+func (m *JoinWaitlistRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_JoinWaitlistRequest.Merge(m, src)
+}
+func (m *JoinWaitlistRequest) XXX_Size() int {
+	return xxx_messageInfo_JoinWaitlistRequest.Size(m)
+}
+func (m *JoinWaitlistRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_JoinWaitlistRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_JoinWaitlistRequest proto.InternalMessageInfo
+
+func (m *JoinWaitlistRequest) GetTitleId() string {
+	if m != nil {
+		return m.TitleId
+	}
+	return ""
+}
+
+func (m *JoinWaitlistRequest) GetCustomerId() string {
+	if m != nil {
+		return m.CustomerId
+	}
+	return ""
+}
+
+type JoinWaitlistResponse struct {
+	Reservation          *Reservation `protobuf:"bytes,2,opt,name=reservation,proto3" json:"reservation,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *JoinWaitlistResponse) Reset()         { *m = JoinWaitlistResponse{} }
+func (m *JoinWaitlistResponse) String() string { return proto.CompactTextString(m) }
+func (*JoinWaitlistResponse) ProtoMessage()    {}
+func (*JoinWaitlistResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ffc8f8b3f26a27f, []int{15}
+}
+
+func (m *JoinWaitlistResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_JoinWaitlistResponse.Unmarshal(m, b)
+}
+func (m *JoinWaitlistResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_JoinWaitlistResponse.Marshal(b, m, deterministic)
+}
+func (m *JoinWaitlistResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_JoinWaitlistResponse.Merge(m, src)
+}
+func (m *JoinWaitlistResponse) XXX_Size() int {
+	return xxx_messageInfo_JoinWaitlistResponse.Size(m)
+}
+func (m *JoinWaitlistResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_JoinWaitlistResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_JoinWaitlistResponse proto.InternalMessageInfo
+
+func (m *JoinWaitlistResponse) GetReservation() *Reservation {
+	if m != nil {
+		return m.Reservation
+	}
+	return nil
+}
+
+type LeaveWaitlistRequest struct {
+	TitleId              string   `protobuf:"bytes,1,opt,name=title_id,json=titleId,proto3" json:"title_id,omitempty"`
+	CustomerId           string   `protobuf:"bytes,2,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LeaveWaitlistRequest) Reset()         { *m = LeaveWaitlistRequest{} }
+func (m *LeaveWaitlistRequest) String() string { return proto.CompactTextString(m) }
+func (*LeaveWaitlistRequest) ProtoMessage()    {}
+func (*LeaveWaitlistRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ffc8f8b3f26a27f, []int{16}
+}
+
+func (m *LeaveWaitlistRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LeaveWaitlistRequest.Unmarshal(m, b)
+}
+func (m *LeaveWaitlistRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LeaveWaitlistRequest.Marshal(b, m, deterministic)
+}
+func (m *LeaveWaitlistRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LeaveWaitlistRequest.Merge(m, src)
+}
+func (m *LeaveWaitlistRequest) XXX_Size() int {
+	return xxx_messageInfo_LeaveWaitlistRequest.Size(m)
+}
+func (m *LeaveWaitlistRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_LeaveWaitlistRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LeaveWaitlistRequest proto.InternalMessageInfo
+
+func (m *LeaveWaitlistRequest) GetTitleId() string {
+	if m != nil {
+		return m.TitleId
+	}
+	return ""
+}
+
+func (m *LeaveWaitlistRequest) GetCustomerId() string {
+	if m != nil {
+		return m.CustomerId
+	}
+	return ""
+}
+
+type LeaveWaitlistResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LeaveWaitlistResponse) Reset()         { *m = LeaveWaitlistResponse{} }
+func (m *LeaveWaitlistResponse) String() string { return proto.CompactTextString(m) }
+func (*LeaveWaitlistResponse) ProtoMessage()    {}
+func (*LeaveWaitlistResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ffc8f8b3f26a27f, []int{17}
+}
+
+func (m *LeaveWaitlistResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LeaveWaitlistResponse.Unmarshal(m, b)
+}
+func (m *LeaveWaitlistResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LeaveWaitlistResponse.Marshal(b, m, deterministic)
+}
+func (m *LeaveWaitlistResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LeaveWaitlistResponse.Merge(m, src)
+}
+func (m *LeaveWaitlistResponse) XXX_Size() int {
+	return xxx_messageInfo_LeaveWaitlistResponse.Size(m)
+}
+func (m *LeaveWaitlistResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_LeaveWaitlistResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LeaveWaitlistResponse proto.InternalMessageInfo
+
+type ListWaitlistRequest struct {
+	TitleId              string   `protobuf:"bytes,1,opt,name=title_id,json=titleId,proto3" json:"title_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListWaitlistRequest) Reset()         { *m = ListWaitlistRequest{} }
+func (m *ListWaitlistRequest) String() string { return proto.CompactTextString(m) }
+func (*ListWaitlistRequest) ProtoMessage()    {}
+func (*ListWaitlistRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ffc8f8b3f26a27f, []int{18}
+}
+
+func (m *ListWaitlistRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListWaitlistRequest.Unmarshal(m, b)
+}
+func (m *ListWaitlistRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListWaitlistRequest.Marshal(b, m, deterministic)
+}
+func (m *ListWaitlistRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListWaitlistRequest.Merge(m, src)
+}
+func (m *ListWaitlistRequest) XXX_Size() int {
+	return xxx_messageInfo_ListWaitlistRequest.Size(m)
+}
+func (m *ListWaitlistRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListWaitlistRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListWaitlistRequest proto.InternalMessageInfo
+
+func (m *ListWaitlistRequest) GetTitleId() string {
+	if m != nil {
+		return m.TitleId
+	}
+	return ""
+}
+
+type ListWaitlistResponse struct {
+	Reservations         []*Reservation `protobuf:"bytes,2,rep,name=reservations,proto3" json:"reservations,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *ListWaitlistResponse) Reset()         { *m = ListWaitlistResponse{} }
+func (m *ListWaitlistResponse) String() string { return proto.CompactTextString(m) }
+func (*ListWaitlistResponse) ProtoMessage()    {}
+func (*ListWaitlistResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ffc8f8b3f26a27f, []int{19}
+}
+
+func (m *ListWaitlistResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListWaitlistResponse.Unmarshal(m, b)
+}
+func (m *ListWaitlistResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListWaitlistResponse.Marshal(b, m, deterministic)
+}
+func (m *ListWaitlistResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListWaitlistResponse.Merge(m, src)
+}
+func (m *ListWaitlistResponse) XXX_Size() int {
+	return xxx_messageInfo_ListWaitlistResponse.Size(m)
+}
+func (m *ListWaitlistResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListWaitlistResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListWaitlistResponse proto.InternalMessageInfo
+
+func (m *ListWaitlistResponse) GetReservations() []*Reservation {
+	if m != nil {
+		return m.Reservations
+	}
+	return nil
+}
+
+type ListDVDsRequest struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Status               string   `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Cursor               string   `protobuf:"bytes,3,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Limit                int32    `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	TitleId              string   `protobuf:"bytes,5,opt,name=title_id,json=titleId,proto3" json:"title_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListDVDsRequest) Reset()         { *m = ListDVDsRequest{} }
+func (m *ListDVDsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListDVDsRequest) ProtoMessage()    {}
+func (*ListDVDsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ffc8f8b3f26a27f, []int{20}
+}
+
+func (m *ListDVDsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListDVDsRequest.Unmarshal(m, b)
+}
+func (m *ListDVDsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListDVDsRequest.Marshal(b, m, deterministic)
+}
+func (m *ListDVDsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListDVDsRequest.Merge(m, src)
+}
+func (m *ListDVDsRequest) XXX_Size() int {
+	return xxx_messageInfo_ListDVDsRequest.Size(m)
+}
+func (m *ListDVDsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListDVDsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListDVDsRequest proto.InternalMessageInfo
+
+func (m *ListDVDsRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ListDVDsRequest) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *ListDVDsRequest) GetCursor() string {
+	if m != nil {
+		return m.Cursor
+	}
+	return ""
+}
+
+func (m *ListDVDsRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *ListDVDsRequest) GetTitleId() string {
+	if m != nil {
+		return m.TitleId
+	}
+	return ""
+}
+
+type ListDVDsResponse struct {
+	Dvds                 []*DVD   `protobuf:"bytes,2,rep,name=dvds,proto3" json:"dvds,omitempty"`
+	NextCursor           string   `protobuf:"bytes,3,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListDVDsResponse) Reset()         { *m = ListDVDsResponse{} }
+func (m *ListDVDsResponse) String() string { return proto.CompactTextString(m) }
+func (*ListDVDsResponse) ProtoMessage()    {}
+func (*ListDVDsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ffc8f8b3f26a27f, []int{21}
+}
+
+func (m *ListDVDsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListDVDsResponse.Unmarshal(m, b)
+}
+func (m *ListDVDsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListDVDsResponse.Marshal(b, m, deterministic)
+}
+func (m *ListDVDsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListDVDsResponse.Merge(m, src)
+}
+func (m *ListDVDsResponse) XXX_Size() int {
+	return xxx_messageInfo_ListDVDsResponse.Size(m)
+}
+func (m *ListDVDsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListDVDsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListDVDsResponse proto.InternalMessageInfo
+
+func (m *ListDVDsResponse) GetDvds() []*DVD {
+	if m != nil {
+		return m.Dvds
+	}
+	return nil
+}
+
+func (m *ListDVDsResponse) GetNextCursor() string {
+	if m != nil {
+		return m.NextCursor
+	}
+	return ""
+}
+
+// ImportDVDsRequest is a chunk of a CSV file, chunks are concatenated in order.
+// The file starts with a header row naming its title_id, barcode and condition columns.
+type ImportDVDsRequest struct {
+	Chunk                []byte   `protobuf:"bytes,1,opt,name=chunk,proto3" json:"chunk,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ImportDVDsRequest) Reset()         { *m = ImportDVDsRequest{} }
+func (m *ImportDVDsRequest) String() string { return proto.CompactTextString(m) }
+func (*ImportDVDsRequest) ProtoMessage()    {}
+func (*ImportDVDsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ffc8f8b3f26a27f, []int{22}
+}
+
+func (m *ImportDVDsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ImportDVDsRequest.Unmarshal(m, b)
+}
+func (m *ImportDVDsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ImportDVDsRequest.Marshal(b, m, deterministic)
+}
+func (m *ImportDVDsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ImportDVDsRequest.Merge(m, src)
+}
+func (m *ImportDVDsRequest) XXX_Size() int {
+	return xxx_messageInfo_ImportDVDsRequest.Size(m)
+}
+func (m *ImportDVDsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ImportDVDsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ImportDVDsRequest proto.InternalMessageInfo
+
+func (m *ImportDVDsRequest) GetChunk() []byte {
+	if m != nil {
+		return m.Chunk
+	}
+	return nil
+}
+
+// ImportError tells why the row of an import was not imported, the header being row 1
+type ImportError struct {
+	Row                  int32    `protobuf:"varint,1,opt,name=row,proto3" json:"row,omitempty"`
+	Barcode              string   `protobuf:"bytes,2,opt,name=barcode,proto3" json:"barcode,omitempty"`
+	Message              string   `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ImportError) Reset()         { *m = ImportError{} }
+func (m *ImportError) String() string { return proto.CompactTextString(m) }
+func (*ImportError) ProtoMessage()    {}
+func (*ImportError) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ffc8f8b3f26a27f, []int{23}
+}
+
+func (m *ImportError) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ImportError.Unmarshal(m, b)
+}
+func (m *ImportError) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ImportError.Marshal(b, m, deterministic)
+}
+func (m *ImportError) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ImportError.Merge(m, src)
+}
+func (m *ImportError) XXX_Size() int {
+	return xxx_messageInfo_ImportError.Size(m)
+}
+func (m *ImportError) XXX_DiscardUnknown() {
+	xxx_messageInfo_ImportError.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ImportError proto.InternalMessageInfo
+
+func (m *ImportError) GetRow() int32 {
+	if m != nil {
+		return m.Row
+	}
+	return 0
+}
+
+func (m *ImportError) GetBarcode() string {
+	if m != nil {
+		return m.Barcode
+	}
+	return ""
+}
+
+func (m *ImportError) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+type ImportDVDsResponse struct {
+	Imported             int32          `protobuf:"varint,1,opt,name=imported,proto3" json:"imported,omitempty"`
+	Errors               []*ImportError `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *ImportDVDsResponse) Reset()         { *m = ImportDVDsResponse{} }
+func (m *ImportDVDsResponse) String() string { return proto.CompactTextString(m) }
+func (*ImportDVDsResponse) ProtoMessage()    {}
+func (*ImportDVDsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ffc8f8b3f26a27f, []int{24}
+}
+
+func (m *ImportDVDsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ImportDVDsResponse.Unmarshal(m, b)
+}
+func (m *ImportDVDsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ImportDVDsResponse.Marshal(b, m, deterministic)
+}
+func (m *ImportDVDsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ImportDVDsResponse.Merge(m, src)
+}
+func (m *ImportDVDsResponse) XXX_Size() int {
+	return xxx_messageInfo_ImportDVDsResponse.Size(m)
+}
+func (m *ImportDVDsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ImportDVDsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ImportDVDsResponse proto.InternalMessageInfo
+
+func (m *ImportDVDsResponse) GetImported() int32 {
+	if m != nil {
+		return m.Imported
+	}
+	return 0
+}
+
+func (m *ImportDVDsResponse) GetErrors() []*ImportError {
+	if m != nil {
+		return m.Errors
+	}
+	return nil
+}
+
+// ExportDVDsRequest exports the copies matching the filter, as csv or jsonl (JSON Lines)
+type ExportDVDsRequest struct {
+	Format               string   `protobuf:"bytes,1,opt,name=format,proto3" json:"format,omitempty"`
+	Name                 string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Status               string   `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	TitleId              string   `protobuf:"bytes,4,opt,name=title_id,json=titleId,proto3" json:"title_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ExportDVDsRequest) Reset()         { *m = ExportDVDsRequest{} }
+func (m *ExportDVDsRequest) String() string { return proto.CompactTextString(m) }
+func (*ExportDVDsRequest) ProtoMessage()    {}
+func (*ExportDVDsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ffc8f8b3f26a27f, []int{25}
+}
+
+func (m *ExportDVDsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExportDVDsRequest.Unmarshal(m, b)
+}
+func (m *ExportDVDsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExportDVDsRequest.Marshal(b, m, deterministic)
+}
+func (m *ExportDVDsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExportDVDsRequest.Merge(m, src)
+}
+func (m *ExportDVDsRequest) XXX_Size() int {
+	return xxx_messageInfo_ExportDVDsRequest.Size(m)
+}
+func (m *ExportDVDsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExportDVDsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExportDVDsRequest proto.InternalMessageInfo
+
+func (m *ExportDVDsRequest) GetFormat() string {
+	if m != nil {
+		return m.Format
+	}
+	return ""
+}
+
+func (m *ExportDVDsRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ExportDVDsRequest) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *ExportDVDsRequest) GetTitleId() string {
+	if m != nil {
+		return m.TitleId
+	}
+	return ""
+}
+
+// ExportDVDsResponse is a chunk of the exported file
+type ExportDVDsResponse struct {
+	Chunk                []byte   `protobuf:"bytes,1,opt,name=chunk,proto3" json:"chunk,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ExportDVDsResponse) Reset()         { *m = ExportDVDsResponse{} }
+func (m *ExportDVDsResponse) String() string { return proto.CompactTextString(m) }
+func (*ExportDVDsResponse) ProtoMessage()    {}
+func (*ExportDVDsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ffc8f8b3f26a27f, []int{26}
+}
+
+func (m *ExportDVDsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExportDVDsResponse.Unmarshal(m, b)
+}
+func (m *ExportDVDsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExportDVDsResponse.Marshal(b, m, deterministic)
+}
+func (m *ExportDVDsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExportDVDsResponse.Merge(m, src)
+}
+func (m *ExportDVDsResponse) XXX_Size() int {
+	return xxx_messageInfo_ExportDVDsResponse.Size(m)
+}
+func (m *ExportDVDsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExportDVDsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExportDVDsResponse proto.InternalMessageInfo
+
+func (m *ExportDVDsResponse) GetChunk() []byte {
+	if m != nil {
+		return m.Chunk
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*DVD)(nil), "pb.DVD")
+	proto.RegisterType((*Reservation)(nil), "pb.Reservation")
+	proto.RegisterType((*CreateTitleRequest)(nil), "pb.CreateTitleRequest")
+	proto.RegisterType((*CreateTitleResponse)(nil), "pb.CreateTitleResponse")
+	proto.RegisterType((*CreateDVDRequest)(nil), "pb.CreateDVDRequest")
+	proto.RegisterType((*CreateDVDResponse)(nil), "pb.CreateDVDResponse")
+	proto.RegisterType((*RentDVDRequest)(nil), "pb.RentDVDRequest")
+	proto.RegisterType((*RentDVDResponse)(nil), "pb.RentDVDResponse")
+	proto.RegisterType((*ReturnDVDRequest)(nil), "pb.ReturnDVDRequest")
+	proto.RegisterType((*ReturnDVDResponse)(nil), "pb.ReturnDVDResponse")
+	proto.RegisterType((*ReleaseDVDRequest)(nil), "pb.ReleaseDVDRequest")
+	proto.RegisterType((*ReleaseDVDResponse)(nil), "pb.ReleaseDVDResponse")
+	proto.RegisterType((*SellDVDRequest)(nil), "pb.SellDVDRequest")
+	proto.RegisterType((*SellDVDResponse)(nil), "pb.SellDVDResponse")
+	proto.RegisterType((*JoinWaitlistRequest)(nil), "pb.JoinWaitlistRequest")
+	proto.RegisterType((*JoinWaitlistResponse)(nil), "pb.JoinWaitlistResponse")
+	proto.RegisterType((*LeaveWaitlistRequest)(nil), "pb.LeaveWaitlistRequest")
+	proto.RegisterType((*LeaveWaitlistResponse)(nil), "pb.LeaveWaitlistResponse")
+	proto.RegisterType((*ListWaitlistRequest)(nil), "pb.ListWaitlistRequest")
+	proto.RegisterType((*ListWaitlistResponse)(nil), "pb.ListWaitlistResponse")
+	proto.RegisterType((*ListDVDsRequest)(nil), "pb.ListDVDsRequest")
+	proto.RegisterType((*ListDVDsResponse)(nil), "pb.ListDVDsResponse")
+	proto.RegisterType((*ImportDVDsRequest)(nil), "pb.ImportDVDsRequest")
+	proto.RegisterType((*ImportError)(nil), "pb.ImportError")
+	proto.RegisterType((*ImportDVDsResponse)(nil), "pb.ImportDVDsResponse")
+	proto.RegisterType((*ExportDVDsRequest)(nil), "pb.ExportDVDsRequest")
+	proto.RegisterType((*ExportDVDsResponse)(nil), "pb.ExportDVDsResponse")
+}
+
+func init() { proto.RegisterFile("dvd.proto", fileDescriptor_3ffc8f8b3f26a27f) }
+
+var fileDescriptor_3ffc8f8b3f26a27f = []byte{
+	// 1002 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x57, 0x6f, 0x6f, 0xe3, 0xc4,
+	0x13, 0x96, 0x9d, 0x38, 0x7f, 0x26, 0xfd, 0x35, 0xe9, 0x26, 0xcd, 0xcf, 0x35, 0x20, 0x82, 0xdf,
+	0xd0, 0x43, 0xa8, 0x94, 0x9e, 0x10, 0x20, 0x04, 0xd2, 0xa9, 0x09, 0x52, 0x4f, 0xf7, 0x06, 0xdf,
+	0x51, 0x84, 0x40, 0x8a, 0x5c, 0x7b, 0xaf, 0x67, 0xe1, 0xd8, 0xb9, 0xdd, 0x4d, 0x08, 0x5f, 0x80,
+	0xef, 0xc3, 0x1b, 0xbe, 0x11, 0xdf, 0x03, 0xed, 0x1f, 0xc7, 0xbb, 0xb6, 0x49, 0x39, 0x71, 0xef,
+	0x32, 0xcf, 0xce, 0xec, 0x3c, 0x33, 0xb3, 0x7e, 0xa6, 0x85, 0x7e, 0xbc, 0x8d, 0x2f, 0xd6, 0x24,
+	0x67, 0x39, 0xb2, 0xd7, 0x77, 0xfe, 0x9f, 0x36, 0xb4, 0xe6, 0xb7, 0x73, 0x74, 0x0c, 0x76, 0x12,
+	0xbb, 0xd6, 0xcc, 0x3a, 0xef, 0x07, 0x76, 0x12, 0x23, 0x04, 0xed, 0x2c, 0x5c, 0x61, 0xd7, 0x16,
+	0x88, 0xf8, 0x8d, 0xa6, 0xd0, 0xa1, 0x2c, 0x64, 0x1b, 0xea, 0xb6, 0x04, 0xaa, 0x2c, 0xf4, 0x1e,
+	0x40, 0x44, 0x70, 0xc8, 0x70, 0xbc, 0x0c, 0x99, 0xdb, 0x9e, 0x59, 0xe7, 0xad, 0xa0, 0xaf, 0x90,
+	0x27, 0x0c, 0x9d, 0x41, 0x8f, 0x25, 0x2c, 0xc5, 0xcb, 0x24, 0x76, 0x1d, 0x11, 0xd8, 0x15, 0xf6,
+	0x4d, 0x8c, 0x5c, 0xe8, 0xde, 0x85, 0x24, 0xca, 0x63, 0xec, 0x76, 0xe4, 0x89, 0x32, 0xd1, 0xbb,
+	0xd0, 0x8f, 0xf2, 0x2c, 0x4e, 0x58, 0x92, 0x67, 0x6e, 0x57, 0x9c, 0x95, 0x00, 0x7a, 0x04, 0x23,
+	0x82, 0xd7, 0x69, 0x18, 0xe1, 0x15, 0xce, 0xd8, 0x32, 0xca, 0x29, 0x73, 0x7b, 0x22, 0xef, 0x50,
+	0xc3, 0xaf, 0x73, 0xca, 0xd0, 0x04, 0x9c, 0x35, 0x49, 0x22, 0xec, 0xf6, 0xc5, 0xb9, 0x34, 0xd0,
+	0x07, 0x70, 0x44, 0x30, 0xc5, 0x64, 0x8b, 0xe3, 0xe5, 0xcb, 0x9c, 0xb8, 0x20, 0x32, 0x0c, 0x0a,
+	0xec, 0xdb, 0x9c, 0xf0, 0xaa, 0x5e, 0xe1, 0x34, 0x5e, 0x6e, 0x32, 0x96, 0xa4, 0xee, 0x40, 0x56,
+	0xc5, 0x91, 0xef, 0x39, 0xe0, 0xef, 0x60, 0x10, 0x08, 0xef, 0x50, 0x30, 0xaa, 0xf6, 0x4f, 0x2f,
+	0xda, 0x36, 0x8b, 0x7e, 0x1f, 0x06, 0xd1, 0x86, 0xb2, 0x7c, 0x85, 0x09, 0x3f, 0x95, 0xbd, 0x84,
+	0x02, 0xba, 0x89, 0x1f, 0xe8, 0xa7, 0xff, 0x87, 0x05, 0xe8, 0x5a, 0x58, 0x2f, 0xf8, 0x8d, 0x01,
+	0x7e, 0xbd, 0xc1, 0x94, 0xed, 0x27, 0x66, 0x69, 0x13, 0x43, 0xd0, 0xfe, 0x0d, 0x87, 0x44, 0x30,
+	0x70, 0x02, 0xf1, 0x9b, 0xf7, 0x9c, 0xf0, 0x9a, 0x56, 0x58, 0xa4, 0x76, 0x82, 0xc2, 0xe4, 0xf3,
+	0x25, 0x21, 0x4b, 0xb2, 0x7b, 0x91, 0xb3, 0x1f, 0x28, 0x8b, 0xe3, 0xf7, 0x38, 0x23, 0x98, 0xba,
+	0xce, 0xac, 0xc5, 0x71, 0x69, 0x35, 0x4e, 0xa1, 0xd3, 0x38, 0x05, 0xff, 0x12, 0xc6, 0x06, 0x65,
+	0xba, 0xce, 0x33, 0x8a, 0x55, 0xd7, 0xec, 0xa2, 0x6b, 0x4f, 0xdb, 0x3d, 0x6b, 0x64, 0x07, 0x2d,
+	0x4c, 0x88, 0xff, 0x1a, 0x46, 0x32, 0x62, 0x7e, 0x3b, 0x2f, 0x4a, 0x3c, 0xd0, 0x54, 0xed, 0x25,
+	0xb5, 0x0e, 0xbc, 0xa4, 0x76, 0xe5, 0x25, 0xa9, 0x8c, 0xa2, 0x5b, 0xfe, 0x05, 0x9c, 0x68, 0x29,
+	0x1f, 0xa6, 0xf8, 0x33, 0x1c, 0x07, 0x38, 0x63, 0x1a, 0xc1, 0xb7, 0xf8, 0x0a, 0xfc, 0x8f, 0x61,
+	0xb8, 0xbf, 0xfd, 0x61, 0x2e, 0xd7, 0x30, 0x0a, 0x30, 0xdb, 0x90, 0xec, 0x00, 0x9b, 0x4a, 0x4a,
+	0xbb, 0x96, 0xf2, 0x4b, 0x38, 0xd1, 0x2e, 0x51, 0x49, 0xcf, 0xa0, 0x15, 0x6f, 0xa5, 0xf7, 0xe0,
+	0xaa, 0x7b, 0xb1, 0xbe, 0xbb, 0xe0, 0xa7, 0x1c, 0xd3, 0xf3, 0xcf, 0x79, 0x68, 0x8a, 0x43, 0x8a,
+	0xff, 0x0b, 0x81, 0x4f, 0x00, 0xe9, 0xb7, 0x98, 0x0c, 0xac, 0x3a, 0x03, 0x7f, 0x06, 0xc7, 0xcf,
+	0x71, 0x9a, 0xfe, 0x73, 0x4e, 0xff, 0x73, 0x18, 0xee, 0x3d, 0xde, 0xa8, 0xa2, 0xef, 0x60, 0xfc,
+	0x34, 0x4f, 0xb2, 0x1f, 0xc2, 0x84, 0xa5, 0x09, 0x65, 0x4d, 0x6f, 0xd0, 0x3a, 0x38, 0xd2, 0x7a,
+	0x79, 0x2f, 0x60, 0x62, 0x5e, 0xa9, 0x08, 0x7d, 0x0a, 0x03, 0x52, 0x6a, 0x89, 0x22, 0x36, 0xe4,
+	0xc4, 0x34, 0x89, 0x09, 0x74, 0x1f, 0x9d, 0x68, 0x00, 0x93, 0x67, 0x38, 0xdc, 0xe2, 0xb7, 0xc9,
+	0xd4, 0x87, 0xd3, 0xca, 0x9d, 0x92, 0xaa, 0x9e, 0xf7, 0x12, 0xc6, 0xcf, 0x12, 0xca, 0xfe, 0x7d,
+	0x5a, 0xff, 0x16, 0x26, 0x66, 0x84, 0xaa, 0xff, 0x31, 0x1c, 0x69, 0xb5, 0x51, 0xd7, 0x9e, 0xb5,
+	0x9a, 0x1a, 0x60, 0x38, 0xe9, 0x4c, 0x7e, 0xb7, 0x60, 0xc8, 0x2f, 0x9e, 0xdf, 0xce, 0xe9, 0x21,
+	0x39, 0x2c, 0x17, 0x98, 0x6d, 0x2c, 0xb0, 0x29, 0x74, 0xa2, 0x0d, 0xa1, 0x39, 0x29, 0x16, 0x9b,
+	0xb4, 0xf8, 0xee, 0x48, 0x93, 0x55, 0x22, 0x35, 0xd8, 0x09, 0xa4, 0x71, 0x60, 0x9f, 0xf9, 0x3f,
+	0xc1, 0xa8, 0xe4, 0xa1, 0x8a, 0x7b, 0x07, 0xda, 0xf1, 0x36, 0x2e, 0x8a, 0xda, 0x3f, 0x37, 0x01,
+	0xf2, 0x41, 0x64, 0x78, 0xc7, 0x96, 0x46, 0x7a, 0xe0, 0xd0, 0xb5, 0x40, 0xf4, 0x2a, 0x1f, 0xc1,
+	0xc9, 0xcd, 0x6a, 0x9d, 0x13, 0xa3, 0xcc, 0x09, 0x38, 0xd1, 0xab, 0x4d, 0xf6, 0x8b, 0xa8, 0xf3,
+	0x28, 0x90, 0x86, 0xff, 0x1c, 0x06, 0xd2, 0x75, 0x41, 0x48, 0x4e, 0xd0, 0x08, 0x5a, 0x24, 0xff,
+	0x55, 0xb8, 0x38, 0x01, 0xff, 0xa9, 0xcb, 0xa5, 0x6d, 0xca, 0xa5, 0x0b, 0xdd, 0x15, 0xa6, 0x34,
+	0xbc, 0xdf, 0x0b, 0xa9, 0x32, 0xfd, 0x1f, 0x01, 0xe9, 0xf9, 0x55, 0x79, 0x1e, 0xf4, 0x12, 0x81,
+	0xe2, 0x58, 0x25, 0xd8, 0xdb, 0xe8, 0x43, 0xe8, 0x60, 0x4e, 0xc0, 0x98, 0xa8, 0x46, 0x2c, 0x50,
+	0xc7, 0x3e, 0x81, 0x93, 0xc5, 0xae, 0x5a, 0xda, 0x14, 0x3a, 0x2f, 0x73, 0xb2, 0x0a, 0x99, 0x9a,
+	0xa1, 0xb2, 0xde, 0xe8, 0x4f, 0x13, 0x7d, 0x56, 0x6d, 0x73, 0x56, 0x1f, 0x01, 0x5a, 0xec, 0x6a,
+	0xe5, 0x34, 0xf6, 0xf3, 0xea, 0x2f, 0x07, 0xfa, 0x42, 0x41, 0x32, 0x16, 0xa6, 0xe8, 0x1b, 0x18,
+	0x68, 0xcb, 0x0c, 0x4d, 0x79, 0x55, 0xf5, 0x85, 0xec, 0xfd, 0xbf, 0x86, 0xab, 0x1c, 0x5f, 0x40,
+	0x7f, 0xbf, 0x67, 0xd0, 0xa4, 0xf4, 0x2a, 0x55, 0xcc, 0x3b, 0xad, 0xa0, 0x2a, 0xf2, 0x0a, 0xba,
+	0x6a, 0x27, 0x20, 0x24, 0xbf, 0x0e, 0x7d, 0xfd, 0x78, 0x63, 0x03, 0x2b, 0xb3, 0xed, 0x45, 0x5d,
+	0x66, 0xab, 0x2e, 0x0a, 0xef, 0xb4, 0x82, 0xaa, 0xc8, 0xaf, 0x00, 0x4a, 0x35, 0x46, 0xca, 0xa9,
+	0xa2, 0xf1, 0xde, 0xb4, 0x0a, 0x97, 0x54, 0x95, 0xee, 0x4a, 0xaa, 0xa6, 0x4c, 0x7b, 0x63, 0x03,
+	0x53, 0x31, 0x4f, 0xe0, 0x48, 0xd7, 0x47, 0x24, 0x3a, 0xd8, 0x20, 0xc2, 0x9e, 0x5b, 0x3f, 0x50,
+	0x57, 0xcc, 0xe1, 0x7f, 0x86, 0x70, 0x21, 0xe1, 0xda, 0xa4, 0x8f, 0xde, 0x59, 0xc3, 0x49, 0x49,
+	0x44, 0x17, 0x2a, 0x49, 0xa4, 0x41, 0xec, 0x3c, 0xb7, 0x7e, 0xa0, 0xae, 0xf8, 0x0c, 0x7a, 0x85,
+	0x14, 0xa0, 0x71, 0xe1, 0xa5, 0x3d, 0x6f, 0x6f, 0x62, 0x82, 0x2a, 0xec, 0x6b, 0x80, 0xf2, 0x23,
+	0x93, 0x3d, 0xaf, 0x7d, 0xf4, 0xde, 0xb4, 0x0a, 0xcb, 0xe0, 0x73, 0x8b, 0x87, 0x2f, 0x76, 0x66,
+	0xf8, 0x62, 0xd7, 0x18, 0x5e, 0x7f, 0xfb, 0x97, 0xd6, 0x5d, 0x47, 0xfc, 0x63, 0xf0, 0xf8, 0xef,
+	0x01, 0x00, 0xd2, 0x73, 0x05, 0x37, 0x25, 0x0c, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// DVDRentalClient is the client API for DVDRental service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type DVDRentalClient interface {
+	CreateTitle(ctx context.Context, in *CreateTitleRequest, opts ...grpc.CallOption) (*CreateTitleResponse, error)
+	CreateDVD(ctx context.Context, in *CreateDVDRequest, opts ...grpc.CallOption) (*CreateDVDResponse, error)
+	RentDVD(ctx context.Context, in *RentDVDRequest, opts ...grpc.CallOption) (*RentDVDResponse, error)
+	ReturnDVD(ctx context.Context, in *ReturnDVDRequest, opts ...grpc.CallOption) (*ReturnDVDResponse, error)
+	ReleaseDVD(ctx context.Context, in *ReleaseDVDRequest, opts ...grpc.CallOption) (*ReleaseDVDResponse, error)
+	SellDVD(ctx context.Context, in *SellDVDRequest, opts ...grpc.CallOption) (*SellDVDResponse, error)
+	JoinWaitlist(ctx context.Context, in *JoinWaitlistRequest, opts ...grpc.CallOption) (*JoinWaitlistResponse, error)
+	LeaveWaitlist(ctx context.Context, in *LeaveWaitlistRequest, opts ...grpc.CallOption) (*LeaveWaitlistResponse, error)
+	ListWaitlist(ctx context.Context, in *ListWaitlistRequest, opts ...grpc.CallOption) (*ListWaitlistResponse, error)
+	ListDVDs(ctx context.Context, in *ListDVDsRequest, opts ...grpc.CallOption) (*ListDVDsResponse, error)
+	ImportDVDs(ctx context.Context, opts ...grpc.CallOption) (DVDRental_ImportDVDsClient, error)
+	ExportDVDs(ctx context.Context, in *ExportDVDsRequest, opts ...grpc.CallOption) (DVDRental_ExportDVDsClient, error)
+}
+
+type dVDRentalClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewDVDRentalClient(cc *grpc.ClientConn) DVDRentalClient {
+	return &dVDRentalClient{cc}
+}
+
+func (c *dVDRentalClient) CreateTitle(ctx context.Context, in *CreateTitleRequest, opts ...grpc.CallOption) (*CreateTitleResponse, error) {
+	out := new(CreateTitleResponse)
+	err := c.cc.Invoke(ctx, "/pb.DVDRental/CreateTitle", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dVDRentalClient) CreateDVD(ctx context.Context, in *CreateDVDRequest, opts ...grpc.CallOption) (*CreateDVDResponse, error) {
+	out := new(CreateDVDResponse)
+	err := c.cc.Invoke(ctx, "/pb.DVDRental/CreateDVD", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dVDRentalClient) RentDVD(ctx context.Context, in *RentDVDRequest, opts ...grpc.CallOption) (*RentDVDResponse, error) {
+	out := new(RentDVDResponse)
+	err := c.cc.Invoke(ctx, "/pb.DVDRental/RentDVD", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dVDRentalClient) ReturnDVD(ctx context.Context, in *ReturnDVDRequest, opts ...grpc.CallOption) (*ReturnDVDResponse, error) {
+	out := new(ReturnDVDResponse)
+	err := c.cc.Invoke(ctx, "/pb.DVDRental/ReturnDVD", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dVDRentalClient) ReleaseDVD(ctx context.Context, in *ReleaseDVDRequest, opts ...grpc.CallOption) (*ReleaseDVDResponse, error) {
+	out := new(ReleaseDVDResponse)
+	err := c.cc.Invoke(ctx, "/pb.DVDRental/ReleaseDVD", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dVDRentalClient) SellDVD(ctx context.Context, in *SellDVDRequest, opts ...grpc.CallOption) (*SellDVDResponse, error) {
+	out := new(SellDVDResponse)
+	err := c.cc.Invoke(ctx, "/pb.DVDRental/SellDVD", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dVDRentalClient) JoinWaitlist(ctx context.Context, in *JoinWaitlistRequest, opts ...grpc.CallOption) (*JoinWaitlistResponse, error) {
+	out := new(JoinWaitlistResponse)
+	err := c.cc.Invoke(ctx, "/pb.DVDRental/JoinWaitlist", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dVDRentalClient) LeaveWaitlist(ctx context.Context, in *LeaveWaitlistRequest, opts ...grpc.CallOption) (*LeaveWaitlistResponse, error) {
+	out := new(LeaveWaitlistResponse)
+	err := c.cc.Invoke(ctx, "/pb.DVDRental/LeaveWaitlist", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dVDRentalClient) ListWaitlist(ctx context.Context, in *ListWaitlistRequest, opts ...grpc.CallOption) (*ListWaitlistResponse, error) {
+	out := new(ListWaitlistResponse)
+	err := c.cc.Invoke(ctx, "/pb.DVDRental/ListWaitlist", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dVDRentalClient) ListDVDs(ctx context.Context, in *ListDVDsRequest, opts ...grpc.CallOption) (*ListDVDsResponse, error) {
+	out := new(ListDVDsResponse)
+	err := c.cc.Invoke(ctx, "/pb.DVDRental/ListDVDs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dVDRentalClient) ImportDVDs(ctx context.Context, opts ...grpc.CallOption) (DVDRental_ImportDVDsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_DVDRental_serviceDesc.Streams[0], "/pb.DVDRental/ImportDVDs", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dVDRentalImportDVDsClient{stream}
+	return x, nil
+}
+
+type DVDRental_ImportDVDsClient interface {
+	Send(*ImportDVDsRequest) error
+	CloseAndRecv() (*ImportDVDsResponse, error)
+	grpc.ClientStream
+}
+
+type dVDRentalImportDVDsClient struct {
+	grpc.ClientStream
+}
+
+func (x *dVDRentalImportDVDsClient) Send(m *ImportDVDsRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *dVDRentalImportDVDsClient) CloseAndRecv() (*ImportDVDsResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(ImportDVDsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *dVDRentalClient) ExportDVDs(ctx context.Context, in *ExportDVDsRequest, opts ...grpc.CallOption) (DVDRental_ExportDVDsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_DVDRental_serviceDesc.Streams[1], "/pb.DVDRental/ExportDVDs", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dVDRentalExportDVDsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DVDRental_ExportDVDsClient interface {
+	Recv() (*ExportDVDsResponse, error)
+	grpc.ClientStream
+}
+
+type dVDRentalExportDVDsClient struct {
+	grpc.ClientStream
+}
+
+func (x *dVDRentalExportDVDsClient) Recv() (*ExportDVDsResponse, error) {
+	m := new(ExportDVDsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// DVDRentalServer is the server API for DVDRental service.
+type DVDRentalServer interface {
+	CreateTitle(context.Context, *CreateTitleRequest) (*CreateTitleResponse, error)
+	CreateDVD(context.Context, *CreateDVDRequest) (*CreateDVDResponse, error)
+	RentDVD(context.Context, *RentDVDRequest) (*RentDVDResponse, error)
+	ReturnDVD(context.Context, *ReturnDVDRequest) (*ReturnDVDResponse, error)
+	ReleaseDVD(context.Context, *ReleaseDVDRequest) (*ReleaseDVDResponse, error)
+	SellDVD(context.Context, *SellDVDRequest) (*SellDVDResponse, error)
+	JoinWaitlist(context.Context, *JoinWaitlistRequest) (*JoinWaitlistResponse, error)
+	LeaveWaitlist(context.Context, *LeaveWaitlistRequest) (*LeaveWaitlistResponse, error)
+	ListWaitlist(context.Context, *ListWaitlistRequest) (*ListWaitlistResponse, error)
+	ListDVDs(context.Context, *ListDVDsRequest) (*ListDVDsResponse, error)
+	ImportDVDs(DVDRental_ImportDVDsServer) error
+	ExportDVDs(*ExportDVDsRequest, DVDRental_ExportDVDsServer) error
+}
+
+// UnimplementedDVDRentalServer can be embedded to have forward compatible implementations.
 type UnimplementedDVDRentalServer struct {
 }
 
+func (*UnimplementedDVDRentalServer) CreateTitle(ctx context.Context, req *CreateTitleRequest) (*CreateTitleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateTitle not implemented")
+}
 func (*UnimplementedDVDRentalServer) CreateDVD(ctx context.Context, req *CreateDVDRequest) (*CreateDVDResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateDVD not implemented")
 }
 func (*UnimplementedDVDRentalServer) RentDVD(ctx context.Context, req *RentDVDRequest) (*RentDVDResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RentDVD not implemented")
 }
+func (*UnimplementedDVDRentalServer) ReturnDVD(ctx context.Context, req *ReturnDVDRequest) (*ReturnDVDResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReturnDVD not implemented")
+}
+func (*UnimplementedDVDRentalServer) ReleaseDVD(ctx context.Context, req *ReleaseDVDRequest) (*ReleaseDVDResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReleaseDVD not implemented")
+}
+func (*UnimplementedDVDRentalServer) SellDVD(ctx context.Context, req *SellDVDRequest) (*SellDVDResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SellDVD not implemented")
+}
+func (*UnimplementedDVDRentalServer) JoinWaitlist(ctx context.Context, req *JoinWaitlistRequest) (*JoinWaitlistResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method JoinWaitlist not implemented")
+}
+func (*UnimplementedDVDRentalServer) LeaveWaitlist(ctx context.Context, req *LeaveWaitlistRequest) (*LeaveWaitlistResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LeaveWaitlist not implemented")
+}
+func (*UnimplementedDVDRentalServer) ListWaitlist(ctx context.Context, req *ListWaitlistRequest) (*ListWaitlistResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWaitlist not implemented")
+}
+func (*UnimplementedDVDRentalServer) ListDVDs(ctx context.Context, req *ListDVDsRequest) (*ListDVDsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDVDs not implemented")
+}
+func (*UnimplementedDVDRentalServer) ImportDVDs(srv DVDRental_ImportDVDsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ImportDVDs not implemented")
+}
+func (*UnimplementedDVDRentalServer) ExportDVDs(req *ExportDVDsRequest, srv DVDRental_ExportDVDsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportDVDs not implemented")
+}
 
 func RegisterDVDRentalServer(s *grpc.Server, srv DVDRentalServer) {
 	s.RegisterService(&_DVDRental_serviceDesc, srv)
 }
 
+func _DVDRental_CreateTitle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateTitleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DVDRentalServer).CreateTitle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.DVDRental/CreateTitle",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DVDRentalServer).CreateTitle(ctx, req.(*CreateTitleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _DVDRental_CreateDVD_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(CreateDVDRequest)
 	if err := dec(in); err != nil {
@@ -304,10 +1782,187 @@ func _DVDRental_RentDVD_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DVDRental_ReturnDVD_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReturnDVDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DVDRentalServer).ReturnDVD(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.DVDRental/ReturnDVD",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DVDRentalServer).ReturnDVD(ctx, req.(*ReturnDVDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DVDRental_ReleaseDVD_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReleaseDVDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DVDRentalServer).ReleaseDVD(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.DVDRental/ReleaseDVD",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DVDRentalServer).ReleaseDVD(ctx, req.(*ReleaseDVDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DVDRental_SellDVD_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SellDVDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DVDRentalServer).SellDVD(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.DVDRental/SellDVD",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DVDRentalServer).SellDVD(ctx, req.(*SellDVDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DVDRental_JoinWaitlist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JoinWaitlistRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DVDRentalServer).JoinWaitlist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.DVDRental/JoinWaitlist",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DVDRentalServer).JoinWaitlist(ctx, req.(*JoinWaitlistRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DVDRental_LeaveWaitlist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LeaveWaitlistRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DVDRentalServer).LeaveWaitlist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.DVDRental/LeaveWaitlist",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DVDRentalServer).LeaveWaitlist(ctx, req.(*LeaveWaitlistRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DVDRental_ListWaitlist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWaitlistRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DVDRentalServer).ListWaitlist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.DVDRental/ListWaitlist",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DVDRentalServer).ListWaitlist(ctx, req.(*ListWaitlistRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DVDRental_ListDVDs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDVDsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DVDRentalServer).ListDVDs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.DVDRental/ListDVDs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DVDRentalServer).ListDVDs(ctx, req.(*ListDVDsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DVDRental_ImportDVDs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(DVDRentalServer).ImportDVDs(&dVDRentalImportDVDsServer{stream})
+}
+
+type DVDRental_ImportDVDsServer interface {
+	SendAndClose(*ImportDVDsResponse) error
+	Recv() (*ImportDVDsRequest, error)
+	grpc.ServerStream
+}
+
+type dVDRentalImportDVDsServer struct {
+	grpc.ServerStream
+}
+
+func (x *dVDRentalImportDVDsServer) SendAndClose(m *ImportDVDsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *dVDRentalImportDVDsServer) Recv() (*ImportDVDsRequest, error) {
+	m := new(ImportDVDsRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _DVDRental_ExportDVDs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportDVDsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DVDRentalServer).ExportDVDs(m, &dVDRentalExportDVDsServer{stream})
+}
+
+type DVDRental_ExportDVDsServer interface {
+	Send(*ExportDVDsResponse) error
+	grpc.ServerStream
+}
+
+type dVDRentalExportDVDsServer struct {
+	grpc.ServerStream
+}
+
+func (x *dVDRentalExportDVDsServer) Send(m *ExportDVDsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _DVDRental_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "pb.DVDRental",
 	HandlerType: (*DVDRentalServer)(nil),
 	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateTitle",
+			Handler:    _DVDRental_CreateTitle_Handler,
+		},
 		{
 			MethodName: "CreateDVD",
 			Handler:    _DVDRental_CreateDVD_Handler,
@@ -316,7 +1971,46 @@ var _DVDRental_serviceDesc = grpc.ServiceDesc{
 			MethodName: "RentDVD",
 			Handler:    _DVDRental_RentDVD_Handler,
 		},
+		{
+			MethodName: "ReturnDVD",
+			Handler:    _DVDRental_ReturnDVD_Handler,
+		},
+		{
+			MethodName: "ReleaseDVD",
+			Handler:    _DVDRental_ReleaseDVD_Handler,
+		},
+		{
+			MethodName: "SellDVD",
+			Handler:    _DVDRental_SellDVD_Handler,
+		},
+		{
+			MethodName: "JoinWaitlist",
+			Handler:    _DVDRental_JoinWaitlist_Handler,
+		},
+		{
+			MethodName: "LeaveWaitlist",
+			Handler:    _DVDRental_LeaveWaitlist_Handler,
+		},
+		{
+			MethodName: "ListWaitlist",
+			Handler:    _DVDRental_ListWaitlist_Handler,
+		},
+		{
+			MethodName: "ListDVDs",
+			Handler:    _DVDRental_ListDVDs_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ImportDVDs",
+			Handler:       _DVDRental_ImportDVDs_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ExportDVDs",
+			Handler:       _DVDRental_ExportDVDs_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "dvd.proto",
 }
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"github.com/ngray1747/dvd-rental/internal/migrate"
+	"github.com/ngray1747/dvd-rental/internal/outbox"
+)
+
+// Migrations builds the dvd database schema. Tables are created only if missing so databases
+// created before migrations existed are adopted as they are.
+var Migrations = []migrate.Migration{
+	{
+		Version: 1,
+		Name:    "create_dvds",
+		Up: `CREATE TABLE IF NOT EXISTS dvds (
+			id uuid NOT NULL,
+			"name" varchar(255) NULL,
+			status int2 NULL,
+			created_at timestamptz NULL,
+			updated_at timestamptz NULL,
+			deleted_at timestamptz NULL,
+			CONSTRAINT dvds_pkey PRIMARY KEY (id)
+		)`,
+		Down: `DROP TABLE dvds`,
+	},
+	{
+		Version: 2,
+		Name:    "split_dvds_into_titles_and_copies",
+		//* Every dvd becomes a title with a single copy, barcoded with its id
+		Up: `CREATE TABLE IF NOT EXISTS titles (
+			id uuid NOT NULL,
+			"name" varchar(255) NOT NULL,
+			"year" int4 NULL,
+			runtime int4 NULL,
+			rating varchar(16) NULL,
+			genres text[] NULL,
+			replacement_cost int8 NOT NULL DEFAULT 0,
+			created_at timestamptz NULL,
+			updated_at timestamptz NULL,
+			deleted_at timestamptz NULL,
+			CONSTRAINT titles_pkey PRIMARY KEY (id)
+		);
+		CREATE TABLE IF NOT EXISTS copies (
+			id uuid NOT NULL,
+			title_id uuid NOT NULL REFERENCES titles (id),
+			barcode varchar(64) NOT NULL UNIQUE,
+			"condition" varchar(16) NOT NULL,
+			status int2 NULL,
+			created_at timestamptz NULL,
+			updated_at timestamptz NULL,
+			deleted_at timestamptz NULL,
+			CONSTRAINT copies_pkey PRIMARY KEY (id)
+		);
+		INSERT INTO titles (id, "name", created_at, updated_at, deleted_at)
+			SELECT id, coalesce("name", ''), created_at, updated_at, deleted_at FROM dvds;
+		INSERT INTO copies (id, title_id, barcode, "condition", status, created_at, updated_at, deleted_at)
+			SELECT id, id, id::text, 'good', status, created_at, updated_at, deleted_at FROM dvds;
+		DROP TABLE dvds`,
+		//* Titles with several copies come back as several dvds of the same name
+		Down: `CREATE TABLE dvds (
+			id uuid NOT NULL,
+			"name" varchar(255) NULL,
+			status int2 NULL,
+			created_at timestamptz NULL,
+			updated_at timestamptz NULL,
+			deleted_at timestamptz NULL,
+			CONSTRAINT dvds_pkey PRIMARY KEY (id)
+		);
+		INSERT INTO dvds (id, "name", status, created_at, updated_at, deleted_at)
+			SELECT c.id, t."name", c.status, c.created_at, c.updated_at, c.deleted_at
+			FROM copies c JOIN titles t ON t.id = c.title_id;
+		DROP TABLE copies;
+		DROP TABLE titles`,
+	},
+	{
+		Version: 3,
+		Name:    "create_reservations",
+		Up: `CREATE TABLE IF NOT EXISTS reservations (
+			id uuid NOT NULL,
+			title_id uuid NOT NULL REFERENCES titles (id),
+			customer_id varchar(64) NOT NULL,
+			created_at timestamptz NULL,
+			updated_at timestamptz NULL,
+			deleted_at timestamptz NULL,
+			CONSTRAINT reservations_pkey PRIMARY KEY (id)
+		);
+		ALTER TABLE copies ADD COLUMN IF NOT EXISTS reserved_for varchar(64) NULL;
+		ALTER TABLE copies ADD COLUMN IF NOT EXISTS held_until timestamptz NULL`,
+		Down: `ALTER TABLE copies DROP COLUMN held_until;
+			ALTER TABLE copies DROP COLUMN reserved_for;
+			DROP TABLE reservations`,
+	},
+	{
+		Version: 4,
+		Name:    "add_copy_rented_by",
+		Up:      `ALTER TABLE copies ADD COLUMN IF NOT EXISTS rented_by varchar(64) NULL`,
+		Down:    `ALTER TABLE copies DROP COLUMN rented_by`,
+	},
+	outbox.Migration(5),
+	{
+		Version: 6,
+		Name:    "add_versions",
+		Up: `ALTER TABLE titles ADD COLUMN IF NOT EXISTS version int4 NOT NULL DEFAULT 1;
+			ALTER TABLE copies ADD COLUMN IF NOT EXISTS version int4 NOT NULL DEFAULT 1;
+			ALTER TABLE reservations ADD COLUMN IF NOT EXISTS version int4 NOT NULL DEFAULT 1`,
+		Down: `ALTER TABLE reservations DROP COLUMN version;
+			ALTER TABLE copies DROP COLUMN version;
+			ALTER TABLE titles DROP COLUMN version`,
+	},
+	{
+		Version: 7,
+		Name:    "add_waitlist_index",
+		//* A customer waits once for a title, see waitlistIndex. Concurrent joins may have queued a customer twice,
+		//* the first place in the queue is kept.
+		Up: `DELETE FROM reservations r USING reservations o
+			WHERE r.title_id = o.title_id AND r.customer_id = o.customer_id
+			AND (r.created_at > o.created_at OR (r.created_at = o.created_at AND r.id > o.id));
+			CREATE UNIQUE INDEX IF NOT EXISTS reservations_title_customer_idx ON reservations (title_id, customer_id)`,
+		Down: `DROP INDEX reservations_title_customer_idx`,
+	},
+}
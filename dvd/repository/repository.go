@@ -1,19 +1,40 @@
 package repository
 
 import (
-	"errors"
+	"strings"
+	"time"
 
 	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/go-redis/redis/v7"
 	"github.com/ngray1747/dvd-rental/dvd"
+	"github.com/ngray1747/dvd-rental/internal/apperr"
 	"github.com/ngray1747/dvd-rental/internal/config"
+	"github.com/ngray1747/dvd-rental/internal/model"
+	"github.com/ngray1747/dvd-rental/internal/outbox"
 )
 
 var (
-	errDVDNotAvailable = errors.New("dvd not available")
+	errDVDNotAvailable  = apperr.New(apperr.Conflict, "dvd not available")
+	errDVDNotRented     = apperr.New(apperr.Conflict, "dvd is not rented")
+	errDVDSold          = apperr.New(apperr.Conflict, "dvd has been sold")
+	errDVDReserved      = apperr.New(apperr.Conflict, "dvd is reserved for another customer")
+	errDVDRentedByOther = apperr.New(apperr.Conflict, "dvd is rented by another customer")
+	errAlreadyWaiting   = apperr.New(apperr.Conflict, "customer is already on the waitlist")
+	errNotWaiting       = apperr.New(apperr.NotFound, "customer is not on the waitlist")
+)
+
+const (
+	// titleCacheKeySuffix is appended to the service cache key to hold titles
+	titleCacheKeySuffix = ":titles"
+	// waitlistIndex keeps a customer from waiting twice for a title
+	waitlistIndex = "reservations_title_customer_idx"
 )
 
 type Cache interface {
-	StoreToCache(key string, value dvd.DVD) error
+	StoreToCache(key string, value dvd.Copy) error
+	StoreTitleToCache(key string, value dvd.Title) error
+	GetTitleFromCache(key, field string) (*dvd.Title, error)
 }
 
 type dvdRepository struct {
@@ -22,51 +43,439 @@ type dvdRepository struct {
 	cache Cache
 }
 
-//NewDVDRepository create a new dvd repository.
+// NewDVDRepository create a new dvd repository.
 func NewDVDRepository(cfg *config.Cache, db *pg.DB, cache Cache) dvd.Repository {
 	return &dvdRepository{cfg: cfg, db: db, cache: cache}
 }
 
-func (cr *dvdRepository) Store(d *dvd.DVD) error {
+func (cr *dvdRepository) titleCacheKey() string {
+	return cr.cfg.CacheKey + titleCacheKeySuffix
+}
+
+// commit commits tx then caches copies, so the cache never holds a change that was rolled back.
+// A failed cache write is reported even though the change is committed.
+func (cr *dvdRepository) commit(tx *pg.Tx, copies ...*dvd.Copy) error {
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	for _, c := range copies {
+		if err := cr.cache.StoreToCache(cr.cfg.CacheKey, *c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// commitTitle commits tx then caches t, see commit
+func (cr *dvdRepository) commitTitle(tx *pg.Tx, t *dvd.Title) error {
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return cr.cache.StoreTitleToCache(cr.titleCacheKey(), *t)
+}
+
+func (cr *dvdRepository) StoreTitle(t *dvd.Title) error {
 	tx, err := cr.db.Begin()
 	if err != nil {
 		return err
 	}
 	// Rollback tx on error.
 	defer tx.Close()
-	if err := tx.Insert(d); err != nil {
+	if err := tx.Insert(t); err != nil {
 		return err
 	}
+	return cr.commitTitle(tx, t)
+}
+
+func (cr *dvdRepository) GetTitle(id string) (*dvd.Title, error) {
+	//* Get data from cache first
+	t, err := cr.cache.GetTitleFromCache(cr.titleCacheKey(), id)
+	if err != nil && err != redis.Nil {
+		return nil, err
+	} else if err == redis.Nil {
+		t = &dvd.Title{
+			Base: model.Base{
+				ID: id,
+			},
+		}
+		// Get from database
+		if err := cr.db.Select(t); err != nil {
+			return nil, err
+		}
+		// Set back to cache
+		if err = cr.cache.StoreTitleToCache(cr.titleCacheKey(), *t); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
 
-	if err := cr.cache.StoreToCache(cr.cfg.CacheKey, *d); err != nil {
+func (cr *dvdRepository) UpdateTitle(t *dvd.Title) error {
+	tx, err := cr.db.Begin()
+	if err != nil {
 		return err
 	}
-	return tx.Commit()
+	// Rollback tx on error.
+	defer tx.Close()
+	if err := model.Update(tx, t); err != nil {
+		return err
+	}
+	return cr.commitTitle(tx, t)
 }
 
-func (cr *dvdRepository) Update(id string, status dvd.Status) error {
+func (cr *dvdRepository) Store(c *dvd.Copy) error {
 	tx, err := cr.db.Begin()
 	if err != nil {
 		return err
 	}
 	// Rollback tx on error.
 	defer tx.Close()
-	d := new(dvd.DVD)
-	if err := tx.Model(d).Where("id = ?", id).Select(); err != nil {
+	if err := tx.Insert(c); err != nil {
+		return err
+	}
+	if err := outbox.Add(tx, outbox.DVDCreated, c.ID, c); err != nil {
 		return err
 	}
-	
-	if d.Status == dvd.NotAvailable {
+	return cr.commit(tx, c)
+}
+
+func (cr *dvdRepository) StoreMany(copies []*dvd.Copy) error {
+	if len(copies) == 0 {
+		return nil
+	}
+	tx, err := cr.db.Begin()
+	if err != nil {
+		return err
+	}
+	// Rollback tx on error.
+	defer tx.Close()
+	if _, err := tx.Model(&copies).Insert(); err != nil {
+		return err
+	}
+	for _, c := range copies {
+		if err := outbox.Add(tx, outbox.DVDCreated, c.ID, c); err != nil {
+			return err
+		}
+	}
+	return cr.commit(tx, copies...)
+}
+
+func (cr *dvdRepository) TakenBarcodes(barcodes []string) ([]string, error) {
+	var taken []string
+	if len(barcodes) == 0 {
+		return taken, nil
+	}
+	err := cr.db.Model((*dvd.Copy)(nil)).
+		Column("barcode").
+		WhereIn("barcode IN (?)", barcodes).
+		//* Barcodes stay unique among deleted copies too
+		AllWithDeleted().
+		Select(&taken)
+	if err != nil {
+		return nil, err
+	}
+	return taken, nil
+}
+
+func (cr *dvdRepository) Get(id string) (*dvd.Copy, error) {
+	c := new(dvd.Copy)
+	if err := cr.db.Model(c).Relation("Title").Where("?TableAlias.id = ?", id).Select(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (cr *dvdRepository) Update(id string, status dvd.Status) (*dvd.Copy, error) {
+	tx, err := cr.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	// Rollback tx on error.
+	defer tx.Close()
+	c := new(dvd.Copy)
+	//* The copy is not locked, a concurrent change of it fails the update with model.ErrStale
+	if err := tx.Model(c).Where("id = ?", id).Select(); err != nil {
+		return nil, err
+	}
+
+	if !c.Status.CanBecome(status) {
+		return nil, transitionError(c.Status, status)
+	}
+
+	c.Status = status
+	if err := model.Update(tx, c); err != nil {
+		return nil, err
+	}
+	return c, cr.commit(tx, c)
+}
+
+// transitionError explains why a copy in status from can not be moved to status to
+func transitionError(from, to dvd.Status) error {
+	switch {
+	case from == dvd.Sold:
+		return errDVDSold
+	case from == dvd.Reserved:
+		return errDVDReserved
+	case to == dvd.Available:
+		return errDVDNotRented
+	default:
 		return errDVDNotAvailable
 	}
+}
+
+func (cr *dvdRepository) Rent(id, customerID string) (*dvd.Copy, error) {
+	tx, err := cr.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	// Rollback tx on error.
+	defer tx.Close()
+	c := new(dvd.Copy)
+	if err := tx.Model(c).Where("id = ?", id).For("UPDATE").Select(); err != nil {
+		return nil, err
+	}
+
+	if (c.Status == dvd.Reserved && c.ReservedFor != customerID) || !c.Status.CanBecome(dvd.NotAvailable) {
+		return nil, transitionError(c.Status, dvd.NotAvailable)
+	}
+	if err := cr.rent(tx, c, customerID); err != nil {
+		return nil, err
+	}
+	return c, cr.commit(tx, c)
+}
+
+func (cr *dvdRepository) RentAnyCopy(titleID, customerID string) (*dvd.Copy, error) {
+	tx, err := cr.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	// Rollback tx on error.
+	defer tx.Close()
+	c := new(dvd.Copy)
+	//* A copy held for the customer comes first, copies being rented by concurrent transactions are skipped
+	err = tx.Model(c).
+		Where("title_id = ?", titleID).
+		WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+			return q.WhereOr("status = ?", dvd.Available).
+				WhereOr("status = ? AND reserved_for = ?", dvd.Reserved, customerID), nil
+		}).
+		OrderExpr("status = ? DESC", dvd.Reserved).
+		Order("created_at ASC").
+		Limit(1).
+		For("UPDATE SKIP LOCKED").
+		Select()
+	if err == pg.ErrNoRows {
+		return nil, errDVDNotAvailable
+	} else if err != nil {
+		return nil, err
+	}
 
-	d.Status = status
-	if err := tx.Update(d); err != nil {
+	if err := cr.rent(tx, c, customerID); err != nil {
+		return nil, err
+	}
+	return c, cr.commit(tx, c)
+}
+
+func (cr *dvdRepository) rent(tx *pg.Tx, c *dvd.Copy, customerID string) error {
+	c.Status = dvd.NotAvailable
+	c.RentedBy = customerID
+	c.ReservedFor = ""
+	c.HeldUntil = time.Time{}
+	if err := model.Update(tx, c); err != nil {
 		return err
 	}
-	
-	if err := cr.cache.StoreToCache(cr.cfg.CacheKey, *d); err != nil {
+	return outbox.Add(tx, outbox.DVDRented, c.ID, c)
+}
+
+func (cr *dvdRepository) Return(id, customerID string, hold time.Duration) (*dvd.Copy, error) {
+	tx, err := cr.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	// Rollback tx on error.
+	defer tx.Close()
+	c := new(dvd.Copy)
+	if err := tx.Model(c).Where("id = ?", id).For("UPDATE").Select(); err != nil {
+		return nil, err
+	}
+
+	if c.Status != dvd.NotAvailable {
+		return nil, transitionError(c.Status, dvd.Available)
+	}
+	if c.RentedBy != customerID {
+		return nil, errDVDRentedByOther
+	}
+	if err := cr.release(tx, c, hold); err != nil {
+		return nil, err
+	}
+	return c, cr.commit(tx, c)
+}
+
+func (cr *dvdRepository) Release(id, customerID string, hold time.Duration) (*dvd.Copy, error) {
+	tx, err := cr.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	// Rollback tx on error.
+	defer tx.Close()
+	c := new(dvd.Copy)
+	if err := tx.Model(c).Where("id = ?", id).For("UPDATE").Select(); err != nil {
+		return nil, err
+	}
+
+	//* Releasing twice, or a copy the customer never got, must leave it alone
+	if c.Status != dvd.NotAvailable || c.RentedBy != customerID {
+		return c, nil
+	}
+	if err := cr.release(tx, c, hold); err != nil {
+		return nil, err
+	}
+	return c, cr.commit(tx, c)
+}
+
+func (cr *dvdRepository) ExpireHolds(hold time.Duration) (int, error) {
+	tx, err := cr.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	// Rollback tx on error.
+	defer tx.Close()
+	var copies []*dvd.Copy
+	err = tx.Model(&copies).
+		Where("status = ?", dvd.Reserved).
+		Where("held_until < ?", time.Now()).
+		For("UPDATE SKIP LOCKED").
+		Select()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, c := range copies {
+		if err := cr.release(tx, c, hold); err != nil {
+			return 0, err
+		}
+	}
+	return len(copies), cr.commit(tx, copies...)
+}
+
+// release holds a copy for the first customer of its title's waitlist, who leaves the waitlist,
+// or puts it back on the shelf when nobody is waiting
+func (cr *dvdRepository) release(tx *pg.Tx, c *dvd.Copy, hold time.Duration) error {
+	r := new(dvd.Reservation)
+	err := tx.Model(r).
+		Where("title_id = ?", c.TitleID).
+		Order("created_at ASC", "id ASC").
+		Limit(1).
+		For("UPDATE SKIP LOCKED").
+		Select()
+	c.RentedBy = ""
+	switch {
+	case err == pg.ErrNoRows:
+		c.Status = dvd.Available
+		c.ReservedFor = ""
+		c.HeldUntil = time.Time{}
+	case err != nil:
+		return err
+	default:
+		if _, err := tx.Model(r).WherePK().ForceDelete(); err != nil {
+			return err
+		}
+		c.Status = dvd.Reserved
+		c.ReservedFor = r.CustomerID
+		c.HeldUntil = time.Now().Add(hold)
+	}
+
+	return model.Update(tx, c)
+}
+
+func (cr *dvdRepository) Reserve(r *dvd.Reservation) error {
+	//* The index turns down the second of two concurrent joins, which a check before inserting would let through
+	if err := cr.db.Insert(r); err != nil {
+		if model.IsUniqueViolation(err, waitlistIndex) {
+			return errAlreadyWaiting
+		}
+		return err
+	}
+	return nil
+}
+
+func (cr *dvdRepository) CancelReservation(titleID, customerID string, hold time.Duration) error {
+	tx, err := cr.db.Begin()
+	if err != nil {
+		return err
+	}
+	// Rollback tx on error.
+	defer tx.Close()
+	res, err := tx.Model((*dvd.Reservation)(nil)).
+		Where("title_id = ?", titleID).
+		Where("customer_id = ?", customerID).
+		ForceDelete()
+	if err != nil {
+		return err
+	}
+
+	//* A customer whose turn has come is no longer on the waitlist but holds a copy
+	var held []*dvd.Copy
+	err = tx.Model(&held).
+		Where("title_id = ?", titleID).
+		Where("status = ?", dvd.Reserved).
+		Where("reserved_for = ?", customerID).
+		For("UPDATE").
+		Select()
+	if err != nil {
 		return err
 	}
-	return tx.Commit()
+	if res.RowsAffected() == 0 && len(held) == 0 {
+		return errNotWaiting
+	}
+
+	for _, c := range held {
+		if err := cr.release(tx, c, hold); err != nil {
+			return err
+		}
+	}
+	return cr.commit(tx, held...)
 }
+
+func (cr *dvdRepository) ListReservations(titleID string) ([]*dvd.Reservation, error) {
+	var reservations []*dvd.Reservation
+	err := cr.db.Model(&reservations).
+		Where("title_id = ?", titleID).
+		Order("created_at ASC", "id ASC").
+		Select()
+	if err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
+
+func (cr *dvdRepository) List(after *dvd.Cursor, limit int) ([]*dvd.Copy, error) {
+	return cr.Search(dvd.Filter{}, after, limit)
+}
+
+func (cr *dvdRepository) Search(f dvd.Filter, after *dvd.Cursor, limit int) ([]*dvd.Copy, error) {
+	var copies []*dvd.Copy
+	q := cr.db.Model(&copies).
+		Relation("Title").
+		Order("?TableAlias.created_at ASC", "?TableAlias.id ASC").
+		Limit(limit)
+	if f.Name != "" {
+		q = q.Where("title.name ILIKE ?", "%"+likeEscaper.Replace(f.Name)+"%")
+	}
+	if f.TitleID != "" {
+		q = q.Where("?TableAlias.title_id = ?", f.TitleID)
+	}
+	if f.Status != 0 {
+		q = q.Where("?TableAlias.status = ?", f.Status)
+	}
+	if after != nil {
+		q = q.Where("(?TableAlias.created_at, ?TableAlias.id) > (?, ?)", after.CreatedAt, after.ID)
+	}
+	if err := q.Select(); err != nil {
+		return nil, err
+	}
+	return copies, nil
+}
+
+// likeEscaper escapes LIKE wildcards so names are matched literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
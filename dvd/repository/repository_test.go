@@ -2,19 +2,25 @@ package repository_test
 
 import (
 	// "database/sql"
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
+	kitlog "github.com/go-kit/kit/log"
 	"github.com/go-pg/pg/v9"
 	"github.com/go-redis/redis/v7"
 	"github.com/ngray1747/dvd-rental/dvd"
 	"github.com/ngray1747/dvd-rental/dvd/cache"
 	"github.com/ngray1747/dvd-rental/dvd/repository"
+	"github.com/ngray1747/dvd-rental/internal/apperr"
 	"github.com/ngray1747/dvd-rental/internal/config"
+	"github.com/ngray1747/dvd-rental/internal/migrate"
 	"github.com/ngray1747/dvd-rental/internal/model"
+	"github.com/ngray1747/dvd-rental/internal/outbox"
 	"github.com/ory/dockertest"
 	"github.com/stretchr/testify/assert"
 )
@@ -42,19 +48,14 @@ func TestMain(m *testing.M) {
 			panic(err)
 		}
 		db = pg.Connect(pgConnectionString)
-		_, err = db.Exec(`CREATE TABLE public.dvds (
-				id uuid NOT NULL,
-				"name" varchar(255) NULL,
-				status int2 NULL,
-				created_at timestamptz NULL,
-				updated_at timestamptz NULL,
-				deleted_at timestamptz NULL,
-				CONSTRAINT dvds_pkey PRIMARY KEY (id)
-			);`)
+		_, err = db.Exec("SELECT 1")
 		return err
 	}); err != nil {
 		log.Fatalf("Could not connect to docker: %s", err)
 	}
+	if err := migrateDB(db); err != nil {
+		log.Fatalf("Could not migrate database: %s", err)
+	}
 
 	cacheResource, err := pool.Run("bitnami/redis", "latest", []string{"ALLOW_EMPTY_PASSWORD=yes"})
 	if err != nil {
@@ -86,11 +87,37 @@ func TestMain(m *testing.M) {
 	os.Exit(code)
 }
 
+// migrateDB applies the migrations, rolls them all back and applies them again so both ways are exercised
+func migrateDB(db *pg.DB) error {
+	migrator, err := migrate.New(db, repository.Migrations)
+	if err != nil {
+		return err
+	}
+	if _, err := migrator.Up(); err != nil {
+		return err
+	}
+	for range repository.Migrations {
+		if _, err := migrator.Down(); err != nil {
+			return err
+		}
+	}
+	_, err = migrator.Up()
+	return err
+}
+
 func TestStore(t *testing.T) {
 	cacheCli := cache.NewCacheClient(cacheClient)
 	repo := repository.NewDVDRepository(cacheConfig, db, cacheCli)
+	title := &dvd.Title{
+		Base: model.Base{
+			ID: "0b7a1c3e-52a4-4c1b-8d7e-3f6b2f0d9a01",
+		},
+		Name: "Title 1",
+	}
+	assert.NoError(t, repo.StoreTitle(title))
+
 	type args struct {
-		dvd *dvd.DVD
+		copy *dvd.Copy
 	}
 	cases := []struct {
 		name    string
@@ -100,23 +127,360 @@ func TestStore(t *testing.T) {
 		{
 			name: "OK",
 			args: args{
-				dvd: &dvd.DVD{
+				copy: &dvd.Copy{
 					Base: model.Base{
-						ID:      "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+						ID:        "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
 						CreatedAt: time.Now(),
 						UpdatedAt: time.Now(),
 					},
-					Name:    "Title 1",
-					Status: dvd.Available,
+					TitleID:   title.ID,
+					Barcode:   "0001",
+					Condition: dvd.New,
+					Status:    dvd.Available,
 				},
 			},
 			wantErr: false,
 		},
+		{
+			name: "unknown title",
+			args: args{
+				copy: &dvd.Copy{
+					Base: model.Base{
+						ID: "5e8b83c9-36f3-4084-94b5-33153246d534",
+					},
+					TitleID:   "66d112da-07e3-41de-bce3-86fe2bd52b24",
+					Barcode:   "0002",
+					Condition: dvd.New,
+					Status:    dvd.Available,
+				},
+			},
+			wantErr: true,
+		},
 	}
 	for _, v := range cases {
 		t.Run(v.name, func(t *testing.T) {
-			err := repo.Store(v.args.dvd)
+			err := repo.Store(v.args.copy)
 			assert.Equal(t, v.wantErr, err != nil)
 		})
 	}
 }
+
+func TestRentAnyCopy(t *testing.T) {
+	cacheCli := cache.NewCacheClient(cacheClient)
+	repo := repository.NewDVDRepository(cacheConfig, db, cacheCli)
+	title, err := dvd.NewTitle("Rent Any", 2000, 90, "PG", nil, 1999)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.StoreTitle(title))
+	for _, barcode := range []string{"rent-any-1", "rent-any-2"} {
+		c, err := dvd.NewCopy(title.ID, barcode, dvd.Good)
+		assert.NoError(t, err)
+		assert.NoError(t, repo.Store(c))
+	}
+
+	first, err := repo.RentAnyCopy(title.ID, "")
+	assert.NoError(t, err)
+	second, err := repo.RentAnyCopy(title.ID, "")
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.ID, second.ID)
+
+	_, err = repo.RentAnyCopy(title.ID, "")
+	assert.Error(t, err)
+
+	_, err = repo.Return(first.ID, "", time.Hour)
+	assert.NoError(t, err)
+	_, err = repo.Return(first.ID, "", time.Hour)
+	assert.Error(t, err)
+
+	_, err = repo.Update(second.ID, dvd.Sold)
+	assert.Error(t, err, "a rented copy can not be sold")
+	_, err = repo.Update(first.ID, dvd.Sold)
+	assert.NoError(t, err)
+	_, err = repo.Update(first.ID, dvd.NotAvailable)
+	assert.Error(t, err, "a sold copy can not be rented")
+}
+
+func TestRelease(t *testing.T) {
+	cacheCli := cache.NewCacheClient(cacheClient)
+	repo := repository.NewDVDRepository(cacheConfig, db, cacheCli)
+	title, err := dvd.NewTitle("Release", 2001, 95, "PG", nil, 1999)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.StoreTitle(title))
+	c, err := dvd.NewCopy(title.ID, "release-1", dvd.Good)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Store(c))
+
+	customerID := "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7"
+	rented, err := repo.Rent(c.ID, customerID)
+	assert.NoError(t, err)
+	assert.Equal(t, customerID, rented.RentedBy)
+
+	kept, err := repo.Release(c.ID, "5e8b83c9-36f3-4084-94b5-33153246d534", time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, dvd.NotAvailable, kept.Status, "only the renter releases a copy")
+
+	released, err := repo.Release(c.ID, customerID, time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, dvd.Available, released.Status)
+	assert.Empty(t, released.RentedBy)
+
+	again, err := repo.Release(c.ID, customerID, time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, dvd.Available, again.Status)
+}
+
+func TestStoreMany(t *testing.T) {
+	cacheCli := cache.NewCacheClient(cacheClient)
+	repo := repository.NewDVDRepository(cacheConfig, db, cacheCli)
+	title, err := dvd.NewTitle("Store Many", 2005, 115, "PG", nil, 1999)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.StoreTitle(title))
+	var copies []*dvd.Copy
+	for _, barcode := range []string{"many-1", "many-2"} {
+		c, err := dvd.NewCopy(title.ID, barcode, dvd.Good)
+		assert.NoError(t, err)
+		copies = append(copies, c)
+	}
+
+	assert.NoError(t, repo.StoreMany(copies))
+	taken, err := repo.TakenBarcodes([]string{"many-1", "many-2", "many-3"})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"many-1", "many-2"}, taken)
+
+	stored, err := repo.Get(copies[1].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Store Many", stored.Title.Name)
+}
+
+func TestConcurrentRent(t *testing.T) {
+	cacheCli := cache.NewCacheClient(cacheClient)
+	repo := repository.NewDVDRepository(cacheConfig, db, cacheCli)
+	svc := dvd.NewDVDService(repo, dvd.NewWaitlistPolicy(nil))
+	title, err := dvd.NewTitle("Concurrent", 2003, 105, "PG", nil, 1999)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.StoreTitle(title))
+	c, err := dvd.NewCopy(title.ID, "concurrent-1", dvd.Good)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Store(c))
+
+	const callers = 10
+	errs := make(chan error, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := svc.RentDVD(context.Background(), c.ID, "", fmt.Sprintf("customer-%d", i))
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	won := 0
+	for err := range errs {
+		if err == nil {
+			won++
+			continue
+		}
+		assert.Equal(t, apperr.Conflict, apperr.CodeOf(err))
+	}
+	assert.Equal(t, 1, won)
+}
+
+func TestStaleUpdate(t *testing.T) {
+	cacheCli := cache.NewCacheClient(cacheClient)
+	repo := repository.NewDVDRepository(cacheConfig, db, cacheCli)
+	title, err := dvd.NewTitle("Stale", 2004, 110, "PG", nil, 1999)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.StoreTitle(title))
+	c, err := dvd.NewCopy(title.ID, "stale-1", dvd.Good)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Store(c))
+
+	first, second := new(dvd.Copy), new(dvd.Copy)
+	assert.NoError(t, db.Model(first).Where("id = ?", c.ID).Select())
+	assert.NoError(t, db.Model(second).Where("id = ?", c.ID).Select())
+
+	first.Condition = dvd.Fair
+	assert.NoError(t, model.Update(db, first))
+	assert.Equal(t, 2, first.Version)
+
+	second.Condition = dvd.Poor
+	assert.Equal(t, model.ErrStale, model.Update(db, second))
+	assert.Equal(t, 1, second.Version)
+}
+
+func TestUpdateTitle(t *testing.T) {
+	cacheCli := cache.NewCacheClient(cacheClient)
+	repo := repository.NewDVDRepository(cacheConfig, db, cacheCli)
+	title, err := dvd.NewTitle("Update", 2001, 95, "PG", nil, 1999)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.StoreTitle(title))
+
+	title.ReplacementCost = 2499
+	assert.NoError(t, repo.UpdateTitle(title))
+	got, err := repo.GetTitle(title.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2499), got.ReplacementCost)
+	assert.Equal(t, 2, got.Version)
+}
+
+func TestOutboxRelay(t *testing.T) {
+	cacheCli := cache.NewCacheClient(cacheClient)
+	repo := repository.NewDVDRepository(cacheConfig, db, cacheCli)
+	//* Drain what earlier tests left in the outbox
+	discard := outbox.NewInProcessPublisher()
+	for _, eventType := range []string{outbox.DVDCreated, outbox.DVDRented} {
+		discard.Subscribe(eventType, func(context.Context, *outbox.Event) error { return nil })
+	}
+	relay := outbox.NewRelay(db, discard, nil, kitlog.NewNopLogger())
+	for n := 1; n > 0; {
+		var err error
+		n, err = relay.Drain(context.Background())
+		assert.NoError(t, err)
+	}
+
+	publisher := outbox.NewInProcessPublisher()
+	var got []string
+	for _, eventType := range []string{outbox.DVDCreated, outbox.DVDRented} {
+		publisher.Subscribe(eventType, func(_ context.Context, e *outbox.Event) error {
+			got = append(got, e.Type)
+			return nil
+		})
+	}
+	relay = outbox.NewRelay(db, publisher, nil, kitlog.NewNopLogger())
+
+	title, err := dvd.NewTitle("Outbox", 2002, 100, "PG", nil, 1999)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.StoreTitle(title))
+	c, err := dvd.NewCopy(title.ID, "outbox-1", dvd.New)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Store(c))
+	_, err = repo.Rent(c.ID, "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7")
+	assert.NoError(t, err)
+
+	n, err := relay.Drain(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []string{outbox.DVDCreated, outbox.DVDRented}, got)
+
+	n, err = relay.Drain(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n, "published events are not relayed again")
+}
+
+func TestWaitlist(t *testing.T) {
+	cacheCli := cache.NewCacheClient(cacheClient)
+	repo := repository.NewDVDRepository(cacheConfig, db, cacheCli)
+	title, err := dvd.NewTitle("Waitlist", 2001, 100, "PG", nil, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.StoreTitle(title))
+	c, err := dvd.NewCopy(title.ID, "waitlist-1", dvd.Good)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Store(c))
+	const first, second = "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7", "66d112da-07e3-41de-bce3-86fe2bd52b24"
+
+	_, err = repo.Rent(c.ID, first)
+	assert.NoError(t, err)
+	for _, customerID := range []string{first, second} {
+		r, err := dvd.NewReservation(title.ID, customerID)
+		assert.NoError(t, err)
+		assert.NoError(t, repo.Reserve(r))
+	}
+	dup, err := dvd.NewReservation(title.ID, second)
+	assert.NoError(t, err)
+	assert.Equal(t, apperr.Conflict, apperr.CodeOf(repo.Reserve(dup)), "a customer waits once for a title")
+
+	_, err = repo.Return(c.ID, second, time.Hour)
+	assert.Error(t, err, "a copy is only returned by the customer renting it")
+	returned, err := repo.Return(c.ID, first, time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, dvd.Status(dvd.Reserved), returned.Status)
+	assert.Equal(t, first, returned.ReservedFor)
+
+	waiting, err := repo.ListReservations(title.ID)
+	assert.NoError(t, err)
+	assert.Len(t, waiting, 1)
+	assert.Equal(t, second, waiting[0].CustomerID)
+
+	_, err = repo.Rent(c.ID, second)
+	assert.Error(t, err, "a held copy is only rented to its holder")
+	_, err = repo.RentAnyCopy(title.ID, second)
+	assert.Error(t, err)
+	_, err = repo.RentAnyCopy(title.ID, first)
+	assert.NoError(t, err)
+
+	//* A hold already past its time is released by the next sweep
+	returned, err = repo.Return(c.ID, first, -time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, second, returned.ReservedFor)
+	n, err := repo.ExpireHolds(time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	_, err = repo.Rent(c.ID, first)
+	assert.NoError(t, err)
+	assert.Error(t, repo.CancelReservation(title.ID, second, time.Hour))
+}
+
+func TestSearch(t *testing.T) {
+	cacheCli := cache.NewCacheClient(cacheClient)
+	repo := repository.NewDVDRepository(cacheConfig, db, cacheCli)
+	for i, name := range []string{"Search 1", "Search 2", "Search 3", "Other 100%"} {
+		title, err := dvd.NewTitle(name, 0, 0, "", nil, 0)
+		assert.NoError(t, err)
+		assert.NoError(t, repo.StoreTitle(title))
+		c, err := dvd.NewCopy(title.ID, fmt.Sprintf("search-%d", i), dvd.New)
+		assert.NoError(t, err)
+		assert.NoError(t, repo.Store(c))
+	}
+
+	first, err := repo.Search(dvd.Filter{Name: "search"}, nil, 2)
+	assert.NoError(t, err)
+	assert.Len(t, first, 2)
+
+	last := first[len(first)-1]
+	rest, err := repo.Search(dvd.Filter{Name: "search"}, &dvd.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}, 2)
+	assert.NoError(t, err)
+	assert.Len(t, rest, 1)
+	assert.Equal(t, "Search 3", rest[0].Title.Name)
+
+	percent, err := repo.Search(dvd.Filter{Name: "0%", Status: dvd.Available}, nil, 10)
+	assert.NoError(t, err)
+	assert.Len(t, percent, 1)
+}
+
+func TestReserveConcurrently(t *testing.T) {
+	repo := repository.NewDVDRepository(cacheConfig, db, cache.NewCacheClient(cacheClient))
+	title, err := dvd.NewTitle("Concurrent Waitlist", 2002, 100, "PG", nil, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.StoreTitle(title))
+	const customerID = "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7"
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r, err := dvd.NewReservation(title.ID, customerID)
+			assert.NoError(t, err)
+			errs <- repo.Reserve(r)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	joined := 0
+	for err := range errs {
+		if err == nil {
+			joined++
+			continue
+		}
+		assert.Equal(t, apperr.Conflict, apperr.CodeOf(err))
+	}
+	assert.Equal(t, 1, joined)
+
+	waiting, err := repo.ListReservations(title.ID)
+	assert.NoError(t, err)
+	assert.Len(t, waiting, 1)
+}
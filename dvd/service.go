@@ -2,62 +2,378 @@ package dvd
 
 import (
 	"context"
-	"errors"
 
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/metrics"
 	"github.com/google/uuid"
+	"github.com/ngray1747/dvd-rental/internal/apperr"
 )
 
 var (
-	errInvalidDVDName = errors.New("invalid dvd name")
-	errInvalidDVDID   = errors.New("invalid DVD id")
+	errInvalidDVDName   = apperr.New(apperr.InvalidArgument, "invalid dvd name")
+	errInvalidDVDID     = apperr.New(apperr.InvalidArgument, "invalid DVD id")
+	errInvalidTitleID   = apperr.New(apperr.InvalidArgument, "invalid title id")
+	errInvalidBarcode   = apperr.New(apperr.InvalidArgument, "invalid barcode")
+	errInvalidCondition = apperr.New(apperr.InvalidArgument, "invalid condition")
+	errInvalidRentQuery = apperr.New(apperr.InvalidArgument, "either a dvd id or a title id is required")
+	errInvalidCost      = apperr.New(apperr.InvalidArgument, "invalid replacement cost")
+	errInvalidCustomer  = apperr.New(apperr.InvalidArgument, "invalid customer id")
+	errTitleNotFound    = apperr.New(apperr.NotFound, "title not found")
 )
 
 type Service interface {
-	CreateDVD(ctx context.Context, name string) error
-	RentDVD(ctx context.Context, id string) error
+	CreateTitle(ctx context.Context, name string, year, runtime int, rating string, genres []string, replacementCost int64) (*Title, error)
+	// CreateDVD adds a physical copy of a title
+	CreateDVD(ctx context.Context, titleID, barcode string, condition Condition) (*Copy, error)
+	// GetDVD returns the copy id along with its title
+	GetDVD(ctx context.Context, id string) (*Copy, error)
+	// RentDVD rents the copy id, or any available copy of the title titleID, to a customer.
+	// Copies held for the customer are rented first, copies held for others are never rented.
+	RentDVD(ctx context.Context, id, titleID, customerID string) (*Copy, error)
+	// ReturnDVD puts the copy id rented by customerID back on the shelf, or on hold for the title's waitlist,
+	// and returns it along with its title. A copy rented by another customer is left alone.
+	ReturnDVD(ctx context.Context, id, customerID string) (*Copy, error)
+	// ReleaseDVD compensates a rent of the copy id by customerID that could not be completed.
+	// Releasing a copy the customer does not rent is not an error, so it is safe to retry.
+	ReleaseDVD(ctx context.Context, id, customerID string) (*Copy, error)
+	// SellDVD marks the available copy id as sold and returns it along with its title, see Copy.Price
+	SellDVD(ctx context.Context, id string) (*Copy, error)
+	// JoinWaitlist puts a customer in line for the next returned copy of a title
+	JoinWaitlist(ctx context.Context, titleID, customerID string) (*Reservation, error)
+	LeaveWaitlist(ctx context.Context, titleID, customerID string) error
+	ListWaitlist(ctx context.Context, titleID string) ([]*Reservation, error)
+	// ExpireHolds passes the copies held for too long on to the next in line
+	ExpireHolds(ctx context.Context) (int, error)
+	ListDVDs(ctx context.Context, cursor string, limit int) (*Page, error)
+	SearchDVDs(ctx context.Context, f Filter, cursor string, limit int) (*Page, error)
+	// ImportDVDs checks each row the way CreateDVD does and adds the valid ones in a single batch,
+	// the result reports the rows which were not imported
+	ImportDVDs(ctx context.Context, rows []ImportRow) (*ImportResult, error)
+	// ExportDVDs calls each with every copy matching the filter along with its title, oldest first
+	ExportDVDs(ctx context.Context, f Filter, each func(*Copy) error) error
 }
 
 type dvdService struct {
-	repo Repository
+	repo     Repository
+	waitlist WaitlistPolicy
 }
 
-func NewDVDService(dvdRepo Repository) Service {
-	return &dvdService{repo: dvdRepo}
+func NewDVDService(dvdRepo Repository, waitlist WaitlistPolicy) Service {
+	return &dvdService{repo: dvdRepo, waitlist: waitlist}
 }
 
-func NewService(dvdRepo Repository, logger log.Logger, counter metrics.Counter, histogram metrics.Histogram) Service {
+func NewService(dvdRepo Repository, waitlist WaitlistPolicy, logger log.Logger, counter metrics.Counter, histogram metrics.Histogram) Service {
 	var dvdService Service
 	{
-		dvdService = NewDVDService(dvdRepo)
+		dvdService = NewDVDService(dvdRepo, waitlist)
 		dvdService = NewLoggerMiddleware(logger)(dvdService)
 		dvdService = NewMetrictMiddleware(counter, histogram)(dvdService)
 	}
 	return dvdService
 }
 
-func (d *dvdService) CreateDVD(ctx context.Context, name string) error {
+func (d *dvdService) CreateTitle(ctx context.Context, name string, year, runtime int, rating string, genres []string, replacementCost int64) (*Title, error) {
 	if name == "" {
-		return errInvalidDVDName
+		return nil, errInvalidDVDName
+	}
+	if replacementCost < 0 {
+		return nil, errInvalidCost
+	}
+
+	title, err := NewTitle(name, year, runtime, rating, genres, replacementCost)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.repo.StoreTitle(title); err != nil {
+		return nil, err
+	}
+	return title, nil
+}
+
+func (d *dvdService) CreateDVD(ctx context.Context, titleID, barcode string, condition Condition) (*Copy, error) {
+	if _, err := uuid.Parse(titleID); err != nil {
+		return nil, errInvalidTitleID
+	}
+	if barcode == "" {
+		return nil, errInvalidBarcode
+	}
+	if !condition.IsValid() {
+		return nil, errInvalidCondition
+	}
+	if _, err := d.repo.GetTitle(titleID); err != nil {
+		return nil, err
 	}
 
-	dvd, err := NewDVD(name)
+	c, err := NewCopy(titleID, barcode, condition)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return d.repo.Store(dvd)
+	if err := d.repo.Store(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (d *dvdService) GetDVD(ctx context.Context, id string) (*Copy, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, errInvalidDVDID
+	}
+	return d.repo.Get(id)
+}
+
+func (d *dvdService) RentDVD(ctx context.Context, id, titleID, customerID string) (*Copy, error) {
+	switch {
+	case id != "" && titleID == "":
+		if _, err := uuid.Parse(id); err != nil {
+			return nil, errInvalidDVDID
+		}
+		return d.repo.Rent(id, customerID)
+	case id == "" && titleID != "":
+		if _, err := uuid.Parse(titleID); err != nil {
+			return nil, errInvalidTitleID
+		}
+		return d.repo.RentAnyCopy(titleID, customerID)
+	default:
+		return nil, errInvalidRentQuery
+	}
 }
 
-func (d *dvdService) RentDVD(ctx context.Context, id string) error {
+func (d *dvdService) ReturnDVD(ctx context.Context, id, customerID string) (*Copy, error) {
 	if id == "" {
-		return errInvalidDVDID
+		return nil, errInvalidDVDID
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, errInvalidDVDID
+	}
+	if customerID == "" {
+		return nil, errInvalidCustomer
+	}
+
+	title, err := d.copyTitle(id)
+	if err != nil {
+		return nil, err
 	}
-	_, err := uuid.Parse(id)
+	c, err := d.repo.Return(id, customerID, d.waitlist.Hold)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	c.Title = title
+	return c, nil
+}
+
+func (d *dvdService) ReleaseDVD(ctx context.Context, id, customerID string) (*Copy, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, errInvalidDVDID
+	}
+	if customerID == "" {
+		return nil, errInvalidCustomer
+	}
+	return d.repo.Release(id, customerID, d.waitlist.Hold)
+}
+
+func (d *dvdService) SellDVD(ctx context.Context, id string) (*Copy, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, errInvalidDVDID
+	}
+
+	title, err := d.copyTitle(id)
+	if err != nil {
+		return nil, err
+	}
+	c, err := d.repo.Update(id, Sold)
+	if err != nil {
+		return nil, err
+	}
+	c.Title = title
+	return c, nil
+}
+
+// copyTitle loads the title of copy id before its status changes, so no error is reported for a change already made
+func (d *dvdService) copyTitle(id string) (*Title, error) {
+	c, err := d.repo.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	return c.Title, nil
+}
+
+func (d *dvdService) JoinWaitlist(ctx context.Context, titleID, customerID string) (*Reservation, error) {
+	if _, err := uuid.Parse(titleID); err != nil {
+		return nil, errInvalidTitleID
+	}
+	if customerID == "" {
+		return nil, errInvalidCustomer
+	}
+	if _, err := d.repo.GetTitle(titleID); err != nil {
+		return nil, err
+	}
+
+	r, err := NewReservation(titleID, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.repo.Reserve(r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (d *dvdService) LeaveWaitlist(ctx context.Context, titleID, customerID string) error {
+	if _, err := uuid.Parse(titleID); err != nil {
+		return errInvalidTitleID
+	}
+	if customerID == "" {
+		return errInvalidCustomer
+	}
+	return d.repo.CancelReservation(titleID, customerID, d.waitlist.Hold)
+}
+
+func (d *dvdService) ListWaitlist(ctx context.Context, titleID string) ([]*Reservation, error) {
+	if _, err := uuid.Parse(titleID); err != nil {
+		return nil, errInvalidTitleID
+	}
+	return d.repo.ListReservations(titleID)
+}
+
+func (d *dvdService) ExpireHolds(ctx context.Context) (int, error) {
+	return d.repo.ExpireHolds(d.waitlist.Hold)
+}
+
+func (d *dvdService) ListDVDs(ctx context.Context, cursor string, limit int) (*Page, error) {
+	after, err := DecodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	size := pageSize(limit)
+	copies, err := d.repo.List(after, size+1)
+	if err != nil {
+		return nil, err
+	}
+	return newPage(copies, size), nil
+}
+
+func (d *dvdService) SearchDVDs(ctx context.Context, f Filter, cursor string, limit int) (*Page, error) {
+	after, err := DecodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	size := pageSize(limit)
+	copies, err := d.repo.Search(f, after, size+1)
+	if err != nil {
+		return nil, err
+	}
+	return newPage(copies, size), nil
+}
+
+func (d *dvdService) ImportDVDs(ctx context.Context, rows []ImportRow) (*ImportResult, error) {
+	result := new(ImportResult)
+	titles := make(map[string]error)
+	seen := make(map[string]bool, len(rows))
+	var valid []ImportRow
+	for _, row := range rows {
+		err := d.checkImportRow(row, titles)
+		if err != nil && !apperr.IsDomain(err) {
+			return nil, err
+		}
+		if err == nil && seen[row.Barcode] {
+			err = errDuplicateBarcode
+		}
+		if err != nil {
+			result.fail(row, err)
+			continue
+		}
+		seen[row.Barcode] = true
+		valid = append(valid, row)
+	}
+
+	barcodes := make([]string, len(valid))
+	for i, row := range valid {
+		barcodes[i] = row.Barcode
+	}
+	taken, err := d.repo.TakenBarcodes(barcodes)
+	if err != nil {
+		return nil, err
+	}
+	isTaken := make(map[string]bool, len(taken))
+	for _, barcode := range taken {
+		isTaken[barcode] = true
+	}
+
+	var imported []ImportRow
+	var copies []*Copy
+	for _, row := range valid {
+		if isTaken[row.Barcode] {
+			result.fail(row, errDuplicateBarcode)
+			continue
+		}
+		c, err := NewCopy(row.TitleID, row.Barcode, row.Condition)
+		if err != nil {
+			return nil, err
+		}
+		imported = append(imported, row)
+		copies = append(copies, c)
+	}
+	if len(copies) == 0 {
+		return result, nil
+	}
+	if err := d.repo.StoreMany(copies); err != nil {
+		if !apperr.IsDomain(err) {
+			return nil, err
+		}
+		for _, row := range imported {
+			result.fail(row, err)
+		}
+		return result, nil
+	}
+	result.Imported = len(copies)
+	return result, nil
+}
+
+// checkImportRow validates a row like CreateDVD does, titles remembers the outcome of the title lookups
+func (d *dvdService) checkImportRow(row ImportRow, titles map[string]error) error {
+	if _, err := uuid.Parse(row.TitleID); err != nil {
+		return errInvalidTitleID
+	}
+	if row.Barcode == "" {
+		return errInvalidBarcode
+	}
+	if !row.Condition.IsValid() {
+		return errInvalidCondition
+	}
+	err, ok := titles[row.TitleID]
+	if !ok {
+		_, err = d.repo.GetTitle(row.TitleID)
+		if apperr.CodeOf(err) == apperr.NotFound {
+			err = errTitleNotFound
+		}
+		titles[row.TitleID] = err
+	}
+	return err
+}
+
+func (d *dvdService) ExportDVDs(ctx context.Context, f Filter, each func(*Copy) error) error {
+	var after *Cursor
+	for {
+		copies, err := d.repo.Search(f, after, maxPageSize)
+		if err != nil {
+			return err
+		}
+		for _, c := range copies {
+			if err := each(c); err != nil {
+				return err
+			}
+		}
+		if len(copies) < maxPageSize {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		last := copies[len(copies)-1]
+		after = &Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
 	}
-	
-	return d.repo.Update(id, NotAvailable)
 }
@@ -1,25 +1,37 @@
 package dvd_test
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/metrics/discard"
+	"github.com/go-pg/pg/v9"
 	"github.com/ngray1747/dvd-rental/dvd"
 	"github.com/ngray1747/dvd-rental/dvd/mocks"
+	"github.com/ngray1747/dvd-rental/internal/config"
+	"github.com/ngray1747/dvd-rental/internal/idempotency"
+	"github.com/ngray1747/dvd-rental/internal/limits"
+	"github.com/ngray1747/dvd-rental/internal/model"
+	stdopentracing "github.com/opentracing/opentracing-go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
-func TestRegister(t *testing.T) {
+func TestCreateTitle(t *testing.T) {
 	assert := assert.New(t)
 	ctx := context.Background()
 	repo := new(mocks.Repository)
-	svc := dvd.NewService(repo, log.NewNopLogger(), discard.NewCounter(), discard.NewHistogram())
+	svc := dvd.NewService(repo, dvd.NewWaitlistPolicy(nil), log.NewNopLogger(), discard.NewCounter(), discard.NewHistogram())
 	type args struct {
-		name string
+		name            string
+		replacementCost int64
 	}
 	cases := []struct {
 		name    string
@@ -30,11 +42,12 @@ func TestRegister(t *testing.T) {
 		{
 			name: "OK",
 			args: args{
-				name: "Title 1",
+				name:            "Title 1",
+				replacementCost: 1999,
 			},
 			wantErr: false,
 			mock: func() {
-				repo.On("Store", mock.Anything).Return(nil).Once()
+				repo.On("StoreTitle", mock.Anything).Return(nil).Once()
 			},
 		},
 		{
@@ -45,6 +58,15 @@ func TestRegister(t *testing.T) {
 			wantErr: true,
 			mock:    func() {},
 		},
+		{
+			name: "negative replacement cost",
+			args: args{
+				name:            "Title 2",
+				replacementCost: -1,
+			},
+			wantErr: true,
+			mock:    func() {},
+		},
 		{
 			name: "store failed",
 			args: args{
@@ -52,6 +74,104 @@ func TestRegister(t *testing.T) {
 			},
 			wantErr: true,
 			mock: func() {
+				repo.On("StoreTitle", mock.Anything).Return(errors.New("store failed")).Once()
+			},
+		},
+	}
+	for _, v := range cases {
+		t.Run(v.name, func(t *testing.T) {
+			v.mock()
+			title, err := svc.CreateTitle(ctx, v.args.name, 1999, 136, "R", []string{"Action"}, v.args.replacementCost)
+			assert.Equalf(v.wantErr, err != nil, "name: %v , wantErr %v, got %v , err ", v.name, v.wantErr, err != nil, err)
+			if !v.wantErr {
+				assert.NotEmpty(title.ID)
+				assert.Equal(v.args.name, title.Name)
+				assert.Equal(v.args.replacementCost, title.ReplacementCost)
+			}
+		})
+	}
+}
+
+func TestCreateDVD(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	repo := new(mocks.Repository)
+	svc := dvd.NewService(repo, dvd.NewWaitlistPolicy(nil), log.NewNopLogger(), discard.NewCounter(), discard.NewHistogram())
+	type args struct {
+		titleID   string
+		barcode   string
+		condition dvd.Condition
+	}
+	cases := []struct {
+		name    string
+		args    args
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name: "OK",
+			args: args{
+				titleID:   "66d112da-07e3-41de-bce3-86fe2bd52b24",
+				barcode:   "0001",
+				condition: dvd.New,
+			},
+			wantErr: false,
+			mock: func() {
+				repo.On("GetTitle", "66d112da-07e3-41de-bce3-86fe2bd52b24").Return(&dvd.Title{}, nil).Once()
+				repo.On("Store", mock.Anything).Return(nil).Once()
+			},
+		},
+		{
+			name: "invalid title id",
+			args: args{
+				titleID:   "some-id",
+				barcode:   "0001",
+				condition: dvd.New,
+			},
+			wantErr: true,
+			mock:    func() {},
+		},
+		{
+			name: "missing barcode",
+			args: args{
+				titleID:   "66d112da-07e3-41de-bce3-86fe2bd52b24",
+				condition: dvd.New,
+			},
+			wantErr: true,
+			mock:    func() {},
+		},
+		{
+			name: "invalid condition",
+			args: args{
+				titleID:   "66d112da-07e3-41de-bce3-86fe2bd52b24",
+				barcode:   "0001",
+				condition: "scratched",
+			},
+			wantErr: true,
+			mock:    func() {},
+		},
+		{
+			name: "title not found",
+			args: args{
+				titleID:   "66d112da-07e3-41de-bce3-86fe2bd52b24",
+				barcode:   "0001",
+				condition: dvd.Good,
+			},
+			wantErr: true,
+			mock: func() {
+				repo.On("GetTitle", mock.Anything).Return(nil, errors.New("pg: no rows in result set")).Once()
+			},
+		},
+		{
+			name: "store failed",
+			args: args{
+				titleID:   "66d112da-07e3-41de-bce3-86fe2bd52b24",
+				barcode:   "0001",
+				condition: dvd.Good,
+			},
+			wantErr: true,
+			mock: func() {
+				repo.On("GetTitle", mock.Anything).Return(&dvd.Title{}, nil).Once()
 				repo.On("Store", mock.Anything).Return(errors.New("store failed")).Once()
 			},
 		},
@@ -59,19 +179,74 @@ func TestRegister(t *testing.T) {
 	for _, v := range cases {
 		t.Run(v.name, func(t *testing.T) {
 			v.mock()
-			err := svc.CreateDVD(ctx, v.args.name)
+			c, err := svc.CreateDVD(ctx, v.args.titleID, v.args.barcode, v.args.condition)
 			assert.Equalf(v.wantErr, err != nil, "name: %v , wantErr %v, got %v , err ", v.name, v.wantErr, err != nil, err)
+			if !v.wantErr {
+				assert.NotEmpty(c.ID)
+				assert.Equal(v.args.titleID, c.TitleID)
+				assert.Equal(dvd.Status(dvd.Available), c.Status)
+			}
 		})
 	}
 }
 
+func TestGetDVD(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	repo := new(mocks.Repository)
+	svc := dvd.NewService(repo, dvd.NewWaitlistPolicy(nil), log.NewNopLogger(), discard.NewCounter(), discard.NewHistogram())
+	cases := []struct {
+		name    string
+		id      string
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name: "OK",
+			id:   "5e8b83c9-36f3-4084-94b5-33153246d534",
+			mock: func() {
+				repo.On("Get", "5e8b83c9-36f3-4084-94b5-33153246d534").Return(&dvd.Copy{
+					Title:  &dvd.Title{Name: "Metropolis"},
+					Status: dvd.Available,
+				}, nil).Once()
+			},
+		},
+		{
+			name:    "invalid id",
+			id:      "some-id",
+			wantErr: true,
+			mock:    func() {},
+		},
+		{
+			name:    "not found",
+			id:      "5e8b83c9-36f3-4084-94b5-33153246d534",
+			wantErr: true,
+			mock: func() {
+				repo.On("Get", "5e8b83c9-36f3-4084-94b5-33153246d534").Return(nil, pg.ErrNoRows).Once()
+			},
+		},
+	}
+	for _, v := range cases {
+		t.Run(v.name, func(t *testing.T) {
+			v.mock()
+			c, err := svc.GetDVD(ctx, v.id)
+			assert.Equalf(v.wantErr, err != nil, "name: %v , wantErr %v, got %v , err ", v.name, v.wantErr, err != nil, err)
+			if !v.wantErr {
+				assert.Equal("Metropolis", c.Title.Name)
+			}
+		})
+	}
+	repo.AssertExpectations(t)
+}
+
 func TestRentDVD(t *testing.T) {
 	assert := assert.New(t)
 	ctx := context.Background()
 	repo := new(mocks.Repository)
-	svc := dvd.NewService(repo, log.NewNopLogger(), discard.NewCounter(), discard.NewHistogram())
+	svc := dvd.NewService(repo, dvd.NewWaitlistPolicy(nil), log.NewNopLogger(), discard.NewCounter(), discard.NewHistogram())
 	type args struct {
-		id string
+		id      string
+		titleID string
 	}
 	cases := []struct {
 		name    string
@@ -86,13 +261,30 @@ func TestRentDVD(t *testing.T) {
 			},
 			wantErr: false,
 			mock: func() {
-				repo.On("Update", mock.Anything).Return(nil).Once()
+				repo.On("Rent", "5e8b83c9-36f3-4084-94b5-33153246d534", "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7").Return(&dvd.Copy{}, nil).Once()
 			},
 		},
 		{
-			name: "missing id",
+			name: "any copy of title",
 			args: args{
-				id: "",
+				titleID: "66d112da-07e3-41de-bce3-86fe2bd52b24",
+			},
+			wantErr: false,
+			mock: func() {
+				repo.On("RentAnyCopy", "66d112da-07e3-41de-bce3-86fe2bd52b24", "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7").Return(&dvd.Copy{}, nil).Once()
+			},
+		},
+		{
+			name:    "missing id",
+			args:    args{},
+			wantErr: true,
+			mock:    func() {},
+		},
+		{
+			name: "both id and title",
+			args: args{
+				id:      "5e8b83c9-36f3-4084-94b5-33153246d534",
+				titleID: "66d112da-07e3-41de-bce3-86fe2bd52b24",
 			},
 			wantErr: true,
 			mock:    func() {},
@@ -100,27 +292,608 @@ func TestRentDVD(t *testing.T) {
 		{
 			name: "Update failed",
 			args: args{
-				id: "Title 2",
+				id: "5e8b83c9-36f3-4084-94b5-33153246d534",
 			},
 			wantErr: true,
 			mock: func() {
-				repo.On("Update", mock.Anything).Return(errors.New("Update failed")).Once()
+				repo.On("Rent", mock.Anything, mock.Anything).Return(nil, errors.New("dvd is reserved for another customer")).Once()
+			},
+		},
+		{
+			name: "no copy available",
+			args: args{
+				titleID: "66d112da-07e3-41de-bce3-86fe2bd52b24",
+			},
+			wantErr: true,
+			mock: func() {
+				repo.On("RentAnyCopy", mock.Anything, mock.Anything).Return(nil, errors.New("dvd not available")).Once()
+			},
+		},
+		{
+			name: "id failed",
+			args: args{
+				id: "some-id",
+			},
+			wantErr: true,
+			mock:    func() {},
+		},
+	}
+	for _, v := range cases {
+		t.Run(v.name, func(t *testing.T) {
+			v.mock()
+			_, err := svc.RentDVD(ctx, v.args.id, v.args.titleID, "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7")
+			assert.Equalf(v.wantErr, err != nil, "name: %v , wantErr %v, got %v , err ", v.name, v.wantErr, err != nil, err)
+		})
+	}
+}
+
+func TestReturnDVD(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	repo := new(mocks.Repository)
+	svc := dvd.NewService(repo, dvd.NewWaitlistPolicy(nil), log.NewNopLogger(), discard.NewCounter(), discard.NewHistogram())
+	type args struct {
+		id         string
+		customerID string
+	}
+	cases := []struct {
+		name    string
+		args    args
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name: "OK",
+			args: args{
+				id:         "5e8b83c9-36f3-4084-94b5-33153246d534",
+				customerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+			},
+			wantErr: false,
+			mock: func() {
+				repo.On("Get", "5e8b83c9-36f3-4084-94b5-33153246d534").Return(&dvd.Copy{Title: &dvd.Title{ReplacementCost: 1999}}, nil).Once()
+				repo.On("Return", "5e8b83c9-36f3-4084-94b5-33153246d534", "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7", 48*time.Hour).Return(&dvd.Copy{TitleID: "0b7a1c3e-52a4-4c1b-8d7e-3f6b2f0d9a01"}, nil).Once()
+			},
+		},
+		{
+			name: "copy not found",
+			args: args{
+				id:         "5e8b83c9-36f3-4084-94b5-33153246d534",
+				customerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+			},
+			wantErr: true,
+			mock: func() {
+				repo.On("Get", mock.Anything).Return(nil, pg.ErrNoRows).Once()
+			},
+		},
+		{
+			name: "missing id",
+			args: args{
+				id:         "",
+				customerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+			},
+			wantErr: true,
+			mock:    func() {},
+		},
+		{
+			name: "not rented",
+			args: args{
+				id:         "5e8b83c9-36f3-4084-94b5-33153246d534",
+				customerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+			},
+			wantErr: true,
+			mock: func() {
+				repo.On("Get", mock.Anything).Return(&dvd.Copy{Title: &dvd.Title{}}, nil).Once()
+				repo.On("Return", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("dvd is not rented")).Once()
+			},
+		},
+		{
+			name: "id failed",
+			args: args{
+				id:         "some-id",
+				customerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+			},
+			wantErr: true,
+			mock:    func() {},
+		},
+		{
+			name: "missing customer",
+			args: args{
+				id: "5e8b83c9-36f3-4084-94b5-33153246d534",
+			},
+			wantErr: true,
+			mock:    func() {},
+		},
+	}
+	for _, v := range cases {
+		t.Run(v.name, func(t *testing.T) {
+			v.mock()
+			c, err := svc.ReturnDVD(ctx, v.args.id, v.args.customerID)
+			assert.Equalf(v.wantErr, err != nil, "name: %v , wantErr %v, got %v , err ", v.name, v.wantErr, err != nil, err)
+			if !v.wantErr {
+				assert.Equal(int64(1999), c.Title.ReplacementCost)
+			}
+		})
+	}
+}
+
+func TestReleaseDVD(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	repo := new(mocks.Repository)
+	svc := dvd.NewService(repo, dvd.NewWaitlistPolicy(nil), log.NewNopLogger(), discard.NewCounter(), discard.NewHistogram())
+	type args struct {
+		id         string
+		customerID string
+	}
+	cases := []struct {
+		name    string
+		args    args
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name: "OK",
+			args: args{
+				id:         "5e8b83c9-36f3-4084-94b5-33153246d534",
+				customerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+			},
+			wantErr: false,
+			mock: func() {
+				repo.On("Release", "5e8b83c9-36f3-4084-94b5-33153246d534", "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7", 48*time.Hour).Return(&dvd.Copy{Status: dvd.Available}, nil).Once()
 			},
 		},
+		{
+			name: "missing customer",
+			args: args{
+				id: "5e8b83c9-36f3-4084-94b5-33153246d534",
+			},
+			wantErr: true,
+			mock:    func() {},
+		},
 		{
 			name: "id failed",
+			args: args{
+				id:         "some-id",
+				customerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+			},
+			wantErr: true,
+			mock:    func() {},
+		},
+		{
+			name: "release failed",
+			args: args{
+				id:         "5e8b83c9-36f3-4084-94b5-33153246d534",
+				customerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+			},
+			wantErr: true,
+			mock: func() {
+				repo.On("Release", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("connection refused")).Once()
+			},
+		},
+	}
+	for _, v := range cases {
+		t.Run(v.name, func(t *testing.T) {
+			v.mock()
+			_, err := svc.ReleaseDVD(ctx, v.args.id, v.args.customerID)
+			assert.Equalf(v.wantErr, err != nil, "name: %v , wantErr %v, got %v , err ", v.name, v.wantErr, err != nil, err)
+		})
+	}
+}
+
+func TestSellDVD(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	repo := new(mocks.Repository)
+	svc := dvd.NewService(repo, dvd.NewWaitlistPolicy(nil), log.NewNopLogger(), discard.NewCounter(), discard.NewHistogram())
+	type args struct {
+		id string
+	}
+	cases := []struct {
+		name      string
+		args      args
+		wantErr   bool
+		wantPrice int64
+		mock      func()
+	}{
+		{
+			name: "OK",
+			args: args{
+				id: "5e8b83c9-36f3-4084-94b5-33153246d534",
+			},
+			wantErr:   false,
+			wantPrice: 1500,
+			mock: func() {
+				repo.On("Get", "5e8b83c9-36f3-4084-94b5-33153246d534").Return(&dvd.Copy{Title: &dvd.Title{ReplacementCost: 2000}}, nil).Once()
+				repo.On("Update", "5e8b83c9-36f3-4084-94b5-33153246d534", dvd.Status(dvd.Sold)).Return(&dvd.Copy{
+					TitleID:   "0b7a1c3e-52a4-4c1b-8d7e-3f6b2f0d9a01",
+					Condition: dvd.Good,
+					Status:    dvd.Sold,
+				}, nil).Once()
+			},
+		},
+		{
+			name: "invalid id",
 			args: args{
 				id: "some-id",
 			},
 			wantErr: true,
 			mock:    func() {},
 		},
+		{
+			name: "rented",
+			args: args{
+				id: "5e8b83c9-36f3-4084-94b5-33153246d534",
+			},
+			wantErr: true,
+			mock: func() {
+				repo.On("Get", mock.Anything).Return(&dvd.Copy{Title: &dvd.Title{}}, nil).Once()
+				repo.On("Update", mock.Anything, mock.Anything).Return(nil, errors.New("dvd not available")).Once()
+			},
+		},
+		{
+			name: "copy not found",
+			args: args{
+				id: "5e8b83c9-36f3-4084-94b5-33153246d534",
+			},
+			wantErr: true,
+			mock: func() {
+				repo.On("Get", mock.Anything).Return(nil, pg.ErrNoRows).Once()
+			},
+		},
 	}
 	for _, v := range cases {
 		t.Run(v.name, func(t *testing.T) {
 			v.mock()
-			err := svc.RentDVD(ctx, v.args.id)
+			c, err := svc.SellDVD(ctx, v.args.id)
 			assert.Equalf(v.wantErr, err != nil, "name: %v , wantErr %v, got %v , err ", v.name, v.wantErr, err != nil, err)
+			if !v.wantErr {
+				assert.Equal(v.wantPrice, c.Price())
+			}
 		})
 	}
 }
+
+func TestStatusCanBecome(t *testing.T) {
+	assert := assert.New(t)
+	assert.True(dvd.Status(dvd.Available).CanBecome(dvd.NotAvailable))
+	assert.True(dvd.Status(dvd.Available).CanBecome(dvd.Sold))
+	assert.True(dvd.Status(dvd.NotAvailable).CanBecome(dvd.Available))
+	assert.False(dvd.Status(dvd.NotAvailable).CanBecome(dvd.Sold))
+	assert.False(dvd.Status(dvd.Available).CanBecome(dvd.Available))
+	for _, s := range dvd.Statuss {
+		assert.False(dvd.Status(dvd.Sold).CanBecome(s))
+	}
+	assert.Equal("Sold", dvd.Status(dvd.Sold).ToString())
+}
+
+func TestJoinWaitlist(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	repo := new(mocks.Repository)
+	svc := dvd.NewService(repo, dvd.NewWaitlistPolicy(nil), log.NewNopLogger(), discard.NewCounter(), discard.NewHistogram())
+	type args struct {
+		titleID    string
+		customerID string
+	}
+	cases := []struct {
+		name    string
+		args    args
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name: "OK",
+			args: args{
+				titleID:    "66d112da-07e3-41de-bce3-86fe2bd52b24",
+				customerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+			},
+			wantErr: false,
+			mock: func() {
+				repo.On("GetTitle", "66d112da-07e3-41de-bce3-86fe2bd52b24").Return(&dvd.Title{}, nil).Once()
+				repo.On("Reserve", mock.MatchedBy(func(r *dvd.Reservation) bool {
+					return r.TitleID == "66d112da-07e3-41de-bce3-86fe2bd52b24" && r.CustomerID == "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7"
+				})).Return(nil).Once()
+			},
+		},
+		{
+			name: "invalid title id",
+			args: args{
+				titleID:    "some-id",
+				customerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+			},
+			wantErr: true,
+			mock:    func() {},
+		},
+		{
+			name: "missing customer id",
+			args: args{
+				titleID: "66d112da-07e3-41de-bce3-86fe2bd52b24",
+			},
+			wantErr: true,
+			mock:    func() {},
+		},
+		{
+			name: "already waiting",
+			args: args{
+				titleID:    "66d112da-07e3-41de-bce3-86fe2bd52b24",
+				customerID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7",
+			},
+			wantErr: true,
+			mock: func() {
+				repo.On("GetTitle", mock.Anything).Return(&dvd.Title{}, nil).Once()
+				repo.On("Reserve", mock.Anything).Return(errors.New("customer is already on the waitlist")).Once()
+			},
+		},
+	}
+	for _, v := range cases {
+		t.Run(v.name, func(t *testing.T) {
+			v.mock()
+			r, err := svc.JoinWaitlist(ctx, v.args.titleID, v.args.customerID)
+			assert.Equalf(v.wantErr, err != nil, "name: %v , wantErr %v, got %v , err ", v.name, v.wantErr, err != nil, err)
+			assert.Equal(v.wantErr, r == nil)
+		})
+	}
+}
+
+func TestLeaveWaitlist(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	repo := new(mocks.Repository)
+	svc := dvd.NewService(repo, dvd.WaitlistPolicy{Hold: time.Hour}, log.NewNopLogger(), discard.NewCounter(), discard.NewHistogram())
+
+	repo.On("CancelReservation", "66d112da-07e3-41de-bce3-86fe2bd52b24", "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7", time.Hour).Return(nil).Once()
+	assert.NoError(svc.LeaveWaitlist(ctx, "66d112da-07e3-41de-bce3-86fe2bd52b24", "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7"))
+	assert.Error(svc.LeaveWaitlist(ctx, "some-id", "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7"))
+	assert.Error(svc.LeaveWaitlist(ctx, "66d112da-07e3-41de-bce3-86fe2bd52b24", ""))
+	repo.AssertExpectations(t)
+}
+
+func TestListDVDs(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	repo := new(mocks.Repository)
+	svc := dvd.NewService(repo, dvd.NewWaitlistPolicy(nil), log.NewNopLogger(), discard.NewCounter(), discard.NewHistogram())
+	now := time.Now()
+	dvds := []*dvd.Copy{
+		{Base: model.Base{ID: "5e8b83c9-36f3-4084-94b5-33153246d534", CreatedAt: now}, Barcode: "0001"},
+		{Base: model.Base{ID: "66d112da-07e3-41de-bce3-86fe2bd52b24", CreatedAt: now.Add(time.Second)}, Barcode: "0002"},
+		{Base: model.Base{ID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7", CreatedAt: now.Add(2 * time.Second)}, Barcode: "0003"},
+	}
+	type args struct {
+		cursor string
+		limit  int
+	}
+	cases := []struct {
+		name       string
+		args       args
+		wantErr    bool
+		wantLen    int
+		wantCursor bool
+		mock       func()
+	}{
+		{
+			name:       "has next page",
+			args:       args{limit: 2},
+			wantLen:    2,
+			wantCursor: true,
+			mock: func() {
+				repo.On("List", (*dvd.Cursor)(nil), 3).Return(dvds, nil).Once()
+			},
+		},
+		{
+			name:    "last page",
+			args:    args{cursor: dvd.EncodeCursor(dvd.Cursor{CreatedAt: now.Add(time.Second), ID: dvds[1].ID}), limit: 2},
+			wantLen: 1,
+			mock: func() {
+				repo.On("List", mock.MatchedBy(func(c *dvd.Cursor) bool {
+					return c != nil && c.ID == dvds[1].ID && c.CreatedAt.Equal(now.Add(time.Second))
+				}), 3).Return(dvds[2:], nil).Once()
+			},
+		},
+		{
+			name:    "default page size",
+			wantLen: 3,
+			mock: func() {
+				repo.On("List", (*dvd.Cursor)(nil), 21).Return(dvds, nil).Once()
+			},
+		},
+		{
+			name:    "invalid cursor",
+			args:    args{cursor: "not-a-cursor"},
+			wantErr: true,
+			mock:    func() {},
+		},
+		{
+			name:    "list failed",
+			wantErr: true,
+			mock: func() {
+				repo.On("List", mock.Anything, mock.Anything).Return(nil, errors.New("list failed")).Once()
+			},
+		},
+	}
+	for _, v := range cases {
+		t.Run(v.name, func(t *testing.T) {
+			v.mock()
+			page, err := svc.ListDVDs(ctx, v.args.cursor, v.args.limit)
+			assert.Equalf(v.wantErr, err != nil, "name: %v , wantErr %v, got %v , err ", v.name, v.wantErr, err != nil, err)
+			if !v.wantErr {
+				assert.Len(page.Copies, v.wantLen)
+				assert.Equal(v.wantCursor, page.NextCursor != "")
+			}
+		})
+	}
+}
+
+func TestSearchDVDs(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	repo := new(mocks.Repository)
+	svc := dvd.NewService(repo, dvd.NewWaitlistPolicy(nil), log.NewNopLogger(), discard.NewCounter(), discard.NewHistogram())
+	f := dvd.Filter{Name: "title", Status: dvd.Available}
+
+	repo.On("Search", f, (*dvd.Cursor)(nil), 11).Return([]*dvd.Copy{{Barcode: "0001"}}, nil).Once()
+	page, err := svc.SearchDVDs(ctx, f, "", 10)
+	assert.NoError(err)
+	assert.Len(page.Copies, 1)
+	assert.Empty(page.NextCursor)
+}
+
+func TestImportCSV(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	repo := new(mocks.Repository)
+	svc := dvd.NewService(repo, dvd.NewWaitlistPolicy(nil), log.NewNopLogger(), discard.NewCounter(), discard.NewHistogram())
+	titleID := "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7"
+	unknownID := "5e8b83c9-36f3-4084-94b5-33153246d534"
+	repo.On("GetTitle", titleID).Return(&dvd.Title{Base: model.Base{ID: titleID}}, nil)
+	repo.On("GetTitle", unknownID).Return(nil, pg.ErrNoRows)
+	repo.On("TakenBarcodes", []string{"B1"}).Return([]string{}, nil).Once()
+	repo.On("TakenBarcodes", []string{"B4"}).Return([]string{"B4"}, nil).Once()
+	repo.On("TakenBarcodes", []string{"B5", "B6"}).Return([]string{}, nil).Once()
+	repo.On("StoreMany", mock.MatchedBy(func(copies []*dvd.Copy) bool { return len(copies) == 1 })).Return(nil).Once()
+	repo.On("StoreMany", mock.MatchedBy(func(copies []*dvd.Copy) bool { return len(copies) == 2 })).Return(nil).Once()
+
+	//* Batches of 2 rows: B1 and B2, B3 and B4, B5 and B6, the short row never reaches a batch
+	file := "Barcode,title_id,condition,notes\n" +
+		"B1," + titleID + ",good,first\n" +
+		"B2," + titleID + ",mint\n" +
+		"B3," + unknownID + ",good\n" +
+		"B4," + titleID + ",NEW\n" +
+		"B7\n" +
+		"B5," + titleID + ",fair\n" +
+		"B6," + titleID + ",poor\n"
+	res, err := dvd.ImportCSV(ctx, svc, strings.NewReader(file), 2)
+	assert.NoError(err)
+	assert.Equal(3, res.Imported)
+	assert.Equal([]dvd.RowError{
+		{Row: 3, Barcode: "B2", Message: "invalid condition"},
+		{Row: 4, Barcode: "B3", Message: "title not found"},
+		{Row: 5, Barcode: "B4", Message: "barcode is already used"},
+		{Row: 6, Barcode: "B7", Message: "missing title_id"},
+	}, res.Errors)
+	repo.AssertExpectations(t)
+
+	_, err = dvd.ImportCSV(ctx, svc, strings.NewReader("barcode,condition\nB1,good\n"), 2)
+	assert.Error(err, "the title_id column is required")
+	_, err = dvd.ImportCSV(ctx, svc, strings.NewReader(""), 2)
+	assert.Error(err)
+}
+
+func TestExport(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	repo := new(mocks.Repository)
+	svc := dvd.NewService(repo, dvd.NewWaitlistPolicy(nil), log.NewNopLogger(), discard.NewCounter(), discard.NewHistogram())
+	createdAt := time.Date(2020, 3, 1, 10, 0, 0, 0, time.UTC)
+	title := &dvd.Title{Base: model.Base{ID: "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7"}, Name: "Casablanca"}
+	copies := []*dvd.Copy{
+		{Base: model.Base{ID: "c1", CreatedAt: createdAt}, TitleID: title.ID, Title: title, Barcode: "B1", Condition: dvd.Good, Status: dvd.Available},
+		{Base: model.Base{ID: "c2", CreatedAt: createdAt}, TitleID: title.ID, Title: title, Barcode: "B2", Condition: dvd.Fair, Status: dvd.Sold},
+	}
+	f := dvd.Filter{TitleID: title.ID}
+	repo.On("Search", f, (*dvd.Cursor)(nil), mock.Anything).Return(copies, nil)
+
+	var buf bytes.Buffer
+	assert.NoError(dvd.Export(ctx, svc, &buf, dvd.ExportCSV, f))
+	assert.Equal("id,title_id,title,barcode,condition,status,created_at\n"+
+		"c1,"+title.ID+",Casablanca,B1,good,Available,2020-03-01T10:00:00Z\n"+
+		"c2,"+title.ID+",Casablanca,B2,fair,Sold,2020-03-01T10:00:00Z\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(dvd.Export(ctx, svc, &buf, dvd.ExportJSONL, f))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(lines, 2)
+	assert.Contains(lines[1], `"barcode":"B2"`)
+
+	assert.Error(dvd.Export(ctx, svc, &buf, "xlsx", f))
+}
+
+func TestInventoryHandlerLimited(t *testing.T) {
+	assert := assert.New(t)
+	repo := new(mocks.Repository)
+	svc := dvd.NewService(repo, dvd.NewWaitlistPolicy(nil), log.NewNopLogger(), discard.NewCounter(), discard.NewHistogram())
+	titleID := "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7"
+	repo.On("GetTitle", titleID).Return(&dvd.Title{Base: model.Base{ID: titleID}}, nil).Once()
+	repo.On("TakenBarcodes", []string{"B1"}).Return([]string{}, nil).Once()
+	repo.On("StoreMany", mock.Anything).Return(nil).Once()
+
+	guard := limits.NewGuard(config.Limits{Rate: 0.001, Burst: 1}, log.NewNopLogger())
+	endpoints := dvd.NewDVDEndpoint(svc, stdopentracing.NoopTracer{}, nil, guard)
+	h := dvd.MakeInventoryHandler(endpoints, log.NewNopLogger(), stdopentracing.NoopTracer{})
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return w
+	}
+
+	file := "barcode,title_id,condition\nB1," + titleID + ",good\n"
+	w := serve("POST", "/dvd/v1/dvds/import", file)
+	assert.Equal(http.StatusOK, w.Code)
+	assert.JSONEq(`{"imported":1}`, w.Body.String())
+	w = serve("POST", "/dvd/v1/dvds/import", file)
+	assert.Equal(http.StatusTooManyRequests, w.Code, "imports are rate limited like every other endpoint")
+
+	w = serve("GET", "/dvd/v1/dvds/export?format=xlsx", "")
+	assert.Equal(http.StatusBadRequest, w.Code)
+	repo.On("Search", dvd.Filter{}, (*dvd.Cursor)(nil), mock.Anything).Return([]*dvd.Copy{}, nil).Once()
+	w = serve("GET", "/dvd/v1/dvds/export", "")
+	assert.Equal(http.StatusOK, w.Code)
+	w = serve("GET", "/dvd/v1/dvds/export", "")
+	assert.Equal(http.StatusTooManyRequests, w.Code, "exports are rate limited like every other endpoint")
+	assert.Empty(w.Header().Get("Content-Disposition"))
+	repo.AssertExpectations(t)
+}
+
+// mapStore is an idempotency.Store keeping the records in memory, leases and ttls are ignored
+type mapStore struct {
+	records map[string]*idempotency.Record
+}
+
+func (s *mapStore) Claim(key string, _ time.Duration) (bool, error) {
+	if _, ok := s.records[key]; ok {
+		return false, nil
+	}
+	s.records[key] = nil
+	return true, nil
+}
+
+func (s *mapStore) Get(key string) (*idempotency.Record, error) { return s.records[key], nil }
+
+func (s *mapStore) Save(key string, r *idempotency.Record, _ time.Duration) error {
+	s.records[key] = r
+	return nil
+}
+
+func (s *mapStore) Release(key string) error {
+	delete(s.records, key)
+	return nil
+}
+
+func TestImportBatchesIdempotent(t *testing.T) {
+	assert := assert.New(t)
+	repo := new(mocks.Repository)
+	svc := dvd.NewService(repo, dvd.NewWaitlistPolicy(nil), log.NewNopLogger(), discard.NewCounter(), discard.NewHistogram())
+	titleID := "18eb0b6e-8757-4dfb-b062-1c7944e2b8f7"
+	repo.On("GetTitle", titleID).Return(&dvd.Title{Base: model.Base{ID: titleID}}, nil)
+	repo.On("TakenBarcodes", []string{"B1", "B2"}).Return([]string{}, nil).Once()
+	repo.On("TakenBarcodes", []string{"B3"}).Return([]string{}, nil).Once()
+	repo.On("StoreMany", mock.Anything).Return(nil).Twice()
+
+	keeper := idempotency.NewKeeper(&mapStore{records: map[string]*idempotency.Record{}}, 60, log.NewNopLogger())
+	guard := limits.NewGuard(config.Limits{Rate: 100, Burst: 100}, log.NewNopLogger())
+	endpoints := dvd.NewDVDEndpoint(svc, stdopentracing.NoopTracer{}, keeper, guard)
+	ctx := idempotency.NewContext(context.Background(), "import-1")
+	file := "barcode,title_id,condition\n" +
+		"B1," + titleID + ",good\n" +
+		"B2," + titleID + ",good\n" +
+		"B3," + titleID + ",good\n"
+
+	first, err := dvd.ImportCSV(ctx, endpoints, strings.NewReader(file), 2)
+	assert.NoError(err)
+	assert.Equal(3, first.Imported)
+	//* The retry replays both batches instead of importing them again
+	retry, err := dvd.ImportCSV(ctx, endpoints, strings.NewReader(file), 2)
+	assert.NoError(err)
+	assert.Equal(first, retry)
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,80 @@
+package dvd
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/google/uuid"
+	"github.com/ngray1747/dvd-rental/internal/config"
+	"github.com/ngray1747/dvd-rental/internal/model"
+)
+
+const (
+	defaultHoldPeriod    = 48 * time.Hour
+	defaultSweepInterval = time.Minute
+)
+
+// Reservation is the place of a customer in the waitlist of a title
+type Reservation struct {
+	model.Base
+	TitleID    string `pg:",notnull" json:"title_id"`
+	CustomerID string `pg:",notnull" json:"customer_id"`
+}
+
+// NewReservation init a reservation of a title for a customer
+func NewReservation(titleID, customerID string) (*Reservation, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Reservation{
+		Base: model.Base{
+			ID: id.String(),
+		},
+		TitleID:    titleID,
+		CustomerID: customerID,
+	}, nil
+}
+
+// WaitlistPolicy decides how long a returned copy is held for the next customer in line
+// and how often expired holds are looked for
+type WaitlistPolicy struct {
+	Hold          time.Duration
+	SweepInterval time.Duration
+}
+
+// NewWaitlistPolicy builds a policy from the service's reservation config, falling back to defaults for unset values
+func NewWaitlistPolicy(cfg *config.Reservation) WaitlistPolicy {
+	p := WaitlistPolicy{
+		Hold:          defaultHoldPeriod,
+		SweepInterval: defaultSweepInterval,
+	}
+	if cfg == nil {
+		return p
+	}
+	if cfg.HoldPeriod > 0 {
+		p.Hold = time.Duration(cfg.HoldPeriod) * time.Hour
+	}
+	if cfg.SweepInterval > 0 {
+		p.SweepInterval = time.Duration(cfg.SweepInterval) * time.Second
+	}
+	return p
+}
+
+// RunHoldExpiry releases expired holds every interval until ctx is done
+func RunHoldExpiry(ctx context.Context, svc Service, interval time.Duration, logger log.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := svc.ExpireHolds(ctx); err != nil {
+				logger.Log("method", "RunHoldExpiry", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,143 @@
+// Package apperr defines the errors shared by the services, tagged with a code
+// that survives a trip over gRPC and decides the status reported to clients.
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-kit/kit/ratelimit"
+	"github.com/go-pg/pg/v9"
+	"github.com/ngray1747/dvd-rental/internal/apperr/errpb"
+	"github.com/sony/gobreaker"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Code classifies an error by how a caller should react to it
+type Code string
+
+const (
+	Unknown         Code = ""
+	NotFound        Code = "not-found"
+	Conflict        Code = "conflict"
+	InvalidArgument Code = "invalid-argument"
+	Unavailable     Code = "unavailable"
+	RateLimited     Code = "rate-limited"
+)
+
+// Error is an error with a code attached
+type Error struct {
+	Code    Code
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// New returns an error with the given code and message
+func New(code Code, message string) error {
+	return &Error{Code: code, Message: message}
+}
+
+// Errorf formats an error with the given code
+func Errorf(code Code, format string, args ...interface{}) error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// CodeOf returns the code of err, recognising the errors of the libraries the services are built on
+func CodeOf(err error) Code {
+	var e *Error
+	switch {
+	case err == nil:
+		return Unknown
+	case errors.As(err, &e):
+		return e.Code
+	case errors.Is(err, pg.ErrNoRows):
+		return NotFound
+	case errors.Is(err, ratelimit.ErrLimited):
+		return RateLimited
+	case errors.Is(err, gobreaker.ErrOpenState), errors.Is(err, gobreaker.ErrTooManyRequests):
+		return Unavailable
+	}
+	return Unknown
+}
+
+// IsDomain reports whether err is a failure of the request rather than of the service answering it
+func IsDomain(err error) bool {
+	switch CodeOf(err) {
+	case NotFound, Conflict, InvalidArgument:
+		return true
+	}
+	return false
+}
+
+// HTTPStatus returns the status code an HTTP response failing with err should have
+func HTTPStatus(err error) int {
+	switch CodeOf(err) {
+	case NotFound:
+		return http.StatusNotFound
+	case Conflict:
+		return http.StatusConflict
+	case InvalidArgument:
+		return http.StatusBadRequest
+	case Unavailable:
+		return http.StatusServiceUnavailable
+	case RateLimited:
+		return http.StatusTooManyRequests
+	}
+	return http.StatusInternalServerError
+}
+
+var grpcCodes = map[Code]codes.Code{
+	NotFound:        codes.NotFound,
+	Conflict:        codes.FailedPrecondition,
+	InvalidArgument: codes.InvalidArgument,
+	Unavailable:     codes.Unavailable,
+	RateLimited:     codes.ResourceExhausted,
+}
+
+// ToGRPC converts err into a gRPC status error carrying its code in the status details
+func ToGRPC(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	code := CodeOf(err)
+	c, ok := grpcCodes[code]
+	if !ok {
+		return status.Error(codes.Unknown, err.Error())
+	}
+	s, derr := status.New(c, err.Error()).WithDetails(&errpb.Error{Code: string(code), Message: err.Error()})
+	if derr != nil {
+		return status.Error(c, err.Error())
+	}
+	return s.Err()
+}
+
+// FromGRPC turns a gRPC status error back into an *Error.
+// Statuses without details, such as those raised by grpc itself, are classified by their gRPC code.
+func FromGRPC(err error) error {
+	s, ok := status.FromError(err)
+	if !ok || err == nil {
+		return err
+	}
+	for _, d := range s.Details() {
+		if e, ok := d.(*errpb.Error); ok {
+			return &Error{Code: Code(e.Code), Message: e.Message}
+		}
+	}
+	for code, c := range grpcCodes {
+		if c == s.Code() {
+			return &Error{Code: code, Message: s.Message()}
+		}
+	}
+	if s.Code() == codes.DeadlineExceeded {
+		return &Error{Code: Unavailable, Message: s.Message()}
+	}
+	return &Error{Code: Unknown, Message: s.Message()}
+}
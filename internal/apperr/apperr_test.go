@@ -0,0 +1,110 @@
+package apperr_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/go-kit/kit/ratelimit"
+	"github.com/go-pg/pg/v9"
+	"github.com/ngray1747/dvd-rental/internal/apperr"
+	"github.com/sony/gobreaker"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCodeOf(t *testing.T) {
+	assert := assert.New(t)
+	cases := []struct {
+		name       string
+		err        error
+		wantCode   apperr.Code
+		wantStatus int
+	}{
+		{
+			name:       "not found",
+			err:        apperr.New(apperr.NotFound, "rental not found"),
+			wantCode:   apperr.NotFound,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "wrapped conflict",
+			err:        fmt.Errorf("rent: %w", apperr.New(apperr.Conflict, "dvd not available")),
+			wantCode:   apperr.Conflict,
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "no rows",
+			err:        pg.ErrNoRows,
+			wantCode:   apperr.NotFound,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "rate limited",
+			err:        ratelimit.ErrLimited,
+			wantCode:   apperr.RateLimited,
+			wantStatus: http.StatusTooManyRequests,
+		},
+		{
+			name:       "breaker open",
+			err:        gobreaker.ErrOpenState,
+			wantCode:   apperr.Unavailable,
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:       "unknown",
+			err:        errors.New("boom"),
+			wantCode:   apperr.Unknown,
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, c := range cases {
+		assert.Equal(c.wantCode, apperr.CodeOf(c.err), c.name)
+		assert.Equal(c.wantStatus, apperr.HTTPStatus(c.err), c.name)
+	}
+}
+
+func TestGRPC(t *testing.T) {
+	assert := assert.New(t)
+	cases := []struct {
+		name     string
+		err      error
+		wantGRPC codes.Code
+		wantCode apperr.Code
+	}{
+		{
+			name:     "invalid argument",
+			err:      apperr.New(apperr.InvalidArgument, "invalid dvd id"),
+			wantGRPC: codes.InvalidArgument,
+			wantCode: apperr.InvalidArgument,
+		},
+		{
+			name:     "conflict",
+			err:      apperr.New(apperr.Conflict, "dvd not available"),
+			wantGRPC: codes.FailedPrecondition,
+			wantCode: apperr.Conflict,
+		},
+		{
+			name:     "unknown",
+			err:      errors.New("boom"),
+			wantGRPC: codes.Unknown,
+			wantCode: apperr.Unknown,
+		},
+	}
+
+	for _, c := range cases {
+		err := apperr.ToGRPC(c.err)
+		assert.Equal(c.wantGRPC, status.Code(err), c.name)
+
+		err = apperr.FromGRPC(err)
+		assert.Equal(c.wantCode, apperr.CodeOf(err), c.name)
+		assert.Equal(c.err.Error(), err.Error(), c.name)
+	}
+
+	err := apperr.FromGRPC(status.Error(codes.Unavailable, "connection refused"))
+	assert.Equal(apperr.Unavailable, apperr.CodeOf(err))
+	assert.False(apperr.IsDomain(err))
+}
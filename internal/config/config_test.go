@@ -0,0 +1,260 @@
+package config_test
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ngray1747/dvd-rental/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// setenv sets the environment variables and returns a func unsetting them
+func setenv(env map[string]string) func() {
+	for k, v := range env {
+		os.Setenv(k, v)
+	}
+	return func() {
+		for k := range env {
+			os.Unsetenv(k)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		name    string
+		env     map[string]string
+		args    []string
+		check   func(t *testing.T, cfg *config.Configuration)
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			check: func(t *testing.T, cfg *config.Configuration) {
+				assert.Equal(t, "dev", cfg.Env)
+				assert.Equal(t, ":9999", cfg.Server.HTTPAddr)
+				assert.Equal(t, 15, cfg.Server.ShutdownTimeout)
+				svc, err := cfg.Service("dvd")
+				assert.NoError(t, err)
+				assert.Equal(t, "dvd_rental_dvd", svc.Database.DBName)
+				assert.Equal(t, "my-user", svc.Database.User, "services inherit the shared database settings")
+			},
+		},
+		{
+			name: "environment overrides file",
+			env:  map[string]string{"DVD_RENTAL_ENV": "prod", "DVD_RENTAL_DATABASE_ADDR": "db:5432", "DVD_RENTAL_SERVER_HTTPADDR": ":80"},
+			check: func(t *testing.T, cfg *config.Configuration) {
+				assert.Equal(t, "prod", cfg.Env)
+				assert.Equal(t, 30, cfg.Server.ShutdownTimeout)
+				assert.Equal(t, ":80", cfg.Server.HTTPAddr)
+				svc, _ := cfg.Service("customer")
+				assert.Equal(t, "db:5432", svc.Database.Addr)
+			},
+		},
+		{
+			name: "flags override environment",
+			env:  map[string]string{"DVD_RENTAL_DATABASE_ADDR": "db:5432"},
+			args: []string{"-dbHost=localhost:5432", "-shutdownTimeout=3", "-env=prod"},
+			check: func(t *testing.T, cfg *config.Configuration) {
+				assert.Equal(t, "prod", cfg.Env)
+				assert.Equal(t, 3, cfg.Server.ShutdownTimeout)
+				svc, _ := cfg.Service("dvd")
+				assert.Equal(t, "localhost:5432", svc.Database.Addr)
+			},
+		},
+		{
+			name:    "unknown environment",
+			args:    []string{"-env=staging"},
+			wantErr: true,
+		},
+	}
+	for _, v := range cases {
+		t.Run(v.name, func(t *testing.T) {
+			defer setenv(map[string]string{"DVD_RENTAL_CONFIG_DIR": "."})()
+			defer setenv(v.env)()
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			config.RegisterFlags(fs)
+			cfg, err := config.Parse(fs, v.args)
+			assert.Equal(t, v.wantErr, err != nil, err)
+			if err == nil {
+				v.check(t, cfg)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name     string
+		args     []string
+		wantErrs int
+	}{
+		{
+			name: "valid",
+			args: []string{"-service=all", "-zipkinAddr=zipkin:9411", "-dbHost=db:5432", "-redisAddr=redis:6379"},
+		},
+		{
+			name:     "every problem reported",
+			args:     []string{"-service=all", "-dbPassword="},
+			wantErrs: 7,
+		},
+		{
+			name:     "unknown service",
+			args:     []string{"-service=store", "-zipkinAddr=zipkin:9411"},
+			wantErrs: 1,
+		},
+		{
+			name:     "missing credentials",
+			args:     []string{"-service=dvd", "-zipkinAddr=zipkin:9411", "-dbHost=db:5432", "-redisAddr=redis:6379", "-dbUserName=", "-dbPassword="},
+			wantErrs: 2,
+		},
+		{
+			name:     "default password outside dev",
+			args:     []string{"-env=prod", "-service=all", "-zipkinAddr=zipkin:9411", "-dbHost=db:5432", "-redisAddr=redis:6379"},
+			wantErrs: 2,
+		},
+	}
+	for _, v := range cases {
+		t.Run(v.name, func(t *testing.T) {
+			defer setenv(map[string]string{"DVD_RENTAL_CONFIG_DIR": "."})()
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			config.RegisterFlags(fs)
+			cfg, err := config.Parse(fs, v.args)
+			assert.NoError(t, err)
+
+			err = cfg.Validate()
+			if v.wantErrs == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Len(t, err, v.wantErrs, err)
+		})
+	}
+}
+
+func TestSecretFile(t *testing.T) {
+	f, err := ioutil.TempFile("", "db_password")
+	assert.NoError(t, err)
+	defer os.Remove(f.Name())
+	f.WriteString("s3cret\n")
+	f.Close()
+
+	cases := []struct {
+		name         string
+		env          map[string]string
+		args         []string
+		wantPassword string
+		wantErr      bool
+	}{
+		{
+			name:         "read from file",
+			env:          map[string]string{"DVD_RENTAL_DATABASE_PASSWORD_FILE": f.Name()},
+			wantPassword: "s3cret",
+		},
+		{
+			name:         "flag takes precedence",
+			env:          map[string]string{"DVD_RENTAL_DATABASE_PASSWORD_FILE": f.Name()},
+			args:         []string{"-dbPassword=other"},
+			wantPassword: "other",
+		},
+		{
+			name:    "both set",
+			env:     map[string]string{"DVD_RENTAL_DATABASE_PASSWORD_FILE": f.Name(), "DVD_RENTAL_DATABASE_PASSWORD": "other"},
+			wantErr: true,
+		},
+		{
+			name:    "missing file",
+			env:     map[string]string{"DVD_RENTAL_DATABASE_PASSWORD_FILE": f.Name() + ".missing"},
+			wantErr: true,
+		},
+	}
+	for _, v := range cases {
+		t.Run(v.name, func(t *testing.T) {
+			defer setenv(map[string]string{"DVD_RENTAL_CONFIG_DIR": "."})()
+			defer setenv(v.env)()
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			config.RegisterFlags(fs)
+			cfg, err := config.Parse(fs, v.args)
+			assert.Equal(t, v.wantErr, err != nil, err)
+			if err != nil {
+				return
+			}
+			svc, _ := cfg.Service("customer")
+			assert.Equal(t, config.Secret(v.wantPassword), svc.Database.Password)
+			assert.NotContains(t, cfg.String(), v.wantPassword, "secrets are redacted from dumps")
+			assert.NotContains(t, fmt.Sprintf("%+v %#v", *svc.Database, *svc.Database), v.wantPassword)
+		})
+	}
+}
+
+func TestDatabaseOptions(t *testing.T) {
+	d := config.Database{DBName: "dvd", Addr: "db:5432", User: "u", Password: "p", Timeout: 10}
+	opts, err := d.Options()
+	assert.NoError(t, err)
+	assert.Equal(t, "db:5432", opts.Addr)
+	assert.Equal(t, 10*time.Second, opts.ReadTimeout)
+
+	d.PSN = "postgres://admin:secret@pg:5433/store?sslmode=disable"
+	opts, err = d.Options()
+	assert.NoError(t, err)
+	assert.Equal(t, "pg:5433", opts.Addr)
+	assert.Equal(t, "admin", opts.User)
+	assert.Equal(t, "store", opts.Database)
+
+	d.PSN = "postgres://admin:secret@pg:port/store"
+	_, err = d.Options()
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), "secret", "the password is not leaked in errors")
+}
+
+func TestWatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	write := func(rate string) {
+		assert.NoError(t, ioutil.WriteFile(dir+"/dev.yml", []byte("limits:\n  rate: "+rate+"\nservices:\n- name: dvd\n  database:\n    dbName: dvd\n"), 0644))
+	}
+	write("1")
+	defer setenv(map[string]string{"DVD_RENTAL_CONFIG_DIR": dir})()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	config.RegisterFlags(fs)
+	cfg, err := config.Parse(fs, []string{"-service=dvd", "-zipkinAddr=zipkin:9411", "-dbHost=db:5432", "-redisAddr=redis:6379"})
+	assert.NoError(t, err)
+	assert.NoError(t, cfg.Validate())
+
+	type reload struct {
+		cfg *config.Configuration
+		err error
+	}
+	reloads := make(chan reload, 10)
+	cfg.Watch(func(next *config.Configuration, err error) {
+		reloads <- reload{next, err}
+	})
+	next := func() reload {
+		select {
+		case r := <-reloads:
+			return r
+		case <-time.After(5 * time.Second):
+			t.Fatal("configuration not reloaded")
+			return reload{}
+		}
+	}
+
+	//* A write may be seen halfway, as an invalid configuration
+	write("20")
+	r := next()
+	for ; r.err != nil; r = next() {
+	}
+	svc, _ := r.cfg.Service("dvd")
+	assert.Equal(t, 20.0, svc.Limits.Rate)
+	assert.Equal(t, "db:5432", svc.Database.Addr, "flags keep overriding the file")
+
+	write("-1")
+	for r = next(); r.err == nil; r = next() {
+	}
+	assert.Error(t, r.err, "invalid changes are not applied")
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// setting is a configuration key which can be overridden from the command line
+type setting struct {
+	flag  string
+	key   string
+	usage string
+}
+
+// settings are kept in the order their flags are listed, the flag names are the ones the command line always had
+var settings = []setting{
+	{"httpAddr", "server.httpAddr", "Http server address"},
+	{"grpcAddr", "server.grpcAddr", "GRPC server address"},
+	{"customerGrpcAddr", "server.customerGrpcAddr", "Customer GRPC server address"},
+	{"metricsAddr", "server.metricsAddr", "Metrics server address, metrics are served on httpAddr when empty"},
+	{"service", "server.services", "Services to run, customer, dvd or both comma separated, all runs every service"},
+	{"namespace", "server.namespace", "Service namespace"},
+	{"shutdownTimeout", "server.shutdownTimeout", "Seconds to drain in-flight calls, then as long to stop workers, on shutdown"},
+	{"zipkinAddr", "tracing.zipkinAddr", "Zipkin tracer address"},
+	{"dbHost", "database.addr", "Postgresql host"},
+	{"dbUserName", "database.user", "Postgresql username"},
+	{"dbPassword", "database.password", "Postgresql password"},
+	{"redisAddr", "cache.addr", "Redis cache address"},
+	{"redisPassword", "cache.password", "Redis cache password"},
+}
+
+// RegisterFlags adds the env flag and the flags of the named settings to fs, every setting when no name is given
+func RegisterFlags(fs *flag.FlagSet, names ...string) {
+	fs.String("env", envDefault(), "Configuration environment, the internal/config/<env>.yml file loaded")
+	for _, s := range settings {
+		if len(names) > 0 && !contains(names, s.flag) {
+			continue
+		}
+		fs.String(s.flag, fmt.Sprint(defaults[s.key]), s.usage)
+	}
+}
+
+// Parse parses args with fs, which RegisterFlags prepared, and loads the selected environment.
+// Flags set on the command line take precedence over environment variables, which take precedence over the file.
+func Parse(fs *flag.FlagSet, args []string) (*Configuration, error) {
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	env := envDefault()
+	if f := fs.Lookup("env"); f != nil {
+		env = f.Value.String()
+	}
+	cfg, err := load(env, fs)
+	if err != nil {
+		return nil, err
+	}
+	cfg.resolve()
+	return cfg, nil
+}
+
+func envDefault() string {
+	if env := os.Getenv(envPrefix + "_ENV"); env != "" {
+		return env
+	}
+	return DefaultEnv
+}
+
+// flagValue lets viper read a command line flag, only once it is set
+type flagValue struct {
+	f   *flag.Flag
+	set bool
+}
+
+func (v flagValue) HasChanged() bool    { return v.set }
+func (v flagValue) Name() string        { return v.f.Name }
+func (v flagValue) ValueString() string { return v.f.Value.String() }
+func (v flagValue) ValueType() string   { return "string" }
+
+// flagValues returns the flags of fs overriding a setting, by setting key
+func flagValues(fs *flag.FlagSet) map[string]flagValue {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	values := make(map[string]flagValue)
+	for _, s := range settings {
+		if f := fs.Lookup(s.flag); f != nil {
+			values[s.key] = flagValue{f: f, set: set[s.flag]}
+		}
+	}
+	return values
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// DefaultPassword is the development database password, refused outside the dev environment
+const DefaultPassword = "password123"
+
+// redacted replaces a secret wherever it is printed
+const redacted = "******"
+
+// secretKeys are the settings which may be read from the file named by their environment variable suffixed with _FILE,
+// e.g. DVD_RENTAL_DATABASE_PASSWORD_FILE=/run/secrets/db_password for the Docker and Kubernetes mounted secrets
+var secretKeys = []string{"database.password", "database.psn", "cache.password"}
+
+// Secret is a setting which is never printed, neither in logs nor in configuration dumps
+type Secret string
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+// GoString redacts the secret from %#v
+func (s Secret) GoString() string {
+	return fmt.Sprintf("%q", s.String())
+}
+
+// MarshalYAML redacts the secret from configuration dumps
+func (s Secret) MarshalYAML() (interface{}, error) {
+	return s.String(), nil
+}
+
+// envName returns the environment variable overriding key
+func envName(key string) string {
+	return envPrefix + "_" + strings.ToUpper(strings.Replace(key, ".", "_", -1))
+}
+
+// readSecrets reads the secrets referenced by a _FILE environment variable, unless a flag sets them
+func readSecrets(v *viper.Viper, flags map[string]flagValue) error {
+	for _, key := range secretKeys {
+		name := envName(key)
+		path := os.Getenv(name + "_FILE")
+		if path == "" || flags[key].set {
+			continue
+		}
+		if _, ok := os.LookupEnv(name); ok {
+			return fmt.Errorf("%s and %s_FILE are both set", name, name)
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("%s_FILE: %v", name, err)
+		}
+		v.Set(key, strings.TrimRight(string(b), "\r\n"))
+	}
+	return nil
+}
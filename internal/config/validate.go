@@ -0,0 +1,149 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Errors are all the problems found in a configuration
+type Errors []error
+
+func (e Errors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e *Errors) add(format string, args ...interface{}) {
+	*e = append(*e, fmt.Errorf(format, args...))
+}
+
+// err returns nil when no problem was found, so a nil Errors is never returned as a non-nil error
+func (e Errors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Validate reports every problem preventing the selected services from starting, as Errors
+func (c *Configuration) Validate() error {
+	var errs Errors
+	if c.Tracing.ZipkinAddr == "" {
+		errs.add("tracing.zipkinAddr (-zipkinAddr) is required")
+	}
+	if c.Server.ShutdownTimeout < 0 {
+		errs.add("server.shutdownTimeout must not be negative")
+	}
+	services, err := c.Server.Selected()
+	switch {
+	case err != nil:
+		errs.add("server.services (-service): %v", err)
+	case len(services) == 0:
+		errs.add("server.services (-service) is required, customer, dvd or all")
+	}
+	for _, name := range []string{"customer", "dvd"} {
+		if !services[name] {
+			continue
+		}
+		svc, err := c.Service(name)
+		if err != nil {
+			errs.add("%v", err)
+			continue
+		}
+		errs = append(errs, svc.validate(c.Env)...)
+	}
+	return errs.err()
+}
+
+// ValidateStore reports every problem preventing service name from reaching its database, and its cache when withCache
+func (c *Configuration) ValidateStore(name string, withCache bool) error {
+	svc, err := c.Service(name)
+	if err != nil {
+		return err
+	}
+	errs := svc.Database.validate(name, c.Env)
+	if withCache {
+		errs = append(errs, svc.Cache.validate(name)...)
+	}
+	return errs.err()
+}
+
+func (s *Service) validate(env string) Errors {
+	errs := s.Database.validate(s.Name, env)
+	errs = append(errs, s.Cache.validate(s.Name)...)
+	if s.Cache.IdempotencyTTL < 0 {
+		errs.add("%s: cache.idempotencyTTL must not be negative", s.Name)
+	}
+	if s.Outbox != nil {
+		switch s.Outbox.Publisher {
+		case "", "redis":
+		default:
+			errs.add("%s: outbox.publisher %q is not redis, the only publisher consumers can read", s.Name, s.Outbox.Publisher)
+		}
+		if s.Outbox.RelayInterval < 0 || s.Outbox.BatchSize < 0 {
+			errs.add("%s: outbox.relayInterval and outbox.batchSize must not be negative", s.Name)
+		}
+	}
+	if s.Rental != nil && (s.Rental.Period < 0 || s.Rental.GracePeriod < 0 || s.Rental.LateFeePerDay < 0 || s.Rental.SagaRecoveryInterval < 0) {
+		errs.add("%s: rental settings must not be negative", s.Name)
+	}
+	if s.Reservation != nil && (s.Reservation.HoldPeriod < 0 || s.Reservation.SweepInterval < 0) {
+		errs.add("%s: reservation settings must not be negative", s.Name)
+	}
+	errs = append(errs, s.Limits.validate(s.Name, "limits")...)
+	errs = append(errs, s.DVDClient.validate(s.Name, "dvdClient")...)
+	return errs
+}
+
+func (l *Limits) validate(service, key string) Errors {
+	var errs Errors
+	if l.Rate <= 0 || l.Burst <= 0 {
+		errs.add("%s: %s.rate and %s.burst must be positive", service, key, key)
+	}
+	if l.Breaker.Interval < 0 || l.Breaker.Timeout < 0 {
+		errs.add("%s: %s.breaker.interval and %s.breaker.timeout must not be negative", service, key, key)
+	}
+	return errs
+}
+
+func (d *Database) validate(service, env string) Errors {
+	var errs Errors
+	if d.Timeout < 0 {
+		errs.add("%s: database.timeout must not be negative", service)
+	}
+	if d.PSN != "" {
+		opts, err := d.Options()
+		if err != nil {
+			errs.add("%s: %v", service, err)
+		} else if env != DefaultEnv && opts.Password == DefaultPassword {
+			errs.add("%s: database.psn uses the default password, refused outside the %s environment", service, DefaultEnv)
+		}
+		return errs
+	}
+	if d.DBName == "" {
+		errs.add("%s: database.dbName is required", service)
+	}
+	if d.Addr == "" {
+		errs.add("%s: database.addr (-dbHost) is required", service)
+	}
+	if d.User == "" {
+		errs.add("%s: database.user (-dbUserName) is required", service)
+	}
+	if d.Password == "" {
+		errs.add("%s: database.password (-dbPassword) is required", service)
+	} else if env != DefaultEnv && d.Password == DefaultPassword {
+		errs.add("%s: database.password is the default password, refused outside the %s environment", service, DefaultEnv)
+	}
+	return errs
+}
+
+func (c *Cache) validate(service string) Errors {
+	var errs Errors
+	if c.Addr == "" {
+		errs.add("%s: cache.addr (-redisAddr) is required", service)
+	}
+	return errs
+}
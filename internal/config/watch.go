@@ -0,0 +1,28 @@
+package config
+
+import (
+	"github.com/fsnotify/fsnotify"
+)
+
+// Watch calls apply with the configuration reloaded each time the environment file changes,
+// or with the error which kept it from being reloaded. Environment variables and flags keep overriding the file.
+func (c *Configuration) Watch(apply func(*Configuration, error)) {
+	if c.v == nil {
+		return
+	}
+	v, env := c.v, c.Env
+	v.OnConfigChange(func(fsnotify.Event) {
+		next, err := decode(v, env)
+		if err != nil {
+			apply(nil, err)
+			return
+		}
+		next.resolve()
+		if err := next.Validate(); err != nil {
+			apply(nil, err)
+			return
+		}
+		apply(next, nil)
+	})
+	v.WatchConfig()
+}
@@ -0,0 +1,243 @@
+// Package health reports whether the services are alive and ready to serve, over HTTP (/healthz, /readyz)
+// and the standard grpc.health.v1 service. A service is ready when all of its dependencies answer.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/go-redis/redis/v7"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	// DefaultInterval is how often the gRPC serving status is refreshed
+	DefaultInterval = 5 * time.Second
+	// checkTimeout bounds a round of checks so a hung dependency reads as not ready
+	checkTimeout = 2 * time.Second
+)
+
+// Check reports why a dependency can not be used, nil when it can
+type Check func(ctx context.Context) error
+
+type check struct {
+	service string
+	name    string
+	fn      Check
+}
+
+// Result is the outcome of a check
+type Result struct {
+	Service string
+	Name    string
+	Err     error
+}
+
+// Checker runs the readiness checks of the services of a process
+type Checker struct {
+	mu       sync.Mutex
+	checks   []check
+	grpc     *grpchealth.Server
+	drain    sync.Once
+	draining chan struct{}
+	Interval time.Duration
+}
+
+// NewChecker returns a checker refreshing the gRPC serving status every interval, DefaultInterval when not positive
+func NewChecker(interval time.Duration) *Checker {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Checker{grpc: grpchealth.NewServer(), draining: make(chan struct{}), Interval: interval}
+}
+
+// Add registers a check gating service, the full gRPC name of the service (e.g. pb.DVDRental)
+func (c *Checker) Add(service, name string, fn Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, check{service: service, name: name, fn: fn})
+	c.grpc.SetServingStatus(service, healthpb.HealthCheckResponse_NOT_SERVING)
+}
+
+// Check runs every check concurrently
+func (c *Checker) Check(ctx context.Context) []Result {
+	c.mu.Lock()
+	checks := append([]check(nil), c.checks...)
+	c.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, ch := range checks {
+		wg.Add(1)
+		go func(i int, ch check) {
+			defer wg.Done()
+			results[i] = Result{Service: ch.service, Name: ch.name, Err: ch.fn(ctx)}
+		}(i, ch)
+	}
+	wg.Wait()
+	return results
+}
+
+// GRPC returns the grpc.health.v1 service, to register on every gRPC server of the process.
+// The empty service name stands for the whole process.
+func (c *Checker) GRPC() healthpb.HealthServer {
+	return &healthServer{Server: c.grpc, draining: c.draining}
+}
+
+// Drain reports every service as not serving for good, /readyz answers 503 Service Unavailable and
+// grpc.health.v1 Watch streams end, so the servers can be drained without new calls being routed to them.
+func (c *Checker) Drain() {
+	c.drain.Do(func() {
+		c.grpc.Shutdown()
+		close(c.draining)
+	})
+}
+
+func (c *Checker) isDraining() bool {
+	select {
+	case <-c.draining:
+		return true
+	default:
+		return false
+	}
+}
+
+// Run refreshes the gRPC serving status of each service until ctx is done, then drains the checker
+func (c *Checker) Run(ctx context.Context) {
+	c.refresh(ctx)
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			c.Drain()
+			return
+		case <-ticker.C:
+			c.refresh(ctx)
+		}
+	}
+}
+
+func (c *Checker) refresh(ctx context.Context) {
+	serving := map[string]bool{"": true}
+	for _, r := range c.Check(ctx) {
+		if _, ok := serving[r.Service]; !ok {
+			serving[r.Service] = true
+		}
+		if r.Err != nil {
+			serving[r.Service], serving[""] = false, false
+		}
+	}
+	for service, ok := range serving {
+		status := healthpb.HealthCheckResponse_SERVING
+		if !ok {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		c.grpc.SetServingStatus(service, status)
+	}
+}
+
+// LiveHandler answers as long as the process serves http, for liveness probes
+func LiveHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-type", "application/json; charset=utf-8")
+		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+	})
+}
+
+// ReadyHandler runs the checks and answers 503 Service Unavailable when any fails or the checker is drained,
+// for readiness probes
+func (c *Checker) ReadyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status, code := "ok", http.StatusOK
+		checks := make(map[string]string)
+		for _, res := range c.Check(r.Context()) {
+			checks[res.Name] = "ok"
+			if res.Err != nil {
+				checks[res.Name] = res.Err.Error()
+				status, code = "unavailable", http.StatusServiceUnavailable
+			}
+		}
+		if c.isDraining() {
+			status, code = "draining", http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-type", "application/json; charset=utf-8")
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": status,
+			"checks": checks,
+		})
+	})
+}
+
+// healthServer ends Watch streams once the checker is drained, they would otherwise keep a graceful stop waiting
+type healthServer struct {
+	*grpchealth.Server
+	draining <-chan struct{}
+}
+
+func (s *healthServer) Watch(in *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	go func() {
+		select {
+		case <-s.draining:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	err := s.Server.Watch(in, watchStream{Health_WatchServer: stream, ctx: ctx})
+	select {
+	case <-s.draining:
+		//* The last status may not have been sent before the stream context was cancelled
+		return stream.Send(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_NOT_SERVING})
+	default:
+		return err
+	}
+}
+
+type watchStream struct {
+	healthpb.Health_WatchServer
+	ctx context.Context
+}
+
+func (s watchStream) Context() context.Context {
+	return s.ctx
+}
+
+// Postgres checks db answers a query
+func Postgres(db *pg.DB) Check {
+	return func(ctx context.Context) error {
+		_, err := db.WithContext(ctx).Exec("SELECT 1")
+		return err
+	}
+}
+
+// Redis checks cli answers a ping
+func Redis(cli *redis.Client) Check {
+	return func(ctx context.Context) error {
+		return cli.WithContext(ctx).Ping().Err()
+	}
+}
+
+// GRPCConn checks conn is connected, or idle and able to connect on the next call
+func GRPCConn(conn *grpc.ClientConn) Check {
+	return func(ctx context.Context) error {
+		switch state := conn.GetState(); state {
+		case connectivity.Ready, connectivity.Idle:
+			return nil
+		default:
+			return fmt.Errorf("connection is %s", state)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ngray1747/dvd-rental/internal/health"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func ok(context.Context) error { return nil }
+
+func down(context.Context) error { return errors.New("connection refused") }
+
+func TestReadyHandler(t *testing.T) {
+	cases := []struct {
+		name     string
+		checks   map[string]health.Check
+		wantCode int
+	}{
+		{
+			name:     "ready",
+			checks:   map[string]health.Check{"dvd postgres": ok, "dvd redis": ok},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "dependency down",
+			checks:   map[string]health.Check{"dvd postgres": ok, "dvd redis": down},
+			wantCode: http.StatusServiceUnavailable,
+		},
+	}
+	for _, v := range cases {
+		t.Run(v.name, func(t *testing.T) {
+			c := health.NewChecker(time.Minute)
+			for name, check := range v.checks {
+				c.Add("pb.DVDRental", name, check)
+			}
+			w := httptest.NewRecorder()
+			c.ReadyHandler().ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+			assert.Equal(t, v.wantCode, w.Code)
+
+			var body struct {
+				Checks map[string]string `json:"checks"`
+			}
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+			assert.Len(t, body.Checks, len(v.checks))
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	c := health.NewChecker(time.Minute)
+	c.Add("pb.DVDRental", "dvd postgres", ok)
+	c.Add("pb.CustomerService", "dvd grpc", down)
+
+	status := func(service string) healthpb.HealthCheckResponse_ServingStatus {
+		res, err := c.GRPC().Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			return healthpb.HealthCheckResponse_UNKNOWN
+		}
+		return res.Status
+	}
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, status("pb.DVDRental"), "services are not serving until checked")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		c.Run(ctx)
+		close(stopped)
+	}()
+	assert.Eventually(t, func() bool {
+		return status("pb.DVDRental") == healthpb.HealthCheckResponse_SERVING
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, status("pb.CustomerService"))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, status(""), "the process is not serving while a service is not")
+
+	cancel()
+	<-stopped
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, status("pb.DVDRental"), "services stop serving on shutdown")
+}
+
+// watchStream records the statuses sent on a grpc.health.v1 Watch stream
+type watchStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent chan healthpb.HealthCheckResponse_ServingStatus
+}
+
+func (s *watchStream) Context() context.Context { return s.ctx }
+
+func (s *watchStream) Send(res *healthpb.HealthCheckResponse) error {
+	s.sent <- res.Status
+	return nil
+}
+
+type statusSetter interface {
+	SetServingStatus(service string, status healthpb.HealthCheckResponse_ServingStatus)
+}
+
+func TestDrain(t *testing.T) {
+	c := health.NewChecker(time.Minute)
+	c.Add("pb.DVDRental", "dvd postgres", ok)
+	c.GRPC().(statusSetter).SetServingStatus("pb.DVDRental", healthpb.HealthCheckResponse_SERVING)
+
+	stream := &watchStream{ctx: context.Background(), sent: make(chan healthpb.HealthCheckResponse_ServingStatus, 4)}
+	ended := make(chan error)
+	go func() {
+		ended <- c.GRPC().Watch(&healthpb.HealthCheckRequest{Service: "pb.DVDRental"}, stream)
+	}()
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, <-stream.sent)
+
+	c.Drain()
+	select {
+	case err := <-ended:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("watch stream did not end on drain")
+	}
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, <-stream.sent)
+
+	w := httptest.NewRecorder()
+	c.ReadyHandler().ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code, "not ready once drained, even with every check passing")
+	assert.NotPanics(t, c.Drain, "drain is idempotent")
+}
@@ -0,0 +1,194 @@
+// Package idempotency lets clients retry mutating calls safely: a call made with an idempotency key
+// is executed once, repeats of it within a TTL get the response of the first call back.
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/ngray1747/dvd-rental/internal/apperr"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// Header is the HTTP header carrying the key
+	Header = "Idempotency-Key"
+	// MetadataKey is the gRPC metadata key carrying the key
+	MetadataKey = "idempotency-key"
+
+	defaultTTL = 24 * time.Hour
+	// defaultLease is how long a key stays in progress when the call holding it never completes, e.g. on a crash
+	defaultLease = time.Minute
+)
+
+var (
+	errInProgress  = apperr.New(apperr.Conflict, "a request with this idempotency key is in progress")
+	errKeyMismatch = apperr.New(apperr.InvalidArgument, "idempotency key was used for a different request")
+)
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the idempotency key
+func NewContext(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, contextKey{}, key)
+}
+
+// FromContext returns the idempotency key of ctx, empty when the call has none
+func FromContext(ctx context.Context) string {
+	key, _ := ctx.Value(contextKey{}).(string)
+	return key
+}
+
+// HTTPToContext moves the Idempotency-Key header to the context, for use as a kithttp.ServerBefore
+func HTTPToContext(ctx context.Context, r *http.Request) context.Context {
+	if key := r.Header.Get(Header); key != "" {
+		return NewContext(ctx, key)
+	}
+	return ctx
+}
+
+// GRPCToContext moves the idempotency-key metadata to the context, for use as a grpctransport.ServerBefore
+func GRPCToContext(ctx context.Context, md metadata.MD) context.Context {
+	if values := md.Get(MetadataKey); len(values) > 0 && values[0] != "" {
+		return NewContext(ctx, values[0])
+	}
+	return ctx
+}
+
+// Record is what is stored for a key once its call completed
+type Record struct {
+	// RequestHash tells a retry from another request reusing the key
+	RequestHash string          `json:"request_hash"`
+	Response    json.RawMessage `json:"response"`
+}
+
+// Store keeps the records of idempotency keys
+type Store interface {
+	// Claim marks key as in progress for lease and reports whether it was free
+	Claim(key string, lease time.Duration) (bool, error)
+	// Get returns the record of a completed key, nil while it is in progress or once it expired
+	Get(key string) (*Record, error)
+	// Save records the outcome of the call holding key for ttl, replacing its lease
+	Save(key string, r *Record, ttl time.Duration) error
+	// Release frees key so the call can be retried
+	Release(key string) error
+}
+
+// Keeper replays the responses of calls made with an idempotency key
+type Keeper struct {
+	store  Store
+	ttl    time.Duration
+	logger log.Logger
+	// Lease is how long a key is claimed while its call runs, retries get errInProgress until the call completes
+	// or the lease runs out. It outlasts the slowest call, a crashed call then only holds its key that long.
+	Lease time.Duration
+}
+
+// NewKeeper remembers responses in store for ttl seconds, a day when ttl is not set, and logs store failures to logger
+func NewKeeper(store Store, ttl int, logger log.Logger) *Keeper {
+	k := &Keeper{store: store, ttl: defaultTTL, logger: logger, Lease: defaultLease}
+	if ttl > 0 {
+		k.ttl = time.Duration(ttl) * time.Second
+	}
+	return k
+}
+
+// Middleware makes an endpoint idempotent for the calls carrying a key. Keys are scoped by name.
+// response is a value of the endpoint's response type, replayed responses are decoded into a new one.
+// Only successful responses are kept, a call which failed or whose response implements endpoint.Failer
+// and failed runs again when retried. A nil Keeper leaves the endpoint as is.
+func (k *Keeper) Middleware(name string, response interface{}) endpoint.Middleware {
+	responseType := reflect.TypeOf(response)
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		if k == nil {
+			return next
+		}
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			key := FromContext(ctx)
+			if key == "" {
+				return next(ctx, request)
+			}
+			key = name + ":" + key
+			hash, err := hashRequest(request)
+			if err != nil {
+				return nil, err
+			}
+
+			lease := k.Lease
+			if lease > k.ttl {
+				lease = k.ttl
+			}
+			claimed, err := k.store.Claim(key, lease)
+			if err != nil {
+				return nil, err
+			}
+			if !claimed {
+				return k.replay(key, hash, responseType)
+			}
+
+			res, err := next(ctx, request)
+			if err != nil {
+				k.release(key)
+				return nil, err
+			}
+			if f, ok := res.(endpoint.Failer); ok && f.Failed() != nil {
+				k.release(key)
+				return res, nil
+			}
+
+			body, err := json.Marshal(res)
+			if err != nil {
+				k.logger.Log("method", "Keeper.Middleware", "key", key, "error", err)
+				k.release(key)
+				return res, nil
+			}
+			//* The call is done, a failure to record it only costs a retry its replay once the lease runs out
+			if err := k.store.Save(key, &Record{RequestHash: hash, Response: body}, k.ttl); err != nil {
+				k.logger.Log("method", "Keeper.Middleware", "key", key, "error", err)
+			}
+			return res, nil
+		}
+	}
+}
+
+// release frees key for a retry, a failure to free it holds retries off until the lease runs out
+func (k *Keeper) release(key string) {
+	if err := k.store.Release(key); err != nil {
+		k.logger.Log("method", "Keeper.Middleware", "key", key, "error", err)
+	}
+}
+
+func (k *Keeper) replay(key, hash string, responseType reflect.Type) (interface{}, error) {
+	r, err := k.store.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, errInProgress
+	}
+	if r.RequestHash != hash {
+		return nil, errKeyMismatch
+	}
+
+	res := reflect.New(responseType)
+	if err := json.Unmarshal(r.Response, res.Interface()); err != nil {
+		return nil, err
+	}
+	return res.Elem().Interface(), nil
+}
+
+func hashRequest(request interface{}) (string, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
+}
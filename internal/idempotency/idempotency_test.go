@@ -0,0 +1,193 @@
+package idempotency_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/ngray1747/dvd-rental/internal/apperr"
+	"github.com/ngray1747/dvd-rental/internal/idempotency"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryStore struct {
+	mu      sync.Mutex
+	records map[string]*idempotency.Record
+	// expiry is the last lease or ttl a key was stored for
+	expiry  map[string]time.Duration
+	saveErr error
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{records: map[string]*idempotency.Record{}, expiry: map[string]time.Duration{}}
+}
+
+func (s *memoryStore) Claim(key string, lease time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.records[key]; ok {
+		return false, nil
+	}
+	s.records[key] = nil
+	s.expiry[key] = lease
+	return true, nil
+}
+
+func (s *memoryStore) Get(key string) (*idempotency.Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.records[key], nil
+}
+
+func (s *memoryStore) Save(key string, r *idempotency.Record, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.saveErr != nil {
+		return s.saveErr
+	}
+	s.records[key] = r
+	s.expiry[key] = ttl
+	return nil
+}
+
+func (s *memoryStore) Release(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
+
+type request struct {
+	ID string `json:"id"`
+}
+
+type response struct {
+	Count int   `json:"count"`
+	Err   error `json:"-"`
+}
+
+func (r response) Failed() error { return r.Err }
+
+func TestMiddleware(t *testing.T) {
+	failWith := errors.New("dvd service down")
+	testCases := []struct {
+		name      string
+		key       string
+		requests  []request
+		failFirst bool
+		expected  []int
+		err       error
+	}{
+		{
+			name:     "no key",
+			requests: []request{{ID: "1"}, {ID: "1"}},
+			expected: []int{1, 2},
+		},
+		{
+			name:     "replay",
+			key:      "key-1",
+			requests: []request{{ID: "1"}, {ID: "1"}},
+			expected: []int{1, 1},
+		},
+		{
+			name:     "key reused for another request",
+			key:      "key-1",
+			requests: []request{{ID: "1"}, {ID: "2"}},
+			expected: []int{1},
+			err:      apperr.New(apperr.InvalidArgument, "idempotency key was used for a different request"),
+		},
+		{
+			name:      "failed call runs again",
+			key:       "key-1",
+			requests:  []request{{ID: "1"}, {ID: "1"}},
+			failFirst: true,
+			expected:  []int{0, 2},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := assert.New(t)
+			keeper := idempotency.NewKeeper(newMemoryStore(), 0, log.NewNopLogger())
+			count := 0
+			ep := keeper.Middleware("rent", response{})(func(_ context.Context, _ interface{}) (interface{}, error) {
+				count++
+				if tc.failFirst && count == 1 {
+					return response{Err: failWith}, nil
+				}
+				return response{Count: count}, nil
+			})
+
+			ctx := context.Background()
+			if tc.key != "" {
+				ctx = idempotency.NewContext(ctx, tc.key)
+			}
+			var counts []int
+			var err error
+			for _, req := range tc.requests {
+				var res interface{}
+				if res, err = ep(ctx, req); err != nil {
+					break
+				}
+				counts = append(counts, res.(response).Count)
+			}
+			assert.Equal(tc.expected, counts)
+			assert.Equal(tc.err, err)
+		})
+	}
+}
+
+func TestMiddlewareInProgress(t *testing.T) {
+	assert := assert.New(t)
+	keeper := idempotency.NewKeeper(newMemoryStore(), 0, log.NewNopLogger())
+	ctx := idempotency.NewContext(context.Background(), "key-1")
+	started, release := make(chan struct{}), make(chan struct{})
+	ep := keeper.Middleware("rent", response{})(func(_ context.Context, _ interface{}) (interface{}, error) {
+		close(started)
+		<-release
+		return response{Count: 1}, nil
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ep(ctx, request{ID: "1"})
+	}()
+	<-started
+	_, err := ep(ctx, request{ID: "1"})
+	assert.Equal(apperr.Conflict, apperr.CodeOf(err))
+	close(release)
+	<-done
+
+	res, err := ep(ctx, request{ID: "1"})
+	assert.NoError(err)
+	assert.Equal(response{Count: 1}, res)
+}
+
+func TestMiddlewareLease(t *testing.T) {
+	assert := assert.New(t)
+	store := newMemoryStore()
+	var logged []interface{}
+	keeper := idempotency.NewKeeper(store, 3600, log.LoggerFunc(func(keyvals ...interface{}) error {
+		logged = append(logged, keyvals...)
+		return nil
+	}))
+	ctx := idempotency.NewContext(context.Background(), "key-1")
+	var leased time.Duration
+	ep := keeper.Middleware("rent", response{})(func(_ context.Context, _ interface{}) (interface{}, error) {
+		leased = store.expiry["rent:key-1"]
+		return response{Count: 1}, nil
+	})
+
+	_, err := ep(ctx, request{ID: "1"})
+	assert.NoError(err)
+	assert.Equal(keeper.Lease, leased, "a call in progress only holds its key for the lease")
+	assert.Equal(time.Hour, store.expiry["rent:key-1"], "a completed call is kept for the ttl")
+
+	store.saveErr = errors.New("redis down")
+	_, err = ep(idempotency.NewContext(ctx, "key-2"), request{ID: "1"})
+	assert.NoError(err, "the call is done even when its record is lost")
+	assert.Contains(logged, store.saveErr)
+}
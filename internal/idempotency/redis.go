@@ -0,0 +1,54 @@
+package idempotency
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/go-redis/redis/v7"
+)
+
+// keyPrefix namespaces the idempotency keys in Redis
+const keyPrefix = "idempotency:"
+
+// inProgress is stored for a claimed key until its call completes
+const inProgress = ""
+
+type redisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore keeps idempotency records in Redis, where they expire on their own
+func NewRedisStore(client *redis.Client) Store {
+	return &redisStore{client: client}
+}
+
+func (s *redisStore) Claim(key string, lease time.Duration) (bool, error) {
+	return s.client.SetNX(keyPrefix+key, inProgress, lease).Result()
+}
+
+func (s *redisStore) Get(key string) (*Record, error) {
+	val, err := s.client.Get(keyPrefix + key).Bytes()
+	if err == redis.Nil || (err == nil && string(val) == inProgress) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	r := new(Record)
+	if err := json.Unmarshal(val, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (s *redisStore) Save(key string, r *Record, ttl time.Duration) error {
+	val, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(keyPrefix+key, val, ttl).Err()
+}
+
+func (s *redisStore) Release(key string) error {
+	return s.client.Del(keyPrefix + key).Err()
+}
@@ -0,0 +1,215 @@
+// Package lifecycle runs the servers and background workers of a process and shuts them down gracefully:
+// the process stops reporting itself ready, servers drain their in-flight calls, workers stop, then clients are
+// closed in the reverse order they were opened.
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"google.golang.org/grpc"
+)
+
+// DefaultTimeout bounds a shutdown when no timeout is given
+const DefaultTimeout = 15 * time.Second
+
+// Server is a listener of the process
+type Server interface {
+	// Serve blocks until the server fails, it returns nil once the server is shut down
+	Serve() error
+	// Shutdown stops accepting calls and waits for the in-flight ones until ctx is done
+	Shutdown(ctx context.Context) error
+}
+
+type namedServer struct {
+	Server
+	name string
+	addr string
+}
+
+type closer struct {
+	name  string
+	close func() error
+}
+
+// Manager owns the servers, workers and clients of a process
+type Manager struct {
+	timeout time.Duration
+	logger  log.Logger
+	ctx     context.Context
+	cancel  context.CancelFunc
+	workers sync.WaitGroup
+	servers []namedServer
+	drains  []func()
+	closers []closer
+}
+
+// New returns a manager which gives servers timeout to drain their in-flight calls on shutdown, then workers as long to stop
+func New(timeout time.Duration, logger log.Logger) *Manager {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Manager{timeout: timeout, logger: logger, ctx: ctx, cancel: cancel}
+}
+
+// Go starts worker, the context it is given is cancelled on shutdown once the servers stopped
+func (m *Manager) Go(name string, worker func(ctx context.Context)) {
+	m.workers.Add(1)
+	go func() {
+		defer m.workers.Done()
+		worker(m.ctx)
+		m.logger.Log("worker", name, "msg", "stopped")
+	}()
+}
+
+// Serve registers a server started by Run
+func (m *Manager) Serve(name, addr string, s Server) {
+	m.servers = append(m.servers, namedServer{Server: s, name: name, addr: addr})
+}
+
+// OnDrain registers drain to run on shutdown before the servers drain, to stop traffic being routed to the process
+func (m *Manager) OnDrain(drain func()) {
+	m.drains = append(m.drains, drain)
+}
+
+// OnClose registers a client to close on shutdown, after the workers stopped and before the clients registered earlier
+func (m *Manager) OnClose(name string, close func() error) {
+	m.closers = append(m.closers, closer{name: name, close: close})
+}
+
+// Run starts the servers and shuts everything down on SIGINT or SIGTERM, when ctx is done or when a server fails.
+// It returns the error of the failed server, if any.
+func (m *Manager) Run(ctx context.Context) error {
+	errs := make(chan error, len(m.servers))
+	for _, s := range m.servers {
+		go func(s namedServer) {
+			m.logger.Log("transport", s.name, "address", s.addr, "msg", "listening")
+			if err := s.Serve(); err != nil {
+				errs <- fmt.Errorf("%s: %v", s.name, err)
+			}
+		}(s)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	var err error
+	select {
+	case sig := <-signals:
+		m.logger.Log("signal", sig, "msg", "shutting down")
+	case err = <-errs:
+		m.logger.Log("err", err, "msg", "shutting down")
+	case <-ctx.Done():
+		m.logger.Log("msg", "shutting down")
+	}
+	m.shutdown()
+	return err
+}
+
+func (m *Manager) shutdown() {
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+
+	for _, drain := range m.drains {
+		drain()
+	}
+
+	//* Servers drain first, their in-flight calls may still need the workers and clients
+	var wg sync.WaitGroup
+	for _, s := range m.servers {
+		wg.Add(1)
+		go func(s namedServer) {
+			defer wg.Done()
+			if err := s.Shutdown(ctx); err != nil {
+				m.logger.Log("transport", s.name, "msg", "in-flight calls cut off", "err", err)
+			}
+			m.logger.Log("transport", s.name, "msg", "stopped")
+		}(s)
+	}
+	wg.Wait()
+
+	m.cancel()
+	timer := time.NewTimer(m.timeout)
+	defer timer.Stop()
+	stopped := make(chan struct{})
+	go func() {
+		m.workers.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-timer.C:
+		m.logger.Log("msg", "workers did not stop in time")
+	}
+
+	for i := len(m.closers) - 1; i >= 0; i-- {
+		c := m.closers[i]
+		if err := c.close(); err != nil {
+			m.logger.Log("close", c.name, "err", err)
+		}
+	}
+}
+
+type httpServer struct {
+	srv *http.Server
+}
+
+// HTTPServer serves h on addr
+func HTTPServer(addr string, h http.Handler) Server {
+	return &httpServer{srv: &http.Server{Addr: addr, Handler: h}}
+}
+
+func (s *httpServer) Serve() error {
+	if err := s.srv.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+func (s *httpServer) Shutdown(ctx context.Context) error {
+	return s.srv.Shutdown(ctx)
+}
+
+type grpcServer struct {
+	addr string
+	srv  *grpc.Server
+}
+
+// GRPCServer serves s on addr
+func GRPCServer(addr string, s *grpc.Server) Server {
+	return &grpcServer{addr: addr, srv: s}
+}
+
+func (s *grpcServer) Serve() error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	return s.srv.Serve(listener)
+}
+
+// Shutdown stops the server gracefully, pending calls are cancelled once ctx is done
+func (s *grpcServer) Shutdown(ctx context.Context) error {
+	stopped := make(chan struct{})
+	go func() {
+		s.srv.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.srv.Stop()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,114 @@
+package lifecycle_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/ngray1747/dvd-rental/internal/lifecycle"
+	"github.com/stretchr/testify/assert"
+)
+
+type events struct {
+	mu   sync.Mutex
+	list []string
+}
+
+func (e *events) add(event string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.list = append(e.list, event)
+}
+
+func (e *events) get() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.list...)
+}
+
+// fakeServer serves until it is shut down, or fails with err, and takes drain to drain its in-flight calls
+type fakeServer struct {
+	events  *events
+	err     error
+	drain   time.Duration
+	stopped chan struct{}
+}
+
+func newFakeServer(e *events, err error, drain time.Duration) *fakeServer {
+	return &fakeServer{events: e, err: err, drain: drain, stopped: make(chan struct{})}
+}
+
+func (s *fakeServer) Serve() error {
+	if s.err != nil {
+		return s.err
+	}
+	<-s.stopped
+	return nil
+}
+
+func (s *fakeServer) Shutdown(ctx context.Context) error {
+	defer close(s.stopped)
+	select {
+	case <-time.After(s.drain):
+		s.events.add("server drained")
+		return nil
+	case <-ctx.Done():
+		s.events.add("server cut off")
+		return ctx.Err()
+	}
+}
+
+func TestRun(t *testing.T) {
+	cases := []struct {
+		name    string
+		timeout time.Duration
+		err     error
+		drain   time.Duration
+		want    []string
+	}{
+		{
+			name:    "graceful",
+			timeout: time.Second,
+			drain:   10 * time.Millisecond,
+			want:    []string{"drain", "server drained", "worker stopped", "close second", "close first"},
+		},
+		{
+			name:    "deadline",
+			timeout: 10 * time.Millisecond,
+			drain:   time.Second,
+			want:    []string{"drain", "server cut off", "worker stopped", "close second", "close first"},
+		},
+		{
+			name:    "server fails",
+			timeout: time.Second,
+			err:     errors.New("address already in use"),
+			want:    []string{"drain", "server drained", "worker stopped", "close second", "close first"},
+		},
+	}
+	for _, v := range cases {
+		t.Run(v.name, func(t *testing.T) {
+			e := new(events)
+			app := lifecycle.New(v.timeout, log.NewNopLogger())
+			app.Serve("fake", ":0", newFakeServer(e, v.err, v.drain))
+			app.OnDrain(func() { e.add("drain") })
+			app.Go("worker", func(ctx context.Context) {
+				<-ctx.Done()
+				e.add("worker stopped")
+			})
+			app.OnClose("first", func() error { e.add("close first"); return nil })
+			app.OnClose("second", func() error { e.add("close second"); return nil })
+
+			ctx, cancel := context.WithCancel(context.Background())
+			if v.err == nil {
+				cancel()
+			}
+			defer cancel()
+			err := app.Run(ctx)
+			assert.Equal(t, v.err != nil, err != nil, err)
+			assert.Equal(t, v.want, e.get())
+		})
+	}
+}
@@ -0,0 +1,119 @@
+// Package limits rate limits endpoints and guards them with circuit breakers whose settings can change at runtime,
+// so a reloaded configuration applies without restarting the service.
+package limits
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/ratelimit"
+	"github.com/ngray1747/dvd-rental/internal/config"
+	"github.com/sony/gobreaker"
+	"golang.org/x/time/rate"
+)
+
+// breaker is replaced by a breaker with the new settings when they change, since gobreaker settings are fixed
+type breaker struct {
+	mu   sync.RWMutex
+	name string
+	cb   *gobreaker.CircuitBreaker
+}
+
+func (b *breaker) get() *gobreaker.CircuitBreaker {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.cb
+}
+
+func (b *breaker) set(cb *gobreaker.CircuitBreaker) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.cb = cb
+}
+
+// Guard holds the limiters and breakers of a set of endpoints, all following the same settings
+type Guard struct {
+	mu       sync.Mutex
+	cfg      config.Limits
+	limiters []*rate.Limiter
+	breakers []*breaker
+	logger   log.Logger
+}
+
+// NewGuard returns a guard following cfg until it is updated, logging the changes to logger
+func NewGuard(cfg config.Limits, logger log.Logger) *Guard {
+	return &Guard{cfg: cfg, logger: logger}
+}
+
+// Limit returns a middleware rejecting the calls over the rate limit, the endpoints it wraps share the limit
+func (g *Guard) Limit() endpoint.Middleware {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	limiter := rate.NewLimiter(rate.Limit(g.cfg.Rate), g.cfg.Burst)
+	g.limiters = append(g.limiters, limiter)
+	return ratelimit.NewErroringLimiter(limiter)
+}
+
+// Break returns a middleware rejecting the calls while the breaker named name is open
+func (g *Guard) Break(name string) endpoint.Middleware {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	b := &breaker{name: name, cb: newBreaker(name, g.cfg.Breaker)}
+	g.breakers = append(g.breakers, b)
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			return b.get().Execute(func() (interface{}, error) {
+				return next(ctx, request)
+			})
+		}
+	}
+}
+
+// Update applies cfg to the limiters and breakers and logs each setting it changes.
+// Breakers are replaced, closed, when their settings change.
+func (g *Guard) Update(cfg config.Limits) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	old := g.cfg
+	g.cfg = cfg
+
+	if cfg.Rate != old.Rate || cfg.Burst != old.Burst {
+		for _, l := range g.limiters {
+			l.SetLimit(rate.Limit(cfg.Rate))
+			l.SetBurst(cfg.Burst)
+		}
+		g.changed("rate", old.Rate, cfg.Rate)
+		g.changed("burst", old.Burst, cfg.Burst)
+	}
+	if cfg.Breaker != old.Breaker {
+		for _, b := range g.breakers {
+			b.set(newBreaker(b.name, cfg.Breaker))
+		}
+		g.changed("breaker.maxRequests", old.Breaker.MaxRequests, cfg.Breaker.MaxRequests)
+		g.changed("breaker.interval", old.Breaker.Interval, cfg.Breaker.Interval)
+		g.changed("breaker.timeout", old.Breaker.Timeout, cfg.Breaker.Timeout)
+		g.changed("breaker.maxFailures", old.Breaker.MaxFailures, cfg.Breaker.MaxFailures)
+	}
+}
+
+func (g *Guard) changed(setting string, from, to interface{}) {
+	if from != to {
+		g.logger.Log("setting", setting, "from", from, "to", to, "msg", "applied")
+	}
+}
+
+func newBreaker(name string, cfg config.Breaker) *gobreaker.CircuitBreaker {
+	maxFailures := cfg.MaxFailures
+	return gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:        name,
+		MaxRequests: cfg.MaxRequests,
+		Interval:    time.Duration(cfg.Interval) * time.Second,
+		Timeout:     time.Duration(cfg.Timeout) * time.Second,
+		ReadyToTrip: func(counts gobreaker.Counts) bool {
+			return counts.ConsecutiveFailures > maxFailures
+		},
+	})
+}
@@ -0,0 +1,56 @@
+package limits_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/ratelimit"
+	"github.com/ngray1747/dvd-rental/internal/config"
+	"github.com/ngray1747/dvd-rental/internal/limits"
+	"github.com/sony/gobreaker"
+	"github.com/stretchr/testify/assert"
+)
+
+func ok(context.Context, interface{}) (interface{}, error) { return "ok", nil }
+
+func failing(context.Context, interface{}) (interface{}, error) {
+	return nil, errors.New("dvd service down")
+}
+
+func call(e endpoint.Endpoint) error {
+	_, err := e(context.Background(), nil)
+	return err
+}
+
+func TestUpdate(t *testing.T) {
+	cfg := config.Limits{Rate: 0.001, Burst: 1, Breaker: config.Breaker{MaxRequests: 1, Timeout: 60, MaxFailures: 1}}
+	var logs bytes.Buffer
+	guard := limits.NewGuard(cfg, log.NewLogfmtLogger(&logs))
+
+	limited := guard.Limit()(ok)
+	broken := guard.Break("Rent")(failing)
+
+	assert.NoError(t, call(limited))
+	assert.Equal(t, ratelimit.ErrLimited, call(limited))
+	for i := 0; i < 2; i++ {
+		assert.Error(t, call(broken))
+	}
+	assert.Equal(t, gobreaker.ErrOpenState, call(broken))
+
+	guard.Update(cfg)
+	assert.Empty(t, logs.String(), "unchanged settings are not logged")
+
+	cfg.Rate = 1000
+	cfg.Breaker.MaxFailures = 5
+	guard.Update(cfg)
+	assert.Eventually(t, func() bool { return call(limited) == nil }, time.Second, time.Millisecond, "the new rate applies")
+	assert.NotEqual(t, gobreaker.ErrOpenState, call(broken), "breakers are replaced, closed")
+	assert.Contains(t, logs.String(), "setting=rate from=0.001 to=1000")
+	assert.Contains(t, logs.String(), "setting=breaker.maxFailures from=1 to=5")
+	assert.NotContains(t, logs.String(), "setting=burst")
+}
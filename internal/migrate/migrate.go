@@ -0,0 +1,183 @@
+// Package migrate applies versioned schema migrations to a service database.
+// Migrations are SQL kept in the binary, the applied ones are recorded in the schema_migrations table.
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+)
+
+// lockKey serializes migrators of the same database, e.g. replicas starting together
+const lockKey = 7305183
+
+var errNothingApplied = errors.New("no migration has been applied")
+
+// Migration is a schema change, Down undoes Up
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status is a migration along with when it was applied, AppliedAt is zero while it is pending
+type Status struct {
+	Migration
+	AppliedAt time.Time
+}
+
+type record struct {
+	tableName struct{} `pg:"schema_migrations"`
+	Version   int      `pg:",pk"`
+	Name      string   `pg:",notnull"`
+	AppliedAt time.Time
+}
+
+// Migrator applies a set of migrations to a database
+type Migrator struct {
+	db         *pg.DB
+	migrations []Migration
+}
+
+// New checks that migrations are ordered by version and builds their migrator
+func New(db *pg.DB, migrations []Migration) (*Migrator, error) {
+	for i, m := range migrations {
+		if m.Version <= 0 {
+			return nil, fmt.Errorf("migration %q: version must be positive", m.Name)
+		}
+		if i > 0 && m.Version <= migrations[i-1].Version {
+			return nil, fmt.Errorf("migration %d %q: versions must be unique and increasing", m.Version, m.Name)
+		}
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+func (m *Migrator) init() error {
+	return m.db.CreateTable((*record)(nil), &orm.CreateTableOptions{IfNotExists: true})
+}
+
+func (m *Migrator) applied(db orm.DB) (map[int]time.Time, error) {
+	var records []*record
+	if err := db.Model(&records).Select(); err != nil {
+		return nil, err
+	}
+	applied := make(map[int]time.Time, len(records))
+	for _, r := range records {
+		applied[r.Version] = r.AppliedAt
+	}
+	return applied, nil
+}
+
+// Up applies the pending migrations in order and returns the ones it applied.
+// Each migration runs in its own transaction, a failure leaves the ones before it applied.
+func (m *Migrator) Up() ([]Migration, error) {
+	if err := m.init(); err != nil {
+		return nil, err
+	}
+	var done []Migration
+	for _, mig := range m.migrations {
+		mig := mig
+		ran := false
+		err := m.db.RunInTransaction(func(tx *pg.Tx) error {
+			if _, err := tx.Exec("SELECT pg_advisory_xact_lock(?)", lockKey); err != nil {
+				return err
+			}
+			applied, err := m.applied(tx)
+			if err != nil {
+				return err
+			}
+			if _, ok := applied[mig.Version]; ok {
+				return nil
+			}
+			if _, err := tx.Exec(mig.Up); err != nil {
+				return err
+			}
+			ran = true
+			return tx.Insert(&record{Version: mig.Version, Name: mig.Name, AppliedAt: time.Now()})
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %d %q: %v", mig.Version, mig.Name, err)
+		}
+		if ran {
+			done = append(done, mig)
+		}
+	}
+	return done, nil
+}
+
+// Down rolls back the latest applied migration and returns it
+func (m *Migrator) Down() (*Migration, error) {
+	if err := m.init(); err != nil {
+		return nil, err
+	}
+	var undone *Migration
+	err := m.db.RunInTransaction(func(tx *pg.Tx) error {
+		if _, err := tx.Exec("SELECT pg_advisory_xact_lock(?)", lockKey); err != nil {
+			return err
+		}
+		applied, err := m.applied(tx)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			mig := m.migrations[i]
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+			if _, err := tx.Exec(mig.Down); err != nil {
+				return fmt.Errorf("migration %d %q: %v", mig.Version, mig.Name, err)
+			}
+			if _, err := tx.Model(&record{Version: mig.Version}).WherePK().Delete(); err != nil {
+				return err
+			}
+			undone = &mig
+			return nil
+		}
+		return errNothingApplied
+	})
+	if err != nil {
+		return nil, err
+	}
+	return undone, nil
+}
+
+// Status lists every migration, in order, with when it was applied
+func (m *Migrator) Status() ([]Status, error) {
+	if err := m.init(); err != nil {
+		return nil, err
+	}
+	applied, err := m.applied(m.db)
+	if err != nil {
+		return nil, err
+	}
+	status := make([]Status, len(m.migrations))
+	for i, mig := range m.migrations {
+		status[i] = Status{Migration: mig, AppliedAt: applied[mig.Version]}
+	}
+	return status, nil
+}
+
+// CreateDatabase creates the database of opts when it does not exist yet, connecting to the
+// postgres maintenance database to do so. It reports whether the database was created.
+func CreateDatabase(opts *pg.Options) (bool, error) {
+	maintenance := *opts
+	maintenance.Database = "postgres"
+	db := pg.Connect(&maintenance)
+	defer db.Close()
+
+	var exists bool
+	if _, err := db.QueryOne(pg.Scan(&exists), "SELECT EXISTS (SELECT 1 FROM pg_database WHERE datname = ?)", opts.Database); err != nil {
+		return false, err
+	}
+	if exists {
+		return false, nil
+	}
+	if _, err := db.Exec("CREATE DATABASE ?", pg.Ident(opts.Database)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
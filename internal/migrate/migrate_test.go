@@ -0,0 +1,42 @@
+package migrate_test
+
+import (
+	"testing"
+
+	"github.com/ngray1747/dvd-rental/internal/migrate"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		name       string
+		migrations []migrate.Migration
+		wantErr    bool
+	}{
+		{
+			name:       "ordered",
+			migrations: []migrate.Migration{{Version: 1, Name: "a"}, {Version: 2, Name: "b"}, {Version: 5, Name: "c"}},
+		},
+		{
+			name:       "no version",
+			migrations: []migrate.Migration{{Name: "a"}},
+			wantErr:    true,
+		},
+		{
+			name:       "duplicate version",
+			migrations: []migrate.Migration{{Version: 1, Name: "a"}, {Version: 1, Name: "b"}},
+			wantErr:    true,
+		},
+		{
+			name:       "out of order",
+			migrations: []migrate.Migration{{Version: 2, Name: "a"}, {Version: 1, Name: "b"}},
+			wantErr:    true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := migrate.New(nil, tc.migrations)
+			assert.Equal(t, tc.wantErr, err != nil)
+		})
+	}
+}
@@ -4,15 +4,34 @@ import (
 	"context"
 	"time"
 
+	"github.com/go-pg/pg/v9"
 	"github.com/go-pg/pg/v9/orm"
+	"github.com/ngray1747/dvd-rental/internal/apperr"
 )
 
+// ErrStale is returned when a record was changed by someone else since it was read
+var ErrStale = apperr.New(apperr.Conflict, "record was changed concurrently, reload it and retry")
+
+// uniqueViolation is the SQLSTATE Postgres reports when a write breaks a unique index
+const uniqueViolation = "23505"
+
 // Base contains common fields for all tables
 type Base struct {
-	ID        string       `pg:",pk" json:"id,omitempty"`
+	ID        string    `pg:",pk" json:"id,omitempty"`
 	CreatedAt time.Time `json:"created_at,omitempty"`
 	UpdatedAt time.Time `json:"updated_at,omitempty"`
-	DeletedAt time.Time `pg:",soft_delete"json:"deleted_at,omitempty"`
+	DeletedAt time.Time `pg:",soft_delete" json:"deleted_at,omitempty"`
+	// Version is bumped by every update, see Update
+	Version int `pg:",notnull,default:1" json:"version,omitempty"`
+}
+
+// Versioned is a model embedding Base
+type Versioned interface {
+	base() *Base
+}
+
+func (b *Base) base() *Base {
+	return b
 }
 
 var _ orm.BeforeInsertHook = (*Base)(nil)
@@ -23,6 +42,7 @@ func (b *Base) BeforeInsert(c context.Context) (context.Context, error) {
 	now := time.Now()
 	b.CreatedAt = now
 	b.UpdatedAt = now
+	b.Version = 1
 	return c, nil
 }
 
@@ -31,3 +51,25 @@ func (b *Base) BeforeUpdate(c context.Context) (context.Context, error) {
 	b.UpdatedAt = time.Now()
 	return c, nil
 }
+
+// Update writes m only if its row still has the version m was read with, and bumps the version.
+// It returns ErrStale when the row was updated in between, the version of m is then left as it was.
+func Update(db orm.DB, m Versioned) error {
+	b := m.base()
+	version := b.Version
+	b.Version++
+	res, err := db.Model(m).WherePK().Where("version = ?", version).Update()
+	if err == nil && res.RowsAffected() == 0 {
+		err = ErrStale
+	}
+	if err != nil {
+		b.Version = version
+	}
+	return err
+}
+
+// IsUniqueViolation reports whether err is Postgres turning down a write breaking the unique index named index
+func IsUniqueViolation(err error, index string) bool {
+	pgErr, ok := err.(pg.Error)
+	return ok && pgErr.Field('C') == uniqueViolation && pgErr.Field('n') == index
+}
@@ -0,0 +1,84 @@
+// Package outbox publishes the events of a service reliably: events are written to an outbox table
+// in the same transaction as the change they describe, then a relay drains the table to an EventPublisher.
+// Delivery is at least once, consumers drop the events whose DedupeKey they have already seen.
+package outbox
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/google/uuid"
+	"github.com/ngray1747/dvd-rental/internal/migrate"
+)
+
+// Event types published by the services
+const (
+	CustomerRegistered = "CustomerRegistered"
+	DVDCreated         = "DVDCreated"
+	DVDRented          = "DVDRented"
+)
+
+// Event is a row of the outbox, PublishedAt is zero until the relay published it
+type Event struct {
+	tableName struct{} `pg:"outbox_events"`
+
+	ID          string          `pg:",pk" json:"id"`
+	Type        string          `pg:",notnull" json:"type"`
+	AggregateID string          `pg:",notnull" json:"aggregate_id"`
+	DedupeKey   string          `pg:",notnull,unique" json:"dedupe_key"`
+	Payload     json.RawMessage `pg:",notnull" json:"payload"`
+	CreatedAt   time.Time       `pg:",notnull" json:"created_at"`
+	PublishedAt time.Time       `json:"published_at,omitempty"`
+}
+
+// NewEvent init an event of type eventType about the entity aggregateID, payload is marshalled to JSON.
+// The dedupe key is unique per event, so each occurrence of a change is delivered once to a deduping consumer.
+func NewEvent(eventType, aggregateID string, payload interface{}) (*Event, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Event{
+		ID:          id.String(),
+		Type:        eventType,
+		AggregateID: aggregateID,
+		DedupeKey:   eventType + ":" + id.String(),
+		Payload:     body,
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+// Add writes an event to the outbox within tx, so it is published only if tx commits
+func Add(tx *pg.Tx, eventType, aggregateID string, payload interface{}) error {
+	e, err := NewEvent(eventType, aggregateID, payload)
+	if err != nil {
+		return err
+	}
+	return tx.Insert(e)
+}
+
+// Migration creates the outbox table, it takes version in the migrations of the service using the outbox
+func Migration(version int) migrate.Migration {
+	return migrate.Migration{
+		Version: version,
+		Name:    "create_outbox_events",
+		Up: `CREATE TABLE IF NOT EXISTS outbox_events (
+			id uuid NOT NULL,
+			type varchar(64) NOT NULL,
+			aggregate_id varchar(64) NOT NULL,
+			dedupe_key varchar(128) NOT NULL UNIQUE,
+			payload jsonb NOT NULL,
+			created_at timestamptz NOT NULL,
+			published_at timestamptz NULL,
+			CONSTRAINT outbox_events_pkey PRIMARY KEY (id)
+		);
+		CREATE INDEX IF NOT EXISTS outbox_events_unpublished_idx ON outbox_events (created_at) WHERE published_at IS NULL`,
+		Down: `DROP TABLE outbox_events`,
+	}
+}
@@ -0,0 +1,116 @@
+package outbox
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v7"
+)
+
+// EventPublisher delivers outbox events to the services reacting to them
+type EventPublisher interface {
+	Publish(ctx context.Context, e *Event) error
+}
+
+// Handler reacts to a published event
+type Handler func(ctx context.Context, e *Event) error
+
+// defaultSeenKeys is how many dedupe keys an in-process publisher remembers
+const defaultSeenKeys = 10000
+
+// InProcessPublisher hands events to handlers subscribed in the same process.
+// It remembers the latest dedupe keys so handlers do not see an event twice when the relay retries.
+// An event nobody subscribed to is not published, rather than dropped, so it stays in the outbox.
+type InProcessPublisher struct {
+	mu       sync.Mutex
+	handlers map[string][]Handler
+	seen     map[string]*list.Element
+	order    *list.List
+	maxSeen  int
+}
+
+// NewInProcessPublisher init a publisher without subscribers
+func NewInProcessPublisher() *InProcessPublisher {
+	return &InProcessPublisher{
+		handlers: make(map[string][]Handler),
+		seen:     make(map[string]*list.Element),
+		order:    list.New(),
+		maxSeen:  defaultSeenKeys,
+	}
+}
+
+// Subscribe registers h for the events of type eventType
+func (p *InProcessPublisher) Subscribe(eventType string, h Handler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.handlers[eventType] = append(p.handlers[eventType], h)
+}
+
+// Publish runs the handlers of the event type in order, the event is delivered again if one of them fails
+func (p *InProcessPublisher) Publish(ctx context.Context, e *Event) error {
+	p.mu.Lock()
+	_, dup := p.seen[e.DedupeKey]
+	handlers := p.handlers[e.Type]
+	p.mu.Unlock()
+	if dup {
+		return nil
+	}
+	if len(handlers) == 0 {
+		return fmt.Errorf("no subscriber for %s events", e.Type)
+	}
+
+	for _, h := range handlers {
+		if err := h(ctx, e); err != nil {
+			return err
+		}
+	}
+	p.remember(e.DedupeKey)
+	return nil
+}
+
+func (p *InProcessPublisher) remember(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.seen[key]; ok {
+		return
+	}
+	p.seen[key] = p.order.PushBack(key)
+	if p.order.Len() > p.maxSeen {
+		oldest := p.order.Front()
+		p.order.Remove(oldest)
+		delete(p.seen, oldest.Value.(string))
+	}
+}
+
+// defaultStreamMaxLen caps a stream so it does not grow forever, trimming is approximate
+const defaultStreamMaxLen = 100000
+
+// RedisStreamPublisher appends events to a Redis stream.
+// Consumers read the stream with a consumer group and drop entries whose dedupe_key they have already handled.
+type RedisStreamPublisher struct {
+	client *redis.Client
+	stream string
+}
+
+// NewRedisStreamPublisher init a publisher appending to stream
+func NewRedisStreamPublisher(client *redis.Client, stream string) *RedisStreamPublisher {
+	return &RedisStreamPublisher{client: client, stream: stream}
+}
+
+func (p *RedisStreamPublisher) Publish(ctx context.Context, e *Event) error {
+	return p.client.WithContext(ctx).XAdd(&redis.XAddArgs{
+		Stream:       p.stream,
+		MaxLenApprox: defaultStreamMaxLen,
+		Values: map[string]interface{}{
+			"id":           e.ID,
+			"type":         e.Type,
+			"aggregate_id": e.AggregateID,
+			"dedupe_key":   e.DedupeKey,
+			"payload":      string(e.Payload),
+			"created_at":   e.CreatedAt.Format(time.RFC3339Nano),
+		},
+	}).Err()
+}
@@ -0,0 +1,37 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ngray1747/dvd-rental/internal/outbox"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInProcessPublisher(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	publisher := outbox.NewInProcessPublisher()
+	var handled []string
+	fail := true
+	publisher.Subscribe(outbox.DVDRented, func(_ context.Context, e *outbox.Event) error {
+		if fail {
+			fail = false
+			return errors.New("handler failed")
+		}
+		handled = append(handled, e.AggregateID)
+		return nil
+	})
+
+	rented, err := outbox.NewEvent(outbox.DVDRented, "dvd-1", map[string]string{"rented_by": "customer-1"})
+	assert.NoError(err)
+	created, err := outbox.NewEvent(outbox.DVDCreated, "dvd-2", nil)
+	assert.NoError(err)
+
+	assert.Error(publisher.Publish(ctx, rented), "a failed handler gets the event again")
+	assert.NoError(publisher.Publish(ctx, rented))
+	assert.NoError(publisher.Publish(ctx, rented), "a relayed duplicate is dropped")
+	assert.Error(publisher.Publish(ctx, created), "events nobody subscribed to stay in the outbox")
+	assert.Equal([]string{"dvd-1"}, handled)
+}
@@ -0,0 +1,106 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-pg/pg/v9"
+	"github.com/ngray1747/dvd-rental/internal/config"
+)
+
+const (
+	defaultRelayInterval = time.Second
+	defaultBatchSize     = 100
+	// aggregateLockClass namespaces the advisory locks a relay holds on the entities whose events it publishes
+	aggregateLockClass = 7305184
+)
+
+// Relay drains the outbox of a service to a publisher, oldest event first
+type Relay struct {
+	db        *pg.DB
+	publisher EventPublisher
+	logger    log.Logger
+	Interval  time.Duration
+	BatchSize int
+}
+
+// NewRelay builds a relay from the service's outbox config, falling back to defaults for unset values
+func NewRelay(db *pg.DB, publisher EventPublisher, cfg *config.Outbox, logger log.Logger) *Relay {
+	r := &Relay{
+		db:        db,
+		publisher: publisher,
+		logger:    logger,
+		Interval:  defaultRelayInterval,
+		BatchSize: defaultBatchSize,
+	}
+	if cfg == nil {
+		return r
+	}
+	if cfg.RelayInterval > 0 {
+		r.Interval = time.Duration(cfg.RelayInterval) * time.Millisecond
+	}
+	if cfg.BatchSize > 0 {
+		r.BatchSize = cfg.BatchSize
+	}
+	return r
+}
+
+// Drain publishes a batch of unpublished events and returns how many it published.
+// It stops at the first failure so events of an entity are published in order,
+// an event published right before a failed commit is published again on the next drain.
+// Concurrent relays of the same service never drain the events of the same entity at once,
+// so they keep the order of each entity's events too.
+func (r *Relay) Drain(ctx context.Context) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	// Rollback tx on error.
+	defer tx.Close()
+	var events []*Event
+	//* The entities of the batch stay locked until the transaction ends, other relays skip their events
+	err = tx.Model(&events).
+		Where("published_at IS NULL").
+		Where("pg_try_advisory_xact_lock(?, hashtext(aggregate_id))", aggregateLockClass).
+		Order("created_at ASC").
+		Limit(r.BatchSize).
+		For("UPDATE SKIP LOCKED").
+		Select()
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	var publishErr error
+	for _, e := range events {
+		if publishErr = r.publisher.Publish(ctx, e); publishErr != nil {
+			break
+		}
+		e.PublishedAt = time.Now()
+		if _, err := tx.Model(e).Column("published_at").WherePK().Update(); err != nil {
+			return 0, err
+		}
+		n++
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return n, publishErr
+}
+
+// Run drains the outbox every Interval until ctx is done
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.Drain(ctx); err != nil {
+				r.logger.Log("method", "Relay.Run", "error", err)
+			}
+		}
+	}
+}
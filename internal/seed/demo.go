@@ -0,0 +1,80 @@
+package seed
+
+// Demo is the bundled demo catalog, a few customers and titles to play with locally
+var Demo = []byte(`customers:
+- id: c1e347c1-68cf-4d49-bae8-d16c7a818675
+  name: Ada Lovelace
+  address: 12 St James's Square, London
+- id: 1937e0b3-f5cd-4abd-bb9c-16cf49c30fa9
+  name: Alan Turing
+  address: 43 Adlington Road, Wilmslow
+- id: 41034f06-55c2-4e92-a96b-c5266dce7bfc
+  name: Grace Hopper
+  address: 1 Navy Yard, Arlington
+
+titles:
+- id: 8b04e3a1-cfd2-4249-b738-fd3e4600c458
+  name: Metropolis
+  year: 1927
+  runtime: 153
+  rating: NR
+  genres: [drama, sci-fi]
+  replacement_cost: 1999
+  copies:
+  - id: 528fe54d-f1c6-455a-8ce3-fbb4bf0febc5
+    barcode: DEMO-0001
+    condition: good
+  - id: 89ec1b3c-3b58-40f3-888d-c19322d8ce66
+    barcode: DEMO-0002
+    condition: fair
+- id: a44f1e1e-589b-46c4-b8b2-450241932134
+  name: Casablanca
+  year: 1942
+  runtime: 102
+  rating: PG
+  genres: [drama, romance]
+  replacement_cost: 1499
+  copies:
+  - id: 0ed5fd90-0844-4a8e-b0fa-85c1a2654419
+    barcode: DEMO-0003
+    condition: new
+- id: 3a24350c-bf95-4868-8b57-d618fd18d717
+  name: Seven Samurai
+  year: 1954
+  runtime: 207
+  rating: NR
+  genres: [action, drama]
+  replacement_cost: 2499
+  copies:
+  - id: aeff842f-5b78-43c4-9eb8-1094347b43ce
+    barcode: DEMO-0004
+    condition: good
+  - id: 68aedaac-eaf3-4304-a1d0-25c8e295dc6a
+    barcode: DEMO-0005
+    condition: poor
+- id: ae1d951c-06b5-4eb5-a742-4c170dcb3ec0
+  name: 2001 A Space Odyssey
+  year: 1968
+  runtime: 149
+  rating: G
+  genres: [adventure, sci-fi]
+  replacement_cost: 1999
+  copies:
+  - id: a8f5d4e6-5277-4dd6-8beb-4d79d3514f5c
+    barcode: DEMO-0006
+    condition: good
+- id: 7c15e2be-ebce-4880-bf2d-6775d8a0a0b4
+  name: Spirited Away
+  year: 2001
+  runtime: 125
+  rating: PG
+  genres: [animation, fantasy]
+  replacement_cost: 1799
+  copies:
+  - id: a13a8d32-1fc0-4e4d-8c8d-cfc7641d3614
+    barcode: DEMO-0007
+    condition: new
+  - id: 83785fa9-078e-43fe-b394-2293f9e3a403
+    barcode: DEMO-0008
+    condition: good
+`)
@@ -0,0 +1,232 @@
+// Package seed loads fixture data, customers and the dvd catalog, into the services.
+// Fixtures go through the service repositories so caches are filled as well, and seeding is idempotent:
+// records are matched by id, missing ones are created and seeding the same fixtures again changes nothing.
+package seed
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/google/uuid"
+	"github.com/ngray1747/dvd-rental/customer"
+	"github.com/ngray1747/dvd-rental/dvd"
+	"github.com/ngray1747/dvd-rental/internal/apperr"
+	"github.com/ngray1747/dvd-rental/internal/model"
+	"gopkg.in/yaml.v2"
+)
+
+// Fixtures is the content of a fixture file
+type Fixtures struct {
+	Customers []Customer `yaml:"customers"`
+	Titles    []Title    `yaml:"titles"`
+}
+
+// Customer is a customer fixture
+type Customer struct {
+	ID      string `yaml:"id"`
+	Name    string `yaml:"name"`
+	Address string `yaml:"address"`
+}
+
+// Title is a title fixture along with its copies
+type Title struct {
+	ID              string   `yaml:"id"`
+	Name            string   `yaml:"name"`
+	Year            int      `yaml:"year"`
+	Runtime         int      `yaml:"runtime"`
+	Rating          string   `yaml:"rating"`
+	Genres          []string `yaml:"genres"`
+	ReplacementCost int64    `yaml:"replacement_cost"`
+	Copies          []Copy   `yaml:"copies"`
+}
+
+// Copy is a copy fixture
+type Copy struct {
+	ID        string        `yaml:"id"`
+	Barcode   string        `yaml:"barcode"`
+	Condition dvd.Condition `yaml:"condition"`
+}
+
+// Result counts what seeding did
+type Result struct {
+	Created   int
+	Updated   int
+	Unchanged int
+}
+
+// Parse reads fixtures in YAML, or in JSON which is valid YAML
+func Parse(data []byte) (*Fixtures, error) {
+	f := new(Fixtures)
+	if err := yaml.UnmarshalStrict(data, f); err != nil {
+		return nil, err
+	}
+	if err := f.validate(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Load parses the fixture files at paths into a single set of fixtures
+func Load(paths ...string) (*Fixtures, error) {
+	all := new(Fixtures)
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		f, err := Parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		all.Customers = append(all.Customers, f.Customers...)
+		all.Titles = append(all.Titles, f.Titles...)
+	}
+	return all, nil
+}
+
+func (f *Fixtures) validate() error {
+	for i, c := range f.Customers {
+		if _, err := uuid.Parse(c.ID); err != nil {
+			return fmt.Errorf("customer %d: invalid id %q", i, c.ID)
+		}
+		if c.Name == "" {
+			return fmt.Errorf("customer %s: name is required", c.ID)
+		}
+	}
+	for i, t := range f.Titles {
+		if _, err := uuid.Parse(t.ID); err != nil {
+			return fmt.Errorf("title %d: invalid id %q", i, t.ID)
+		}
+		if t.Name == "" {
+			return fmt.Errorf("title %s: name is required", t.ID)
+		}
+		for j, c := range t.Copies {
+			if _, err := uuid.Parse(c.ID); err != nil {
+				return fmt.Errorf("title %s, copy %d: invalid id %q", t.ID, j, c.ID)
+			}
+			if c.Barcode == "" {
+				return fmt.Errorf("copy %s: barcode is required", c.ID)
+			}
+			if !c.Condition.IsValid() {
+				return fmt.Errorf("copy %s: invalid condition %q", c.ID, c.Condition)
+			}
+		}
+	}
+	return nil
+}
+
+// Customers creates the missing customers, restores the deleted ones and updates the name and address of the others
+func Customers(repo customer.Repository, fixtures []Customer) (Result, error) {
+	var res Result
+	for _, f := range fixtures {
+		c, err := repo.GetByID(f.ID)
+		switch {
+		case apperr.CodeOf(err) == apperr.NotFound:
+			c = &customer.Customer{
+				Base:    model.Base{ID: f.ID},
+				Name:    f.Name,
+				Address: f.Address,
+			}
+			//* A deleted customer keeps its id, it is brought back rather than stored again
+			err := repo.Restore(c)
+			switch {
+			case err == nil:
+				res.Updated++
+				continue
+			case apperr.CodeOf(err) != apperr.NotFound:
+				return res, fmt.Errorf("customer %s: %v", f.ID, err)
+			}
+			if err := repo.Store(c); err != nil {
+				return res, fmt.Errorf("customer %s: %v", f.ID, err)
+			}
+			res.Created++
+		case err != nil:
+			return res, fmt.Errorf("customer %s: %v", f.ID, err)
+		case c.Name != f.Name || c.Address != f.Address:
+			c.Name = f.Name
+			c.Address = f.Address
+			if err := repo.Update(c); err != nil {
+				return res, fmt.Errorf("customer %s: %v", f.ID, err)
+			}
+			res.Updated++
+		default:
+			res.Unchanged++
+		}
+	}
+	return res, nil
+}
+
+// Catalog creates the missing titles and copies and updates the details of the existing titles.
+// Existing copies are left as they are, a copy out on rent must stay so.
+func Catalog(repo dvd.Repository, fixtures []Title) (Result, error) {
+	var res Result
+	for _, f := range fixtures {
+		t, err := repo.GetTitle(f.ID)
+		switch {
+		case apperr.CodeOf(err) == apperr.NotFound:
+			t = &dvd.Title{Base: model.Base{ID: f.ID}}
+			f.apply(t)
+			if err := repo.StoreTitle(t); err != nil {
+				return res, fmt.Errorf("title %s: %v", f.ID, err)
+			}
+			res.Created++
+		case err != nil:
+			return res, fmt.Errorf("title %s: %v", f.ID, err)
+		case f.apply(t):
+			if err := repo.UpdateTitle(t); err != nil {
+				return res, fmt.Errorf("title %s: %v", f.ID, err)
+			}
+			res.Updated++
+		default:
+			res.Unchanged++
+		}
+
+		for _, fc := range f.Copies {
+			_, err := repo.Get(fc.ID)
+			switch {
+			case apperr.CodeOf(err) == apperr.NotFound:
+				c := &dvd.Copy{
+					Base:      model.Base{ID: fc.ID},
+					TitleID:   f.ID,
+					Barcode:   fc.Barcode,
+					Condition: fc.Condition,
+					Status:    dvd.Available,
+				}
+				if err := repo.Store(c); err != nil {
+					return res, fmt.Errorf("copy %s: %v", fc.ID, err)
+				}
+				res.Created++
+			case err != nil:
+				return res, fmt.Errorf("copy %s: %v", fc.ID, err)
+			default:
+				res.Unchanged++
+			}
+		}
+	}
+	return res, nil
+}
+
+// apply sets the details of the fixture on t and reports whether any changed
+func (f Title) apply(t *dvd.Title) bool {
+	changed := t.Name != f.Name || t.Year != f.Year || t.Runtime != f.Runtime || t.Rating != f.Rating ||
+		t.ReplacementCost != f.ReplacementCost || !equalStrings(t.Genres, f.Genres)
+	t.Name = f.Name
+	t.Year = f.Year
+	t.Runtime = f.Runtime
+	t.Rating = f.Rating
+	t.Genres = f.Genres
+	t.ReplacementCost = f.ReplacementCost
+	return changed
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
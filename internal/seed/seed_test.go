@@ -0,0 +1,135 @@
+package seed_test
+
+import (
+	"testing"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/ngray1747/dvd-rental/customer"
+	customerMocks "github.com/ngray1747/dvd-rental/customer/mocks"
+	"github.com/ngray1747/dvd-rental/dvd"
+	dvdMocks "github.com/ngray1747/dvd-rental/dvd/mocks"
+	"github.com/ngray1747/dvd-rental/internal/model"
+	"github.com/ngray1747/dvd-rental/internal/seed"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{
+			name: "yaml",
+			data: `customers:
+- id: c1e347c1-68cf-4d49-bae8-d16c7a818675
+  name: Ada Lovelace
+titles:
+- id: 8b04e3a1-cfd2-4249-b738-fd3e4600c458
+  name: Metropolis
+  copies:
+  - id: 528fe54d-f1c6-455a-8ce3-fbb4bf0febc5
+    barcode: DEMO-0001
+    condition: good
+`,
+		},
+		{
+			name: "json",
+			data: `{"titles": [{"id": "8b04e3a1-cfd2-4249-b738-fd3e4600c458", "name": "Metropolis", "replacement_cost": 1999}]}`,
+		},
+		{
+			name:    "unknown field",
+			data:    `{"titles": [{"id": "8b04e3a1-cfd2-4249-b738-fd3e4600c458", "name": "Metropolis", "price": 1999}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid id",
+			data:    `{"customers": [{"id": "1", "name": "Ada Lovelace"}]}`,
+			wantErr: true,
+		},
+		{
+			name: "invalid condition",
+			data: `{"titles": [{"id": "8b04e3a1-cfd2-4249-b738-fd3e4600c458", "name": "Metropolis",
+				"copies": [{"id": "528fe54d-f1c6-455a-8ce3-fbb4bf0febc5", "barcode": "DEMO-0001", "condition": "mint"}]}]}`,
+			wantErr: true,
+		},
+	}
+	for _, v := range cases {
+		t.Run(v.name, func(t *testing.T) {
+			_, err := seed.Parse([]byte(v.data))
+			assert.Equal(t, v.wantErr, err != nil, err)
+		})
+	}
+
+	demo, err := seed.Parse(seed.Demo)
+	assert.NoError(t, err, "the demo catalog must be valid")
+	assert.NotEmpty(t, demo.Customers)
+	assert.NotEmpty(t, demo.Titles)
+}
+
+func TestCustomers(t *testing.T) {
+	assert := assert.New(t)
+	repo := new(customerMocks.Repository)
+	fixtures := []seed.Customer{
+		{ID: "c1e347c1-68cf-4d49-bae8-d16c7a818675", Name: "Ada Lovelace", Address: "London"},
+		{ID: "1937e0b3-f5cd-4abd-bb9c-16cf49c30fa9", Name: "Alan Turing", Address: "Wilmslow"},
+		{ID: "41034f06-55c2-4e92-a96b-c5266dce7bfc", Name: "Grace Hopper", Address: "Arlington"},
+		{ID: "0d9d1f2a-7c1e-4f3b-9a55-6a2b8e4c1d07", Name: "Edsger Dijkstra", Address: "Nuenen"},
+	}
+	repo.On("GetByID", fixtures[0].ID).Return(nil, pg.ErrNoRows).Once()
+	repo.On("Restore", mock.MatchedBy(func(c *customer.Customer) bool { return c.ID == fixtures[0].ID })).Return(pg.ErrNoRows).Once()
+	repo.On("Store", mock.MatchedBy(func(c *customer.Customer) bool { return c.ID == fixtures[0].ID })).Return(nil).Once()
+	repo.On("GetByID", fixtures[1].ID).Return(&customer.Customer{Base: model.Base{ID: fixtures[1].ID}, Name: "Alan Turing", Address: "Manchester"}, nil).Once()
+	repo.On("Update", mock.MatchedBy(func(c *customer.Customer) bool { return c.Address == "Wilmslow" })).Return(nil).Once()
+	repo.On("GetByID", fixtures[2].ID).Return(&customer.Customer{Base: model.Base{ID: fixtures[2].ID}, Name: "Grace Hopper", Address: "Arlington"}, nil).Once()
+	//* A deleted customer is not found, it is restored as storing it again would conflict on its id
+	repo.On("GetByID", fixtures[3].ID).Return(nil, pg.ErrNoRows).Once()
+	repo.On("Restore", mock.MatchedBy(func(c *customer.Customer) bool { return c.ID == fixtures[3].ID && c.Address == "Nuenen" })).Return(nil).Once()
+
+	res, err := seed.Customers(repo, fixtures)
+	assert.NoError(err)
+	assert.Equal(seed.Result{Created: 1, Updated: 2, Unchanged: 1}, res)
+	repo.AssertExpectations(t)
+}
+
+func TestCatalog(t *testing.T) {
+	assert := assert.New(t)
+	repo := new(dvdMocks.Repository)
+	fixtures := []seed.Title{
+		{
+			ID:   "8b04e3a1-cfd2-4249-b738-fd3e4600c458",
+			Name: "Metropolis",
+			Copies: []seed.Copy{
+				{ID: "528fe54d-f1c6-455a-8ce3-fbb4bf0febc5", Barcode: "DEMO-0001", Condition: dvd.Good},
+				{ID: "89ec1b3c-3b58-40f3-888d-c19322d8ce66", Barcode: "DEMO-0002", Condition: dvd.Fair},
+			},
+		},
+		{
+			ID:              "5a3f0e52-2b8c-4d49-93c4-0f7f3c6d2e11",
+			Name:            "Nosferatu",
+			Year:            1922,
+			Genres:          []string{"horror"},
+			ReplacementCost: 1499,
+		},
+		{
+			ID:     "e6b1c0d4-91a7-4c2f-8f0e-3d5a7b9c1e22",
+			Name:   "Sunrise",
+			Genres: []string{"drama", "romance"},
+		},
+	}
+	repo.On("GetTitle", fixtures[0].ID).Return(&dvd.Title{Base: model.Base{ID: fixtures[0].ID}, Name: "Metropolis"}, nil).Once()
+	repo.On("GetTitle", fixtures[1].ID).Return(&dvd.Title{Base: model.Base{ID: fixtures[1].ID}, Name: "Nosferatu", Year: 1922, Genres: []string{"horror"}, ReplacementCost: 999}, nil).Once()
+	repo.On("UpdateTitle", mock.MatchedBy(func(t *dvd.Title) bool { return t.ID == fixtures[1].ID && t.ReplacementCost == 1499 })).Return(nil).Once()
+	repo.On("GetTitle", fixtures[2].ID).Return(&dvd.Title{Base: model.Base{ID: fixtures[2].ID}, Name: "Sunrise", Genres: []string{"drama", "romance"}}, nil).Once()
+	repo.On("Get", "528fe54d-f1c6-455a-8ce3-fbb4bf0febc5").Return(&dvd.Copy{Status: dvd.NotAvailable}, nil).Once()
+	repo.On("Get", "89ec1b3c-3b58-40f3-888d-c19322d8ce66").Return(nil, pg.ErrNoRows).Once()
+	repo.On("Store", mock.MatchedBy(func(c *dvd.Copy) bool {
+		return c.TitleID == fixtures[0].ID && c.Barcode == "DEMO-0002" && c.Status == dvd.Available
+	})).Return(nil).Once()
+
+	res, err := seed.Catalog(repo, fixtures)
+	assert.NoError(err)
+	assert.Equal(seed.Result{Created: 1, Updated: 1, Unchanged: 3}, res)
+	repo.AssertExpectations(t)
+}
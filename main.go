@@ -4,12 +4,8 @@ import (
 	"errors"
 	"flag"
 	"fmt"
-	"net"
 	"net/http"
 	"os"
-	"os/signal"
-	"strings"
-	"syscall"
 	"time"
 	"context"
 
@@ -18,16 +14,22 @@ import (
 	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
 	kitgrpc "github.com/go-kit/kit/transport/grpc"
 	"github.com/go-pg/pg/v9"
-	"github.com/go-pg/pg/v9/orm"
 	"github.com/go-redis/redis/v7"
 	"github.com/ngray1747/dvd-rental/customer"
 	customerCache "github.com/ngray1747/dvd-rental/customer/cache"
+	customerPB "github.com/ngray1747/dvd-rental/customer/pb"
 	customerRepo "github.com/ngray1747/dvd-rental/customer/repository"
 	"github.com/ngray1747/dvd-rental/dvd"
 	dvdCache "github.com/ngray1747/dvd-rental/dvd/cache"
 	dvdPB "github.com/ngray1747/dvd-rental/dvd/pb"
 	dvdRepo "github.com/ngray1747/dvd-rental/dvd/repository"
 	"github.com/ngray1747/dvd-rental/internal/config"
+	"github.com/ngray1747/dvd-rental/internal/health"
+	"github.com/ngray1747/dvd-rental/internal/idempotency"
+	"github.com/ngray1747/dvd-rental/internal/lifecycle"
+	"github.com/ngray1747/dvd-rental/internal/limits"
+	"github.com/ngray1747/dvd-rental/internal/migrate"
+	"github.com/ngray1747/dvd-rental/internal/outbox"
 	stdopentracing "github.com/opentracing/opentracing-go"
 	zipkinot "github.com/openzipkin-contrib/zipkin-go-opentracing"
 	zipkin "github.com/openzipkin/zipkin-go"
@@ -35,58 +37,53 @@ import (
 	stdprometheus "github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
-var (
-	errServiceNotConfigurated = errors.New("service is not configured")
-	errServiceNotFound        = errors.New("service not found")
-)
+var errServiceNotFound = errors.New("service not found")
 
-func getConf(name string, services []config.Service) (*config.Service, error) {
-	if len(services) == 0 {
-		return nil, errServiceNotConfigurated
-	}
-	for _, service := range services {
-		if service.Name == name {
-			return &service, nil
+func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			os.Exit(runMigrate(os.Args[2:]))
+		case "seed":
+			os.Exit(runSeed(os.Args[2:]))
+		case "config":
+			os.Exit(runConfig(os.Args[2:]))
 		}
 	}
-	return nil, errServiceNotFound
-}
-
-func main() {
-	fs := flag.NewFlagSet("dvd_rental", flag.ExitOnError)
-	var (
-		httpAddr      = fs.String("httpAddr", ":9999", "Http server address")
-		grpcAddr      = fs.String("grpcAddr", ":8888", "GRPC server address")
-		zipkinAddr    = fs.String("zipkinAddr", "", "Zipkin tracer address")
-		dbUserName    = fs.String("dbUserName", "my-user", "Postgresql username")
-		dbPassword    = fs.String("dbPassword", "password123", "Postgresql password")
-		dbAddr        = fs.String("dbHost", "", "Postgresql host")
-		redisAddr     = fs.String("redisAddr", "", "Redis cache address")
-		redisPassword = fs.String("redisPassword", "", "Redis cache password")
-		svc           = fs.String("service", "", "Service name")
-		namespace     = fs.String("namespace", "", "Service namespace")
-	)
-	fs.Parse(os.Args[1:])
 
 	var logger log.Logger
 	logger = log.NewLogfmtLogger(log.NewSyncWriter(os.Stderr))
 	logger = log.With(logger, "ts", log.DefaultTimestamp)
 
-	if *zipkinAddr == "" {
-		panic("Zipkin configuration required")
+	fs := flag.NewFlagSet("dvd_rental", flag.ExitOnError)
+	config.RegisterFlags(fs)
+	cfg, err := config.Parse(fs, os.Args[1:])
+	if err != nil {
+		logger.Log("err", err)
+		os.Exit(1)
+	}
+	if err := cfg.Validate(); err != nil {
+		for _, e := range err.(config.Errors) {
+			logger.Log("env", cfg.Env, "err", e)
+		}
+		os.Exit(1)
 	}
+	services, _ := cfg.Server.Selected()
+	app := lifecycle.New(time.Duration(cfg.Server.ShutdownTimeout)*time.Second, logger)
+
 	var zipkinTracer *zipkin.Tracer
 	{
 		var (
 			err         error
 			hostPort    = "localhost:80"
 			serviceName = "customer"
-			zipkinURL   = fmt.Sprintf("http://%s/api/v2/spans", *zipkinAddr)
+			zipkinURL   = fmt.Sprintf("http://%s/api/v2/spans", cfg.Tracing.ZipkinAddr)
 			reporter    = zipkinhttp.NewReporter(zipkinURL)
 		)
-		defer reporter.Close()
+		app.OnClose("zipkin", reporter.Close)
 		zEP, _ := zipkin.NewEndpoint(serviceName, hostPort)
 		zipkinTracer, err = zipkin.NewTracer(reporter, zipkin.WithLocalEndpoint(zEP))
 		if err != nil {
@@ -102,140 +99,174 @@ func main() {
 		zipkinTracer = nil
 	}
 
-	//Get app config
-	cfg, err := config.Load("dev")
-	if err != nil {
-		panic(err)
+	if cfg.Server.MetricsAddr == "" {
+		http.Handle("/metrics", promhttp.Handler())
+	} else {
+		metricsMux := http.NewServeMux()
+		metricsMux.Handle("/metrics", promhttp.Handler())
+		app.Serve("metrics", cfg.Server.MetricsAddr, lifecycle.HTTPServer(cfg.Server.MetricsAddr, metricsMux))
 	}
+	checker := health.NewChecker(health.DefaultInterval)
+	http.Handle("/healthz", health.LiveHandler())
+	http.Handle("/readyz", checker.ReadyHandler())
+	app.OnDrain(checker.Drain)
+	mux := http.NewServeMux()
+	http.Handle("/", accessControl(mux))
+	app.Serve("http", cfg.Server.HTTPAddr, lifecycle.HTTPServer(cfg.Server.HTTPAddr, http.DefaultServeMux))
 
-	//Get Customer configuration
-	if *redisAddr == "" {
-		panic("Redis configuration required")
-	}
-	cacheCli := redis.NewClient(&redis.Options{
-		Addr:     *redisAddr,
-		Password: *redisPassword,
-		DB:       0,
-	})
-	defer cacheCli.Close()
-
-	if *dbAddr == "" || *dbUserName == "" || *dbPassword == "" {
-		panic("Database configuration required")
+	//* Limits follow the environment file, reloaded when it changes
+	var reloads []func(*config.Configuration)
+	newGuard := func(service, key string, limitsOf func(*config.Service) *config.Limits) *limits.Guard {
+		svcCfg, _ := cfg.Service(service)
+		guard := limits.NewGuard(*limitsOf(svcCfg), log.With(logger, "service", service, "limits", key))
+		reloads = append(reloads, func(next *config.Configuration) {
+			svcCfg, _ := next.Service(service)
+			guard.Update(*limitsOf(svcCfg))
+		})
+		return guard
 	}
+	endpointLimits := func(s *config.Service) *config.Limits { return s.Limits }
 
-	var historgram metrics.Histogram
-	{
-		historgram = kitprometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
-			Namespace: *namespace,
-			Subsystem: *svc,
-			Name:      "request_latency_microseconds",
-			Help:      "Request duration",
-		}, []string{"method", "success"})
-	}
-	var counter metrics.Counter
-	{
-		counter = kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
-			Namespace: *namespace,
-			Subsystem: *svc,
-			Name:      "request_count",
-			Help:      "Number of requests received",
-		}, []string{"method"})
-	}
+	//* The dvd service starts first so a customer service running in the same process calls it directly
+	var dvdSrv dvd.Service
+	if services["dvd"] {
+		svcCfg, _ := cfg.Service("dvd")
+		cacheCli := redis.NewClient(svcCfg.Cache.Options())
+		app.OnClose("dvd redis", cacheCli.Close)
+		cacheRepo := dvdCache.NewCacheClient(cacheCli)
 
-	http.Handle("/metrics", promhttp.Handler())
-	var grpcServer *grpc.Server
-	switch *svc {
-	case "customer":
-		svcCfg, err := getConf("customer", cfg.Services)
+		db, err := openDB(logger, svcCfg.Database, dvdRepo.Migrations)
 		if err != nil {
-			logger.Log("get svc config error: ", err)
+			logger.Log("init Db error: ", err)
 			os.Exit(1)
 		}
+		app.OnClose("dvd database", db.Close)
+		checker.Add("pb.DVDRental", "dvd postgres", health.Postgres(db))
+		checker.Add("pb.DVDRental", "dvd redis", health.Redis(cacheCli))
+
+		repo := dvdRepo.NewDVDRepository(svcCfg.Cache, db, cacheRepo)
+		relay := outbox.NewRelay(db, newEventPublisher(svcCfg.Outbox, cacheCli, "dvd"), svcCfg.Outbox, logger)
+		app.Go("dvd outbox relay", relay.Run)
+		counter, historgram := newServiceMetrics(cfg.Server.Namespace, "dvd")
+		waitlist := dvd.NewWaitlistPolicy(svcCfg.Reservation)
+		dvdSrv = dvd.NewService(repo, waitlist, logger, counter, historgram)
+		app.Go("dvd hold expiry", func(ctx context.Context) {
+			dvd.RunHoldExpiry(ctx, dvdSrv, waitlist.SweepInterval, logger)
+		})
+		dvdEndpoint := dvd.NewDVDEndpoint(dvdSrv, tracer, idempotency.NewKeeper(idempotency.NewRedisStore(cacheCli), svcCfg.Cache.IdempotencyTTL, logger), newGuard("dvd", "limits", endpointLimits))
+		dvdGRPCServer := dvd.NewGRPCServer(dvdEndpoint, tracer, logger)
+
+		grpcServer := grpc.NewServer(grpc.UnaryInterceptor(kitgrpc.Interceptor))
+		dvdPB.RegisterDVDRentalServer(grpcServer, dvdGRPCServer)
+		healthpb.RegisterHealthServer(grpcServer, checker.GRPC())
+		app.Serve("GRPC dvd", cfg.Server.GRPCAddr, lifecycle.GRPCServer(cfg.Server.GRPCAddr, grpcServer))
+
+		inventory := dvd.MakeInventoryHandler(dvdEndpoint, logger, tracer)
+		mux.Handle("/dvd/v1/dvds/import", inventory)
+		mux.Handle("/dvd/v1/dvds/export", inventory)
+		mux.Handle("/dvd/v1/", dvd.MakeHandler(dvdEndpoint, logger, tracer))
+	}
+
+	if services["customer"] {
+		svcCfg, _ := cfg.Service("customer")
+		cacheCli := redis.NewClient(svcCfg.Cache.Options())
+		app.OnClose("customer redis", cacheCli.Close)
 		cacheRepo := customerCache.NewCacheClient(cacheCli)
+		rentalCacheRepo := customerCache.NewRentalCacheClient(cacheCli)
 
-		db, err := initDB(*dbAddr, *dbUserName, *dbPassword, svcCfg.Database.DBName, []interface{}{&customer.Customer{}})
+		db, err := openDB(logger, svcCfg.Database, customerRepo.Migrations)
 		if err != nil {
 			logger.Log("init Db error: ", err)
 			os.Exit(1)
 		}
-		defer db.Close()
+		app.OnClose("customer database", db.Close)
+		checker.Add("pb.CustomerService", "customer postgres", health.Postgres(db))
+		checker.Add("pb.CustomerService", "customer redis", health.Redis(cacheCli))
 		repo := customerRepo.NewCustomerRepository(svcCfg.Cache, db, cacheRepo)
-		conn, err := grpc.Dial(*grpcAddr, grpc.WithInsecure(), grpc.WithTimeout(5*time.Second))
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
-		}
-		defer conn.Close()
+		rentalRepo := customerRepo.NewRentalRepository(svcCfg.Cache, db, rentalCacheRepo, cacheRepo)
+		purchaseRepo := customerRepo.NewPurchaseRepository(db)
+		sagaRepo := customerRepo.NewSagaRepository(db)
+		returnSagaRepo := customerRepo.NewReturnSagaRepository(db)
+		relay := outbox.NewRelay(db, newEventPublisher(svcCfg.Outbox, cacheCli, "customer"), svcCfg.Outbox, logger)
+		app.Go("customer outbox relay", relay.Run)
 		var dvdSvc customer.ProxyService
-		dvdSvc = customer.NewProxyMiddleware(conn, context.Background(), tracer, logger)(dvdSvc)
-		
+		if dvdSrv != nil {
+			dvdSvc = customer.NewLocalProxy(dvdSrv)
+		} else {
+			conn, err := grpc.Dial(cfg.Server.GRPCAddr, grpc.WithInsecure(), grpc.WithTimeout(5*time.Second))
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			app.OnClose("dvd connection", conn.Close)
+			checker.Add("pb.CustomerService", "dvd grpc", health.GRPCConn(conn))
+			guard := newGuard("customer", "dvdClient", func(s *config.Service) *config.Limits { return s.DVDClient })
+			dvdSvc = customer.NewProxyMiddleware(conn, context.Background(), tracer, logger, guard)(dvdSvc)
+		}
+
 		var cs customer.Service
-		cs = customer.NewService(repo, logger, counter, historgram, dvdSvc)
-		customerEndpoint := customer.NewCustomerEndpoint(cs, tracer)
+		counter, historgram := newServiceMetrics(cfg.Server.Namespace, "customer")
+		sagas := customer.NewSagaCoordinator(sagaRepo, returnSagaRepo, rentalRepo, dvdSvc, svcCfg.Rental, logger)
+		app.Go("saga recovery", sagas.RunRecovery)
+		cs = customer.NewService(repo, rentalRepo, purchaseRepo, sagas, customer.NewFeePolicy(svcCfg.Rental), logger, counter, historgram, dvdSvc)
+		customerEndpoint := customer.NewCustomerEndpoint(cs, tracer, idempotency.NewKeeper(idempotency.NewRedisStore(cacheCli), svcCfg.Cache.IdempotencyTTL, logger), newGuard("customer", "limits", endpointLimits))
+
+		//* The web app keeps the JSON API while internal callers use gRPC, both served from the same endpoints
+		grpcServer := grpc.NewServer(grpc.UnaryInterceptor(kitgrpc.Interceptor))
+		customerPB.RegisterCustomerServiceServer(grpcServer, customer.NewGRPCServer(customerEndpoint, tracer, logger))
+		healthpb.RegisterHealthServer(grpcServer, checker.GRPC())
+		app.Serve("GRPC customer", cfg.Server.CustomerGRPCAddr, lifecycle.GRPCServer(cfg.Server.CustomerGRPCAddr, grpcServer))
 
-		mux := http.NewServeMux()
-		http.Handle("/", accessControl(mux))
 		mux.Handle("/customer/v1/", customer.MakeHandler(customerEndpoint, logger, tracer))
-		break
-	case "dvd":
-		svcCfg, err := getConf("dvd", cfg.Services)
-		if err != nil {
-			logger.Log("get svc config error: ", err)
-			os.Exit(1)
-		}
-		cacheRepo := dvdCache.NewCacheClient(cacheCli)
+	}
 
-		db, err := initDB(*dbAddr, *dbUserName, *dbPassword, svcCfg.Database.DBName, []interface{}{&dvd.DVD{}})
+	app.Go("health", checker.Run)
+	cfg.Watch(func(next *config.Configuration, err error) {
 		if err != nil {
-			logger.Log("init Db error: ", err)
-			os.Exit(1)
+			logger.Log("env", cfg.Env, "msg", "configuration change not applied", "err", err)
+			return
 		}
-		defer db.Close()
-
-		repo := dvdRepo.NewDVDRepository(svcCfg.Cache, db, cacheRepo)
-		var dvdSrv dvd.Service
-		dvdSrv = dvd.NewService(repo, logger, counter, historgram)
-		dvdEndpoint := dvd.NewDVDEndpoint(dvdSrv, tracer)
-		dvdGRPCServer := dvd.NewGRPCServer(dvdEndpoint, tracer, logger)
-
-		grpcServer = grpc.NewServer(grpc.UnaryInterceptor(kitgrpc.Interceptor))
-		dvdPB.RegisterDVDRentalServer(grpcServer, dvdGRPCServer)
-		// grpcServer.Serve(listener)
-		break
-	default:
-		break
+		for _, reload := range reloads {
+			reload(next)
+		}
+	})
+	if err := app.Run(context.Background()); err != nil {
+		os.Exit(1)
 	}
+}
 
-	errs := make(chan error, 2)
-
-	go func() {
-		if strings.ToUpper(*namespace) == "API" {
-			logger.Log("transport", "http", "address", *httpAddr, "msg", "listening")
-			errs <- http.ListenAndServe(*httpAddr, nil)
-		} else if strings.ToUpper(*namespace) == "SVC" {
-			listener, err := net.Listen("tcp", *grpcAddr)
-			if err != nil {
-				logger.Log("net config error: ", err)
-				os.Exit(1)
-			}
-			logger.Log("transport", "GRPC", "address", *grpcAddr, "msg", "listening")
-			errs <- grpcServer.Serve(listener)
-		}
-	}()
-	go func() {
-		c := make(chan os.Signal)
-		signal.Notify(c, syscall.SIGINT)
-		errs <- fmt.Errorf("%s", <-c)
-	}()
+// newServiceMetrics makes the request metrics of a service, each service of the process in its own subsystem
+func newServiceMetrics(namespace, name string) (metrics.Counter, metrics.Histogram) {
+	counter := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: name,
+		Name:      "request_count",
+		Help:      "Number of requests received",
+	}, []string{"method"})
+	histogram := kitprometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
+		Namespace: namespace,
+		Subsystem: name,
+		Name:      "request_latency_microseconds",
+		Help:      "Request duration",
+	}, []string{"method", "success"})
+	return counter, histogram
+}
 
-	logger.Log("shutting down", <-errs)
+// newEventPublisher picks where a service relays its outbox events, a Redis stream named after the service by default
+//* Nothing subscribes in process, events are always relayed to a Redis stream for the consumers to read
+func newEventPublisher(cfg *config.Outbox, cacheCli *redis.Client, name string) outbox.EventPublisher {
+	stream := name + "-events"
+	if cfg != nil && cfg.Stream != "" {
+		stream = cfg.Stream
+	}
+	return outbox.NewRedisStreamPublisher(cacheCli, stream)
 }
 
 func accessControl(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Idempotency-Key")
 
 		if r.Method == "OPTIONS" {
 			return
@@ -245,39 +276,34 @@ func accessControl(h http.Handler) http.Handler {
 	})
 }
 
-func initDB(addr, username, password, database string, models []interface{}) (*pg.DB, error) {
-
-	db := pg.Connect(&pg.Options{
-		Addr:     addr,
-		User:     username,
-		Password: password,
-		Database: database,
-	})
-
-	query := fmt.Sprintf(`SELECT 1 FROM pg_database WHERE datname = '%s';`, database)
-	result, err := db.Exec(query)
-	if err != nil || result == nil {
+// openDB connects to a service database, creating it when missing, and applies its pending migrations
+func openDB(logger log.Logger, cfg *config.Database, migrations []migrate.Migration) (*pg.DB, error) {
+	opts, err := cfg.Options()
+	if err != nil {
 		return nil, err
 	}
-
-	if result.RowsAffected() == 0 {
-		fmt.Printf("Database \"%s\" is creating...\n", database)
-		query := fmt.Sprintf(`CREATE DATABASE %s;`, database)
-		_, err := db.Exec(query)
-		if err != nil {
-			return nil, err
-		}
-		for _, model := range models {
-			fmt.Printf("Creating model: %+v ... \n", model)
-			if err := db.CreateTable(model, &orm.CreateTableOptions{
-				FKConstraints: true,
-				IfNotExists:   true,
-			}); err != nil {
-				return nil, err
-			}
-		}
+	database := opts.Database
+	created, err := migrate.CreateDatabase(opts)
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		logger.Log("database", database, "msg", "created")
 	}
-	fmt.Println("Database already existed. Abort migration")
 
+	db := pg.Connect(opts)
+	migrator, err := migrate.New(db, migrations)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	applied, err := migrator.Up()
+	for _, m := range applied {
+		logger.Log("database", database, "migration", m.Version, "name", m.Name, "msg", "applied")
+	}
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
 	return db, nil
 }
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-pg/pg/v9"
+	customerRepo "github.com/ngray1747/dvd-rental/customer/repository"
+	dvdRepo "github.com/ngray1747/dvd-rental/dvd/repository"
+	"github.com/ngray1747/dvd-rental/internal/config"
+	"github.com/ngray1747/dvd-rental/internal/migrate"
+)
+
+// serviceMigrations are the migrations of each service database
+var serviceMigrations = map[string][]migrate.Migration{
+	"customer": customerRepo.Migrations,
+	"dvd":      dvdRepo.Migrations,
+}
+
+const migrateUsage = `usage: dvd_rental migrate up|down|status [flags]
+
+  up      apply the pending migrations
+  down    roll back the latest migrations, one unless -steps is set
+  status  list the migrations and when they were applied
+`
+
+// runMigrate runs the migrate subcommand and returns its exit code
+func runMigrate(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return 2
+	}
+	action := args[0]
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		fs.PrintDefaults()
+	}
+	var (
+		svc   = fs.String("service", "", "Service name")
+		steps = fs.Int("steps", 1, "How many migrations down rolls back")
+	)
+	config.RegisterFlags(fs, "dbHost", "dbUserName", "dbPassword")
+	cfg, err := config.Parse(fs, args[1:])
+
+	logger := log.NewLogfmtLogger(log.NewSyncWriter(os.Stderr))
+	logger = log.With(logger, "ts", log.DefaultTimestamp, "service", *svc)
+	if err != nil {
+		logger.Log("err", err)
+		return 1
+	}
+
+	migrations, ok := serviceMigrations[*svc]
+	if !ok {
+		logger.Log("err", errServiceNotFound)
+		return 1
+	}
+	if err := cfg.ValidateStore(*svc, false); err != nil {
+		logger.Log("env", cfg.Env, "err", err)
+		return 1
+	}
+	svcCfg, _ := cfg.Service(*svc)
+	opts, _ := svcCfg.Database.Options()
+	if action == "up" {
+		if _, err := migrate.CreateDatabase(opts); err != nil {
+			logger.Log("err", err)
+			return 1
+		}
+	}
+	db := pg.Connect(opts)
+	defer db.Close()
+	migrator, err := migrate.New(db, migrations)
+	if err != nil {
+		logger.Log("err", err)
+		return 1
+	}
+
+	switch action {
+	case "up":
+		applied, err := migrator.Up()
+		for _, m := range applied {
+			logger.Log("migration", m.Version, "name", m.Name, "msg", "applied")
+		}
+		if err != nil {
+			logger.Log("err", err)
+			return 1
+		}
+		if len(applied) == 0 {
+			logger.Log("msg", "database is up to date")
+		}
+	case "down":
+		for i := 0; i < *steps; i++ {
+			m, err := migrator.Down()
+			if err != nil {
+				logger.Log("err", err)
+				return 1
+			}
+			logger.Log("migration", m.Version, "name", m.Name, "msg", "rolled back")
+		}
+	case "status":
+		status, err := migrator.Status()
+		if err != nil {
+			logger.Log("err", err)
+			return 1
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range status {
+			appliedAt := "pending"
+			if !s.AppliedAt.IsZero() {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Fprintln(w, strconv.Itoa(s.Version)+"\t"+s.Name+"\t"+appliedAt)
+		}
+		w.Flush()
+	default:
+		fs.Usage()
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-redis/redis/v7"
+	customerCache "github.com/ngray1747/dvd-rental/customer/cache"
+	customerRepo "github.com/ngray1747/dvd-rental/customer/repository"
+	dvdCache "github.com/ngray1747/dvd-rental/dvd/cache"
+	dvdRepo "github.com/ngray1747/dvd-rental/dvd/repository"
+	"github.com/ngray1747/dvd-rental/internal/config"
+	"github.com/ngray1747/dvd-rental/internal/seed"
+)
+
+const seedUsage = `usage: dvd_rental seed [flags] [fixture files]
+
+Loads customers into the customer service and titles and copies into the dvd service,
+from YAML or JSON fixture files and/or the bundled demo catalog. Seeding again is safe.
+`
+
+// runSeed runs the seed subcommand and returns its exit code
+func runSeed(args []string) int {
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, seedUsage)
+		fs.PrintDefaults()
+	}
+	var (
+		svc  = fs.String("service", "", "Only seed this service, customer or dvd")
+		demo = fs.Bool("demo", false, "Seed the bundled demo catalog")
+	)
+	config.RegisterFlags(fs, "dbHost", "dbUserName", "dbPassword", "redisAddr", "redisPassword")
+	cfg, err := config.Parse(fs, args)
+
+	logger := log.NewLogfmtLogger(log.NewSyncWriter(os.Stderr))
+	logger = log.With(logger, "ts", log.DefaultTimestamp)
+	if err != nil {
+		logger.Log("err", err)
+		return 1
+	}
+
+	if !*demo && fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+	if *svc != "" && *svc != "customer" && *svc != "dvd" {
+		logger.Log("err", errServiceNotFound)
+		return 1
+	}
+	for _, name := range []string{"customer", "dvd"} {
+		if *svc != "" && *svc != name {
+			continue
+		}
+		if err := cfg.ValidateStore(name, true); err != nil {
+			logger.Log("env", cfg.Env, "service", name, "err", err)
+			return 1
+		}
+	}
+
+	fixtures, err := seed.Load(fs.Args()...)
+	if err != nil {
+		logger.Log("err", err)
+		return 1
+	}
+	if *demo {
+		d, err := seed.Parse(seed.Demo)
+		if err != nil {
+			logger.Log("err", err)
+			return 1
+		}
+		fixtures.Customers = append(fixtures.Customers, d.Customers...)
+		fixtures.Titles = append(fixtures.Titles, d.Titles...)
+	}
+
+	if *svc == "" || *svc == "customer" {
+		svcCfg, _ := cfg.Service("customer")
+		cacheCli := redis.NewClient(svcCfg.Cache.Options())
+		defer cacheCli.Close()
+		db, err := openDB(logger, svcCfg.Database, customerRepo.Migrations)
+		if err != nil {
+			logger.Log("service", "customer", "err", err)
+			return 1
+		}
+		defer db.Close()
+		repo := customerRepo.NewCustomerRepository(svcCfg.Cache, db, customerCache.NewCacheClient(cacheCli))
+		res, err := seed.Customers(repo, fixtures.Customers)
+		logger.Log("service", "customer", "created", res.Created, "updated", res.Updated, "unchanged", res.Unchanged)
+		if err != nil {
+			logger.Log("service", "customer", "err", err)
+			return 1
+		}
+	}
+
+	if *svc == "" || *svc == "dvd" {
+		svcCfg, _ := cfg.Service("dvd")
+		cacheCli := redis.NewClient(svcCfg.Cache.Options())
+		defer cacheCli.Close()
+		db, err := openDB(logger, svcCfg.Database, dvdRepo.Migrations)
+		if err != nil {
+			logger.Log("service", "dvd", "err", err)
+			return 1
+		}
+		defer db.Close()
+		repo := dvdRepo.NewDVDRepository(svcCfg.Cache, db, dvdCache.NewCacheClient(cacheCli))
+		res, err := seed.Catalog(repo, fixtures.Titles)
+		logger.Log("service", "dvd", "created", res.Created, "updated", res.Updated, "unchanged", res.Unchanged)
+		if err != nil {
+			logger.Log("service", "dvd", "err", err)
+			return 1
+		}
+	}
+	return 0
+}